@@ -0,0 +1,207 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"faliactl/pkg/clients"
+	"faliactl/pkg/scraper"
+
+	"github.com/spf13/cobra"
+)
+
+var cacheCmd = &cobra.Command{
+	Use:   "cache",
+	Short: "Inspect and manage the local schedule cache",
+	Long:  `Schedules are cached in ~/.faliactl_cache for 12 hours and revalidated with the intranet afterwards. Use these subcommands to inspect, refresh or wipe that cache.`,
+}
+
+var cacheLsCmd = &cobra.Command{
+	Use:   "ls",
+	Short: "List cached study groups",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		infos, err := scraper.ListCache()
+		if err != nil {
+			return err
+		}
+
+		if structured(cmd) {
+			if infos == nil {
+				infos = []scraper.CacheInfo{}
+			}
+			return printStructured(cmd, infos)
+		}
+
+		if len(infos) == 0 {
+			fmt.Println("The schedule cache is empty.")
+			return nil
+		}
+
+		tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "GROUP\tCOURSES\tAGE\tSTATE\tSIZE")
+		for _, info := range infos {
+			if info.Courses < 0 {
+				fmt.Fprintf(tw, "%s\t-\t-\tcorrupt\t%s\n", info.GroupURL, formatBytes(info.Size))
+				continue
+			}
+			state := "expired"
+			if info.Fresh {
+				state = "fresh"
+			}
+			fmt.Fprintf(tw, "%s\t%d\t%s\t%s\t%s\n",
+				info.GroupURL,
+				info.Courses,
+				formatAge(time.Since(info.Timestamp)),
+				state,
+				formatBytes(info.Size),
+			)
+		}
+		return tw.Flush()
+	},
+}
+
+var cacheClearCmd = &cobra.Command{
+	Use:   "clear [group...]",
+	Short: "Delete cached schedules (all of them if no group is given)",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var groupURLs []string
+		for _, group := range args {
+			groupURLs = append(groupURLs, scraper.GroupPath(group))
+		}
+
+		removed, err := scraper.ClearCache(groupURLs...)
+		if err != nil {
+			return err
+		}
+
+		if structured(cmd) {
+			return printStructured(cmd, map[string]int{"removed": removed})
+		}
+		fmt.Printf("Removed %d cached schedule(s).\n", removed)
+		return nil
+	},
+}
+
+var cacheRefreshCmd = &cobra.Command{
+	Use:   "refresh",
+	Short: "Re-download schedules and overwrite their cache entries",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		groups, _ := cmd.Flags().GetStringSlice("group")
+
+		// Without explicit groups, refresh everything that is already cached
+		if len(groups) == 0 {
+			infos, err := scraper.ListCache()
+			if err != nil {
+				return err
+			}
+			for _, info := range infos {
+				groups = append(groups, info.GroupURL)
+			}
+		}
+		if len(groups) == 0 {
+			return fmt.Errorf("nothing to refresh: pass --group or populate the cache first")
+		}
+
+		client := clients.Scraper()
+		var firstErr error
+		refreshed := []refreshOutput{}
+
+		for _, group := range groups {
+			urlPath := scraper.GroupPath(group)
+			var courses []scraper.Course
+			var err error
+
+			err = runSpinner(cmd.Context(), fmt.Sprintf("Refreshing %s...", urlPath), func(ctx context.Context) (err error) {
+				courses, err = client.RefreshScheduleContext(ctx, urlPath)
+				return err
+			})
+
+			if cmd.Context().Err() != nil {
+				return err
+			}
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Failed to refresh %s: %v\n", urlPath, err)
+				if firstErr == nil {
+					firstErr = err
+				}
+				continue
+			}
+			if structured(cmd) {
+				refreshed = append(refreshed, refreshOutput{Group: urlPath, Courses: len(courses)})
+				continue
+			}
+			fmt.Printf("Refreshed %s (%d courses)\n", urlPath, len(courses))
+		}
+
+		if structured(cmd) {
+			if err := printStructured(cmd, refreshed); err != nil {
+				return err
+			}
+		}
+		return firstErr
+	},
+}
+
+// refreshOutput is one refreshed group of `cache refresh --output json`
+type refreshOutput struct {
+	Group   string `json:"group"`
+	Courses int    `json:"courses"`
+}
+
+var cacheStatsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Show a summary of the schedule cache",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		stats, err := scraper.GetCacheStats()
+		if err != nil {
+			return err
+		}
+
+		if structured(cmd) {
+			return printStructured(cmd, stats)
+		}
+
+		fmt.Printf("Directory:  %s\n", stats.Dir)
+		fmt.Printf("Groups:     %d (%d fresh, %d expired, %d corrupt)\n", stats.Entries, stats.Fresh, stats.Expired, stats.Corrupt)
+		fmt.Printf("Courses:    %d\n", stats.Courses)
+		fmt.Printf("Disk usage: %s\n", formatBytes(stats.TotalBytes))
+		if !stats.Oldest.IsZero() {
+			fmt.Printf("Oldest:     %s (%s ago)\n", stats.Oldest.Local().Format("2006-01-02 15:04"), formatAge(time.Since(stats.Oldest)))
+			fmt.Printf("Newest:     %s (%s ago)\n", stats.Newest.Local().Format("2006-01-02 15:04"), formatAge(time.Since(stats.Newest)))
+		}
+		return nil
+	},
+}
+
+func formatAge(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return "<1m"
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	case d < 48*time.Hour:
+		return fmt.Sprintf("%dh", int(d.Hours()))
+	default:
+		return fmt.Sprintf("%dd", int(d.Hours()/24))
+	}
+}
+
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	if n < unit*unit {
+		return fmt.Sprintf("%.1f KiB", float64(n)/unit)
+	}
+	return fmt.Sprintf("%.1f MiB", float64(n)/(unit*unit))
+}
+
+func init() {
+	rootCmd.AddCommand(cacheCmd)
+	cacheCmd.AddCommand(cacheLsCmd, cacheClearCmd, cacheRefreshCmd, cacheStatsCmd)
+	cacheRefreshCmd.Flags().StringSliceP("group", "g", nil, "Group ID(s) to refresh (defaults to every cached group)")
+}
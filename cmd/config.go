@@ -1,8 +1,8 @@
 package cmd
 
 import (
+	"faliactl/pkg/clients"
 	"faliactl/pkg/config"
-	"faliactl/pkg/transit"
 	"faliactl/pkg/tui"
 	"fmt"
 
@@ -24,8 +24,8 @@ var configCmd = &cobra.Command{
 			fmt.Printf("Searching HAFAS for address: '%s'...\n", setHome)
 
 			// Use the transit API to lookup the location ID for this address
-			client := transit.NewClient()
-			locations, err := client.FetchLocations(setHome)
+			client := clients.Transit()
+			locations, err := client.FetchLocationsContext(cmd.Context(), setHome)
 			if err != nil {
 				return fmt.Errorf("could not lookup address: %w", err)
 			}
@@ -47,7 +47,7 @@ var configCmd = &cobra.Command{
 		}
 
 		// If no flags are given, launch the interactive TUI flow
-		return tui.RunConfigTUI()
+		return tui.RunConfigTUI(cmd.Context())
 	},
 }
 
@@ -0,0 +1,105 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"faliactl/pkg/clients"
+	"faliactl/pkg/conflict"
+	"faliactl/pkg/exporter"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/spf13/cobra"
+)
+
+var conflictsCmd = &cobra.Command{
+	Use:   "conflicts",
+	Short: "Find overlapping lectures, impossible campus changes and long gaps",
+	Long: `Combines the selected groups and courses and reports lectures that overlap, back-to-back
+lectures at different campuses where the break is shorter than the journey, and days with
+breaks longer than --max-gap.
+
+Travel times come from a built-in campus matrix; --live asks the HAFAS API instead.
+Without --group or --set your saved groups and courses are checked.`,
+	Example: `  faliactl conflicts
+  faliactl conflicts --group 161902 --group 161903 --max-gap 2h
+  faliactl conflicts --set my-classes --live --exit-code`,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		maxGap, _ := cmd.Flags().GetDuration("max-gap")
+		live, _ := cmd.Flags().GetBool("live")
+		format, _ := cmd.Flags().GetString("format")
+		exitCode, _ := cmd.Flags().GetBool("exit-code")
+
+		if format != "text" && format != "json" {
+			return fmt.Errorf("unknown format %q (use text or json)", format)
+		}
+
+		courses, err := loadSelection(cmd)
+		if err != nil {
+			return err
+		}
+
+		opts := conflict.Options{MaxGap: maxGap}
+		if maxGap == 0 {
+			opts.MaxGap = -1 // --max-gap 0 switches gap detection off
+		}
+		if live {
+			opts.Travel = conflict.TransitTravel(clients.Transit())
+		}
+		conflicts := conflict.AnalyzeContext(cmd.Context(), courses, opts)
+
+		if format == "json" || structured(cmd) {
+			// --format json predates the global --output, which wins when both are given
+			outFormat := outputJSON
+			if structured(cmd) {
+				outFormat = outputFormat(cmd)
+			}
+			if err := writeStructured(os.Stdout, outFormat, exporter.ConflictRecords(conflicts)); err != nil {
+				return err
+			}
+		} else {
+			printConflicts(conflicts, len(courses))
+		}
+
+		if exitCode && len(conflicts) > 0 {
+			return findings(cmd)
+		}
+		return nil
+	},
+}
+
+func printConflicts(conflicts []conflict.Conflict, courses int) {
+	if len(conflicts) == 0 {
+		fmt.Printf("No conflicts among %d courses.\n", courses)
+		return
+	}
+
+	styles := map[conflict.Kind]lipgloss.Style{
+		conflict.KindOverlap: lipgloss.NewStyle().Foreground(lipgloss.Color("196")),
+		conflict.KindTravel:  lipgloss.NewStyle().Foreground(lipgloss.Color("214")),
+		conflict.KindGap:     lipgloss.NewStyle().Foreground(lipgloss.Color("39")),
+	}
+	symbols := map[conflict.Kind]string{
+		conflict.KindOverlap: "✘",
+		conflict.KindTravel:  "🚌",
+		conflict.KindGap:     "⏳",
+	}
+
+	fmt.Printf("%d conflict(s) among %d courses:\n\n", len(conflicts), courses)
+	for _, c := range conflicts {
+		fmt.Println(styles[c.Kind].Render(fmt.Sprintf("%s %s", symbols[c.Kind], c)))
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(conflictsCmd)
+
+	addSelectionFlags(conflictsCmd)
+	conflictsCmd.Flags().String("from", "", "First day to check (YYYY-MM-DD)")
+	conflictsCmd.Flags().String("to", "", "Last day to check (YYYY-MM-DD)")
+	conflictsCmd.Flags().Duration("max-gap", conflict.DefaultMaxGap, "Report breaks longer than this (0 disables)")
+	conflictsCmd.Flags().Bool("live", false, "Ask the HAFAS API for travel times between campuses")
+	conflictsCmd.Flags().String("format", "text", "Output format: text or json")
+	conflictsCmd.Flags().Bool("exit-code", false, "Exit with status 1 when conflicts were found")
+}
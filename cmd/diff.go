@@ -0,0 +1,151 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"faliactl/pkg/clients"
+	"faliactl/pkg/exporter"
+	"faliactl/pkg/scraper"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/spf13/cobra"
+)
+
+var diffCmd = &cobra.Command{
+	Use:   "diff",
+	Short: "Show what changed in a schedule since it was last cached",
+	Long: `Compares the cached snapshot of a group with a fresh download and lists added, removed,
+moved (time or room changed) and renamed lectures. The fresh download replaces the snapshot,
+so running diff periodically reports every change exactly once.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		group, _ := cmd.Flags().GetString("group")
+		format, _ := cmd.Flags().GetString("format")
+		exitCode, _ := cmd.Flags().GetBool("exit-code")
+
+		if format != "text" && format != "json" && format != "unified" {
+			return fmt.Errorf("unknown format %q (use text, json or unified)", format)
+		}
+
+		urlPath := scraper.GroupPath(group)
+
+		previous, err := scraper.LoadCachedSchedule(urlPath)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+
+		client := clients.Scraper()
+		var courses []scraper.Course
+
+		err = runSpinner(cmd.Context(), fmt.Sprintf("Fetching schedule for group %s...", group), func(ctx context.Context) (err error) {
+			courses, err = client.RefreshScheduleContext(ctx, urlPath)
+			return err
+		})
+
+		if err != nil {
+			return fmt.Errorf("failed to fetch schedule: %w", err)
+		}
+
+		if previous == nil {
+			fmt.Fprintf(os.Stderr, "No cached snapshot for %s yet; saved the current schedule (%d courses) as the baseline.\n", urlPath, len(courses))
+			if structured(cmd) {
+				return printStructured(cmd, []exporter.ChangeRecord{})
+			}
+			return nil
+		}
+
+		changes := scraper.DiffSchedules(previous.Courses, courses)
+
+		// --format json predates the global --output, which wins when both are given
+		if structured(cmd) {
+			format = outputFormat(cmd)
+		}
+		switch format {
+		case outputJSON, outputYAML:
+			if err := writeStructured(os.Stdout, format, exporter.ChangeRecords(changes)); err != nil {
+				return err
+			}
+		case "unified":
+			printUnifiedDiff(urlPath, previous.Timestamp, changes)
+		default:
+			printChanges(urlPath, previous.Timestamp, changes)
+		}
+
+		if exitCode && len(changes) > 0 {
+			return findings(cmd)
+		}
+		return nil
+	},
+}
+
+func printChanges(urlPath string, since time.Time, changes []scraper.Change) {
+	if len(changes) == 0 {
+		fmt.Printf("No changes in %s since %s.\n", urlPath, since.Local().Format("2006-01-02 15:04"))
+		return
+	}
+
+	styles := map[scraper.ChangeKind]lipgloss.Style{
+		scraper.ChangeAdded:   lipgloss.NewStyle().Foreground(lipgloss.Color("42")),
+		scraper.ChangeRemoved: lipgloss.NewStyle().Foreground(lipgloss.Color("196")),
+		scraper.ChangeMoved:   lipgloss.NewStyle().Foreground(lipgloss.Color("214")),
+		scraper.ChangeRenamed: lipgloss.NewStyle().Foreground(lipgloss.Color("39")),
+	}
+	symbols := map[scraper.ChangeKind]string{
+		scraper.ChangeAdded:   "+",
+		scraper.ChangeRemoved: "-",
+		scraper.ChangeMoved:   "~",
+		scraper.ChangeRenamed: "✎",
+	}
+
+	fmt.Printf("%d change(s) in %s since %s:\n\n", len(changes), urlPath, since.Local().Format("2006-01-02 15:04"))
+	for _, c := range changes {
+		fmt.Println(styles[c.Kind].Render(fmt.Sprintf("%s %s", symbols[c.Kind], c)))
+	}
+}
+
+// printUnifiedDiff renders the changes like `diff -u`, with one hunk per day
+func printUnifiedDiff(urlPath string, since time.Time, changes []scraper.Change) {
+	if len(changes) == 0 {
+		return
+	}
+
+	fmt.Printf("--- %s\t%s (cached)\n", urlPath, since.Local().Format("2006-01-02 15:04:05"))
+	fmt.Printf("+++ %s\t%s (live)\n", urlPath, time.Now().Format("2006-01-02 15:04:05"))
+
+	line := func(c scraper.Course) string {
+		s := fmt.Sprintf("%s-%s %s", c.StartTime, c.EndTime, c.Name)
+		if c.Type != "" {
+			s += " [" + c.Type + "]"
+		}
+		if c.Room != "" {
+			s += " " + c.Room
+		}
+		return s
+	}
+
+	day := ""
+	for _, c := range changes {
+		if d := c.Course().DateStr; d != day {
+			day = d
+			fmt.Printf("@@ %s @@\n", day)
+		}
+		if c.Old != nil {
+			fmt.Println("-" + line(*c.Old))
+		}
+		if c.New != nil {
+			fmt.Println("+" + line(*c.New))
+		}
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(diffCmd)
+
+	diffCmd.Flags().StringP("group", "g", "", "Group ID to compare (e.g. 161902 or 161902.html)")
+	diffCmd.Flags().StringP("format", "f", "text", "Output format: text, json or unified")
+	diffCmd.Flags().Bool("exit-code", false, "Exit with status 1 when there are changes, like git diff --exit-code")
+	diffCmd.MarkFlagRequired("group")
+}
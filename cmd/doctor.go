@@ -0,0 +1,161 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"faliactl/pkg/clients"
+	"faliactl/pkg/config"
+	"faliactl/pkg/scraper"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/spf13/cobra"
+)
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Check that the intranet pages still have the structure faliactl expects",
+	Long: `Downloads schedule.html and one group page (bypassing the cache) and verifies every
+selector the parser relies on. Exits with code 4, like any command that hits an unexpected
+format, and a precise diagnosis when the layout changed, so it can run as a cron canary.`,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		group, _ := cmd.Flags().GetString("group")
+		strict, _ := cmd.Flags().GetBool("strict")
+		verbose, _ := cmd.Flags().GetBool("verbose")
+
+		if group == "" {
+			group = "161902"
+			if cfg, err := config.Load(); err == nil && len(cfg.SavedGroupURLs) > 0 {
+				group = cfg.SavedGroupURLs[0]
+			}
+		}
+
+		client := clients.Scraper()
+		pages := []struct {
+			path  string
+			check func(r io.Reader) (*scraper.SchemaReport, error)
+		}{
+			{"schedule.html", scraper.CheckGroupsPage},
+			{scraper.GroupPath(group), scraper.CheckSchedulePage},
+		}
+
+		output := doctorOutput{Healthy: true}
+		var failed []string
+		for _, page := range pages {
+			report, err := checkPage(cmd.Context(), client, page.path, page.check)
+			if err != nil {
+				return err
+			}
+			healthy := report.Healthy(strict)
+			if !healthy {
+				failed = append(failed, page.path)
+				output.Healthy = false
+			}
+			if structured(cmd) {
+				output.Pages = append(output.Pages, doctorPageOutput{Page: page.path, Healthy: healthy, SchemaReport: report})
+			} else {
+				printSchemaReport(page.path, report, verbose)
+			}
+		}
+
+		if structured(cmd) {
+			if err := printStructured(cmd, output); err != nil {
+				return err
+			}
+		}
+		if len(failed) > 0 {
+			return schemaDriftError(failed)
+		}
+		if !structured(cmd) {
+			fmt.Println("\nAll checks passed.")
+		}
+		return nil
+	},
+}
+
+// schemaDriftError reports the pages that failed the check as a parse error, so the canary
+// exits with the same code as a command that ran into the layout change
+func schemaDriftError(pages []string) error {
+	return &scraper.ParseError{URL: strings.Join(pages, ", "),
+		Err: fmt.Errorf("schema check failed for %d page(s)", len(pages))}
+}
+
+// doctorOutput is the schema of `doctor --output json`
+type doctorOutput struct {
+	Healthy bool               `json:"healthy"`
+	Pages   []doctorPageOutput `json:"pages"`
+}
+
+// doctorPageOutput is the schema report of one checked page
+type doctorPageOutput struct {
+	Page    string `json:"page"`
+	Healthy bool   `json:"healthy"`
+	*scraper.SchemaReport
+}
+
+func checkPage(ctx context.Context, client *scraper.Client, path string, check func(r io.Reader) (*scraper.SchemaReport, error)) (*scraper.SchemaReport, error) {
+	resp, err := client.GetContext(ctx, path)
+	if err != nil {
+		return nil, fmt.Errorf("could not download %s: %w", path, err)
+	}
+	defer resp.Body.Close()
+
+	report, err := check(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("could not inspect %s: %w", path, err)
+	}
+	return report, nil
+}
+
+// printSchemaReport prints the selector checks, errors and warnings of one page
+func printSchemaReport(page string, report *scraper.SchemaReport, verbose bool) {
+	okStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("42"))
+	warnStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("214"))
+	errStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("196"))
+
+	fmt.Printf("\n--- 🩺 %s ---\n", page)
+	if report.Popovers > 0 {
+		fmt.Printf("Popovers: %d, parsed courses: %d\n", report.Popovers, report.Courses)
+	}
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for _, check := range report.Checks {
+		mark := okStyle.Render("✔")
+		if !check.OK() {
+			if check.Required && check.Matches == 0 {
+				mark = errStyle.Render("✘")
+			} else {
+				mark = warnStyle.Render("!")
+			}
+		}
+		fmt.Fprintf(tw, "%s %s\t%s\t%d/%d\n", mark, check.Name, check.Selector, check.Matches, check.Total)
+	}
+	tw.Flush()
+
+	for _, e := range report.Errors {
+		fmt.Println(errStyle.Render("ERROR: " + e))
+	}
+	for _, w := range report.Warnings {
+		fmt.Println(warnStyle.Render("WARNING: " + w))
+	}
+
+	if verbose {
+		for _, d := range report.Diagnostics {
+			fmt.Printf("  - %s\n", d)
+		}
+	} else if len(report.Diagnostics) > 0 {
+		fmt.Println("  (run with --verbose to list every dropped popover)")
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(doctorCmd)
+	doctorCmd.Flags().StringP("group", "g", "", "Group page to check (defaults to your first saved group, then 161902)")
+	doctorCmd.Flags().Bool("strict", false, "Treat warnings (partially missing fields) as failures")
+	doctorCmd.Flags().BoolP("verbose", "v", false, "List every popover the parser dropped")
+}
@@ -0,0 +1,10 @@
+package cmd
+
+import "testing"
+
+func TestSchemaDriftError_ExitCode(t *testing.T) {
+	err := schemaDriftError([]string{"schedule.html", "161902.html"})
+	if code := exitCode(err); code != exitParse {
+		t.Errorf("schema drift should exit with %d like any parse error, got %d (%v)", exitParse, code, err)
+	}
+}
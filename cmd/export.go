@@ -1,62 +1,118 @@
 package cmd
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
+	"io"
 	"os"
+	"path/filepath"
+	"regexp"
 	"strings"
+	"time"
 
+	"faliactl/pkg/clients"
+	"faliactl/pkg/config"
 	"faliactl/pkg/exporter"
+	"faliactl/pkg/filter"
 	"faliactl/pkg/scraper"
+	"faliactl/pkg/sets"
 
-	"github.com/charmbracelet/huh/spinner"
 	"github.com/spf13/cobra"
 )
 
 var exportCmd = &cobra.Command{
 	Use:   "export",
-	Short: "Directly export a schedule to an ICS file",
-	Long:  `Export a schedule for a specific group to an ICS file without using the interactive TUI.`,
+	Short: "Directly export a schedule to an ICS, JSON, CSV, Markdown, HTML or jCal file",
+	Long: `Export the schedule of one or more groups without using the interactive TUI.
+
+Groups come from --group (repeatable), --set or --use-saved and are merged without duplicates.
+--course keeps only the named courses, --exclude drops them, and --from/--to limit the dates.
+Use "-o -" to write the calendar to stdout, e.g. in cron jobs or Makefiles.
+
+--format picks the output format; without it the extension of --output decides, falling back to ICS.`,
+	Example: `  faliactl export --group 161902 --group 161903 --course "Lineare Algebra" -o linalg.ics
+  faliactl export --set my-classes --from 2026-03-01 --to 2026-07-31 -o - > summer.ics
+  faliactl export --use-saved --exclude "Mathematik 1"
+  faliactl export --group 161902 -o week.html`,
+	SilenceUsage: true,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		group, _ := cmd.Flags().GetString("group")
 		output, _ := cmd.Flags().GetString("output")
+		toStdout := output == "-"
 
-		// Ensure it has .html suffix
-		urlPath := group
-		if !strings.HasSuffix(urlPath, ".html") {
-			urlPath += ".html"
+		// Progress and status messages must not end up inside a calendar written to stdout
+		var status io.Writer = os.Stdout
+		if toStdout {
+			status = os.Stderr
 		}
 
-		client := scraper.NewClient()
-		var courses []scraper.Course
-		var err error
+		format, err := exportFormat(cmd, output)
+		if err != nil {
+			return err
+		}
+
+		sel, err := exportSelection(cmd)
+		if err != nil {
+			return err
+		}
 
-		_ = spinner.New().
-			Title(fmt.Sprintf("Exporting schedule for group %s to %s...", group, output)).
-			Action(func() {
-				courses, err = client.FetchSchedule(urlPath)
-			}).
-			Run()
+		var courses []scraper.Course
+		fetch := func(ctx context.Context) (err error) {
+			courses, err = fetchGroups(ctx, clients.Scraper(), sel.groups)
+			return err
+		}
 
+		groupList := strings.Join(sel.groups, ", ")
+		if toStdout {
+			err = fetch(cmd.Context())
+		} else {
+			err = runSpinner(cmd.Context(), fmt.Sprintf("Exporting schedule for group %s to %s...", groupList, output), fetch)
+		}
 		if err != nil {
-			return fmt.Errorf("failed to fetch schedule: %w", err)
+			return err
 		}
 
 		if len(courses) == 0 {
-			return fmt.Errorf("no courses found for group %s", group)
+			return fmt.Errorf("no courses found for group %s", groupList)
 		}
 
-		file, err := os.Create(output)
-		if err != nil {
-			return fmt.Errorf("failed to create output file: %w", err)
+		for _, f := range sel.filters {
+			courses = f.Apply(courses)
+		}
+		if len(courses) == 0 {
+			return fmt.Errorf("the course selection removed every course of group %s", groupList)
 		}
-		defer file.Close()
 
-		err = exporter.GenerateICS(courses, file)
+		var out io.Writer = os.Stdout
+		var stateName string
+		if toStdout {
+			// Without a file to key the state on, the selection itself identifies the calendar
+			stateName = "stdout-" + strings.Join(sel.groups, "+")
+		} else {
+			file, err := os.Create(output)
+			if err != nil {
+				return fmt.Errorf("failed to create output file: %w", err)
+			}
+			defer file.Close()
+			out = file
+
+			// The state is keyed by the output path: that file is what calendar apps subscribe to
+			stateName = output
+			if abs, absErr := filepath.Abs(output); absErr == nil {
+				stateName = abs
+			}
+		}
+
+		err = exporter.ExportTracked(stateName, format, courses, out, exporter.Options{CancelGrace: cancelGrace(cmd)})
 		if err != nil {
-			return fmt.Errorf("failed to generate ICS: %w", err)
+			return fmt.Errorf("failed to generate %s: %w", format.Name(), err)
 		}
 
-		fmt.Printf("Successfully exported %d courses to %s\n", len(courses), output)
+		if toStdout {
+			fmt.Fprintf(status, "Exported %d courses\n", len(courses))
+		} else {
+			fmt.Fprintf(status, "Successfully exported %d courses to %s\n", len(courses), output)
+		}
 		return nil
 	},
 }
@@ -64,7 +120,210 @@ var exportCmd = &cobra.Command{
 func init() {
 	rootCmd.AddCommand(exportCmd)
 
-	exportCmd.Flags().StringP("group", "g", "", "Group ID to export (e.g. 161902 or 161902.html)")
-	exportCmd.Flags().StringP("output", "o", "schedule.ics", "Output file path")
-	exportCmd.MarkFlagRequired("group")
+	addSelectionFlags(exportCmd)
+	exportCmd.Flags().String("from", "", "First day to export (YYYY-MM-DD)")
+	exportCmd.Flags().String("to", "", "Last day to export (YYYY-MM-DD)")
+	exportCmd.Flags().StringP("output", "o", "schedule.ics", "Output file path, or - for stdout")
+	exportCmd.Flags().StringP("format", "F", "", "Output format: "+strings.Join(exporter.Formats(), ", ")+" (default from the --output extension, then ics)")
+	exportCmd.Flags().Int("cancel-grace-days", 0, "Days a removed lecture stays in the calendar as cancelled (default from config, then 14; negative disables)")
+}
+
+// exportFormat resolves --format, falling back to the extension of the output file
+func exportFormat(cmd *cobra.Command, output string) (exporter.Exporter, error) {
+	name, _ := cmd.Flags().GetString("format")
+	if name == "" {
+		if format, ok := exporter.Lookup(strings.TrimPrefix(filepath.Ext(output), ".")); ok {
+			return format, nil
+		}
+		name = "ics"
+	}
+	format, ok := exporter.Lookup(name)
+	if !ok {
+		return nil, fmt.Errorf("unknown format %q (available: %s)", name, strings.Join(exporter.Formats(), ", "))
+	}
+	return format, nil
+}
+
+// addSelectionFlags declares the flags read by exportSelection and exportFilter
+func addSelectionFlags(cmd *cobra.Command) {
+	cmd.Flags().StringSliceP("group", "g", nil, "Group ID (e.g. 161902 or 161902.html); repeatable")
+	cmd.Flags().StringArrayP("course", "c", nil, "Only include courses with this exact name; repeatable")
+	cmd.Flags().StringArrayP("exclude", "x", nil, "Skip courses with this exact name; repeatable")
+	cmd.Flags().String("set", "", "Use a named set from the sets file (its groups, courses and filter)")
+	cmd.Flags().String("sets", "sets.json", "Path to the sets file used by --set")
+	cmd.Flags().Bool("use-saved", false, "Use your saved groups and courses from the config file")
+	cmd.Flags().String("filter", "", "JSON file with a course filter (default: \"filter\" from the config file)")
+	cmd.Flags().Bool("no-filter", false, "Ignore the filter from the config file")
+}
+
+// selection is what the export flags resolve to: the groups to fetch and the filters applied in order
+type selection struct {
+	groups  []string
+	filters []*filter.Compiled
+}
+
+// exportSelection merges --group, --set and --use-saved and turns the course flags into filters
+func exportSelection(cmd *cobra.Command) (*selection, error) {
+	groups, _ := cmd.Flags().GetStringSlice("group")
+	courses, _ := cmd.Flags().GetStringArray("course")
+	excluded, _ := cmd.Flags().GetStringArray("exclude")
+	setName, _ := cmd.Flags().GetString("set")
+	useSaved, _ := cmd.Flags().GetBool("use-saved")
+	from, _ := cmd.Flags().GetString("from")
+	to, _ := cmd.Flags().GetString("to")
+
+	sel := &selection{}
+	var setFilter *filter.Filter
+
+	if setName != "" {
+		path, _ := cmd.Flags().GetString("sets")
+		f, err := sets.Load(path)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		set, ok := f.Lookup(setName)
+		if !ok {
+			return nil, unknownSetError(f, setName)
+		}
+		groups = append(groups, set.Groups...)
+		courses = append(courses, set.Courses...)
+		setFilter = set.Filter
+	}
+
+	if useSaved {
+		cfg, err := config.Load()
+		if err != nil {
+			return nil, fmt.Errorf("failed to load config: %w", err)
+		}
+		if len(cfg.SavedGroupURLs) == 0 {
+			return nil, fmt.Errorf("no saved groups: pick some in the interactive settings first")
+		}
+		groups = append(groups, cfg.SavedGroupURLs...)
+		courses = append(courses, cfg.SavedCourses...)
+	}
+
+	if len(groups) == 0 {
+		return nil, fmt.Errorf("no groups to export: use --group, --set or --use-saved")
+	}
+
+	// 161902 and 161902.html are the same group
+	seen := make(map[string]bool)
+	for _, group := range groups {
+		if id := strings.TrimSuffix(group, ".html"); !seen[id] {
+			seen[id] = true
+			sel.groups = append(sel.groups, id)
+		}
+	}
+
+	// Course names refer to the names on the intranet, so they are matched before any renames
+	names := &filter.Filter{From: from, To: to}
+	for _, name := range courses {
+		names.Include = append(names.Include, filter.Match{Name: "^" + regexp.QuoteMeta(name) + "$"})
+	}
+	for _, name := range excluded {
+		names.Exclude = append(names.Exclude, filter.Match{Name: "^" + regexp.QuoteMeta(name) + "$"})
+	}
+
+	userFilter, err := exportFilter(cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, f := range []*filter.Filter{names, setFilter} {
+		compiled, err := f.Compile()
+		if err != nil {
+			return nil, fmt.Errorf("invalid course selection: %w", err)
+		}
+		sel.filters = append(sel.filters, compiled)
+	}
+	sel.filters = append(sel.filters, userFilter)
+	return sel, nil
+}
+
+// loadSelection fetches and filters the courses chosen by the selection flags. Without --group
+// or --set it falls back to the saved groups and courses.
+func loadSelection(cmd *cobra.Command) ([]scraper.Course, error) {
+	if !cmd.Flags().Changed("group") && !cmd.Flags().Changed("set") {
+		cmd.Flags().Set("use-saved", "true")
+	}
+	sel, err := exportSelection(cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	var courses []scraper.Course
+	err = runSpinner(cmd.Context(), "Fetching schedules...", func(ctx context.Context) (err error) {
+		courses, err = fetchGroups(ctx, clients.Scraper(), sel.groups)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range sel.filters {
+		courses = f.Apply(courses)
+	}
+	return courses, nil
+}
+
+// fetchGroups downloads the schedules of the groups and merges them without duplicates
+func fetchGroups(ctx context.Context, client *scraper.Client, groups []string) ([]scraper.Course, error) {
+	list := make([]scraper.Group, len(groups))
+	for i, group := range groups {
+		list[i] = scraper.Group{Name: group, URL: scraper.GroupPath(group)}
+	}
+	result, err := client.FetchAll(ctx, list, scraper.BulkOptions{Retries: -1})
+	if err != nil {
+		return nil, err
+	}
+	// Like FetchSchedule, a week-old copy is better than no calendar when the intranet is down
+	for _, res := range result.Results {
+		if res.Err != nil && res.Status != scraper.CacheStale {
+			return nil, fmt.Errorf("failed to fetch schedule for group %s: %w", res.Group.Name, res.Err)
+		}
+	}
+	return result.Courses(), nil
+}
+
+// exportFilter compiles the filter from --filter, falling back to the one in the config file
+func exportFilter(cmd *cobra.Command) (*filter.Compiled, error) {
+	path, _ := cmd.Flags().GetString("filter")
+	noFilter, _ := cmd.Flags().GetBool("no-filter")
+
+	var f *filter.Filter
+	switch {
+	case path != "":
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read filter: %w", err)
+		}
+		f = &filter.Filter{}
+		if err := json.Unmarshal(data, f); err != nil {
+			return nil, fmt.Errorf("invalid filter %s: %w", path, err)
+		}
+	case !noFilter:
+		if cfg, err := config.Load(); err == nil {
+			f = cfg.Filter
+		}
+	}
+
+	compiled, err := f.Compile()
+	if err != nil {
+		return nil, fmt.Errorf("invalid filter: %w", err)
+	}
+	return compiled, nil
+}
+
+// cancelGrace resolves the cancellation grace period from --cancel-grace-days or the config file
+func cancelGrace(cmd *cobra.Command) time.Duration {
+	if cmd.Flags().Changed("cancel-grace-days") {
+		days, _ := cmd.Flags().GetInt("cancel-grace-days")
+		if days == 0 {
+			return -1 // An explicit 0 means "no grace period", not "use the default"
+		}
+		return time.Duration(days) * 24 * time.Hour
+	}
+	if cfg, err := config.Load(); err == nil {
+		return cfg.CancelGrace()
+	}
+	return 0
 }
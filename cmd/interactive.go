@@ -11,7 +11,7 @@ var interactiveCmd = &cobra.Command{
 	Short: "Launch the interactive TUI",
 	Long:  `Launch the Text User Interface to browse groups, filter courses, and export schedules interactively.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		return tui.RunTUI()
+		return tui.RunTUI(cmd.Context())
 	},
 }
 
@@ -0,0 +1,251 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"faliactl/pkg/campus"
+	"faliactl/pkg/exporter"
+	"faliactl/pkg/rooms"
+	"faliactl/pkg/scraper"
+	"faliactl/pkg/timetable"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/spf13/cobra"
+)
+
+var locateCmd = &cobra.Command{
+	Use:   "locate <room>",
+	Short: "Find a room: campus, building, floor, directions and a rough map",
+	Long: `Resolves a room code such as WF-EX-2/127, or a campus followed by a building such as
+"Am Exer 11", and prints the address, the building and floor, directions from the campus
+entrance and ASCII sketches of the site and the building.
+
+With --schedule (or --week) it works the other way round and lists what takes place in the
+room, or anywhere in the building, by scanning the locally cached schedules.
+
+Buildings and rooms missing from the bundled database can be added to ~/.faliactl_rooms.json,
+which uses the same format and replaces bundled entries with the same campus and code.`,
+	Example: `  faliactl locate WF-EX-2/127
+  faliactl locate "Am Exer 11"
+  faliactl locate WF-EX-7/3 --schedule
+  faliactl locate WF-C-015 --week next`,
+	Args:         cobra.MinimumNArgs(1),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dbPath, _ := cmd.Flags().GetString("db")
+		schedule, _ := cmd.Flags().GetBool("schedule")
+		weekSpec, _ := cmd.Flags().GetString("week")
+		noMap, _ := cmd.Flags().GetBool("no-map")
+
+		if dbPath == "" {
+			path, err := rooms.UserPath()
+			if err != nil {
+				return err
+			}
+			dbPath = path
+		}
+		db, err := rooms.Load(dbPath)
+		if err != nil {
+			return err
+		}
+
+		registry := campus.Default()
+		loc, err := rooms.Locate(registry, db, strings.Join(args, " "))
+		if err != nil {
+			return err
+		}
+
+		if schedule || cmd.Flags().Changed("week") {
+			return printOccupancy(cmd, registry, loc, weekSpec)
+		}
+		if structured(cmd) {
+			return printStructured(cmd, newLocationOutput(db, loc))
+		}
+		printLocation(db, loc, !noMap)
+		return nil
+	},
+}
+
+// locationOutput is the schema of `locate --output json`
+type locationOutput struct {
+	Room         string   `json:"room"`
+	Campus       string   `json:"campus"` // Campus ID
+	CampusName   string   `json:"campus_name"`
+	Address      string   `json:"address"`
+	Building     string   `json:"building"`
+	BuildingName string   `json:"building_name,omitempty"`
+	Floors       int      `json:"floors,omitempty"`
+	Floor        string   `json:"floor,omitempty"`
+	Number       string   `json:"number,omitempty"` // Missing when only a building was asked for
+	Name         string   `json:"name,omitempty"`
+	Stop         string   `json:"stop,omitempty"`
+	Directions   []string `json:"directions"`
+}
+
+func newLocationOutput(db *rooms.Database, loc *rooms.Location) locationOutput {
+	out := locationOutput{
+		Room:       loc.Room.Code,
+		Campus:     loc.Campus.ID,
+		CampusName: loc.Campus.Name,
+		Address:    loc.Address(),
+		Building:   loc.Room.Building,
+		Floor:      loc.Room.Floor,
+		Number:     loc.Room.Number,
+		Stop:       loc.Campus.StationName,
+		Directions: rooms.Directions(db, loc),
+	}
+	if loc.Building != nil {
+		out.BuildingName = loc.Building.Label()
+		out.Floors = loc.Building.Floors
+	}
+	if loc.Info != nil {
+		out.Name = loc.Info.Name
+	}
+	if out.Directions == nil {
+		out.Directions = []string{}
+	}
+	return out
+}
+
+// occupancyOutput is the schema of `locate --schedule --output json`
+type occupancyOutput struct {
+	Room    string                  `json:"room"`
+	Week    string                  `json:"week"`   // ISO week, e.g. 2026-W42
+	Monday  string                  `json:"monday"` // YYYY-MM-DD
+	Scanned int                     `json:"scanned_groups"`
+	Courses []exporter.CourseRecord `json:"courses"`
+}
+
+func printLocation(db *rooms.Database, loc *rooms.Location, maps bool) {
+	titleStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("39"))
+	labelStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("245"))
+	row := func(label, value string) {
+		if value != "" {
+			fmt.Printf("  %s %s\n", labelStyle.Render(fmt.Sprintf("%-9s", label+":")), value)
+		}
+	}
+
+	fmt.Println(titleStyle.Render(loc.Room.Code))
+	row("Campus", loc.Campus.Name)
+	row("Address", loc.Address())
+	if loc.Building != nil {
+		building := loc.Building.Label()
+		if loc.Building.Floors > 0 {
+			building += fmt.Sprintf(" (%d floors)", loc.Building.Floors)
+		}
+		row("Building", building)
+	} else {
+		row("Building", loc.Room.Building)
+	}
+	row("Floor", loc.Room.Floor)
+	room := loc.Room.Number
+	if loc.Info != nil && loc.Info.Name != "" {
+		room += " – " + loc.Info.Name
+	}
+	row("Room", room)
+	row("Stop", loc.Campus.StationName)
+
+	if steps := rooms.Directions(db, loc); len(steps) > 0 {
+		fmt.Println()
+		fmt.Println(titleStyle.Render("Directions"))
+		for i, step := range steps {
+			fmt.Printf("  %d. %s\n", i+1, step)
+		}
+	}
+
+	if !maps {
+		return
+	}
+	if site := rooms.SiteMap(db, loc); site != "" {
+		fmt.Println()
+		fmt.Println(titleStyle.Render("Site"))
+		fmt.Print(site)
+	}
+	if floors := rooms.FloorSketch(loc); floors != "" {
+		fmt.Println()
+		fmt.Println(titleStyle.Render(loc.Building.Label()))
+		fmt.Print(floors)
+	}
+}
+
+// printOccupancy lists the courses of the week taking place at the location, from the cache
+func printOccupancy(cmd *cobra.Command, registry *campus.Registry, loc *rooms.Location, weekSpec string) error {
+	monday, err := timetable.ParseWeek(weekSpec, time.Now())
+	if err != nil {
+		return err
+	}
+
+	infos, err := scraper.ListCache()
+	if err != nil {
+		return err
+	}
+	var courses []scraper.Course
+	scanned := 0
+	for _, info := range infos {
+		entry, err := scraper.LoadCachedSchedule(info.GroupURL)
+		if err != nil {
+			continue // Corrupt entries are reported by 'faliactl cache list'
+		}
+		scanned++
+		courses = append(courses, entry.Courses...)
+	}
+	if scanned == 0 {
+		return errors.New("no cached schedules to scan; export or view some groups first so they are cached")
+	}
+
+	occupied := rooms.Occupancy(registry, loc, courses, monday, monday.AddDate(0, 0, 7))
+	year, number := monday.ISOWeek()
+	if structured(cmd) {
+		return printStructured(cmd, occupancyOutput{
+			Room:    loc.Room.Code,
+			Week:    fmt.Sprintf("%d-W%02d", year, number),
+			Monday:  monday.Format("2006-01-02"),
+			Scanned: scanned,
+			Courses: exporter.CourseRecords(occupied),
+		})
+	}
+	what := "Room " + loc.Room.Code
+	if loc.Room.Number == "" {
+		what = fmt.Sprintf("Building %s (%s)", loc.Room.Building, loc.Campus.Name)
+	}
+	fmt.Println(lipgloss.NewStyle().Bold(true).Render(fmt.Sprintf("%s, week %d: %s – %s",
+		what, number, monday.Format("02.01."), monday.AddDate(0, 0, 6).Format("02.01.2006"))))
+
+	if len(occupied) == 0 {
+		fmt.Printf("Nothing scheduled in the %d cached group(s).\n", scanned)
+		return nil
+	}
+	dayStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("39"))
+	lastDay := ""
+	for _, c := range occupied {
+		if day := c.Start.Format("Mon 02.01."); day != lastDay {
+			fmt.Println(dayStyle.Render(day))
+			lastDay = day
+		}
+		line := fmt.Sprintf("  %s–%s  %s", c.StartTime, c.EndTime, c.Name)
+		if c.Type != "" {
+			line += " (" + c.Type + ")"
+		}
+		if loc.Room.Number == "" {
+			line += "  " + c.Room
+		}
+		if c.GroupStr != "" {
+			line += "  " + lipgloss.NewStyle().Faint(true).Render(c.GroupStr)
+		}
+		fmt.Println(line)
+	}
+	fmt.Printf("\nScanned %d cached group(s); groups that were never fetched are missing.\n", scanned)
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(locateCmd)
+
+	locateCmd.Flags().String("db", "", "Room database with additions to the bundled one (default ~/.faliactl_rooms.json)")
+	locateCmd.Flags().BoolP("schedule", "s", false, "List what takes place in the room this week, from the cached schedules")
+	locateCmd.Flags().StringP("week", "w", "", "Week to list with --schedule: 2026-W42, a date inside the week, next or last")
+	locateCmd.Flags().Bool("no-map", false, "Don't draw the site map and floor sketch")
+}
@@ -1,14 +1,19 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
+	"os"
 	"strings"
 	"time"
 
+	"faliactl/pkg/campus"
+	"faliactl/pkg/clients"
 	"faliactl/pkg/mensa"
+	"faliactl/pkg/timetable"
 
-	"github.com/charmbracelet/huh/spinner"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/term"
 	"github.com/spf13/cobra"
 )
 
@@ -17,36 +22,53 @@ var (
 	dateStr  string
 )
 
-// campusMap maps human readable names to API location IDs
-var campusMap = map[string]int{
-	"wolfenbuettel": 130, // Main Mensa Wolfenbüttel
-	"wolfsburg":     112, // Bistro 4U Wolfsburg
-	"suderburg":     134, // Mensa Suderburg
-	"salzgitter":    200, // Mensa Salzgitter
-}
-
 var mensaCmd = &cobra.Command{
 	Use:   "mensa",
 	Short: "View the Mensa menu for a specific campus",
-	Long:  `Fetch and display the daily cafeteria menu for Ostfalia campuses.`,
+	Long: `Fetch and display the daily cafeteria menu for Ostfalia campuses.
+
+--week, --days and --from/--to fetch several days at once and show them as a matrix with one
+row per day and one column per counter. --ics writes the same days as all-day calendar events.`,
+	Example: `  faliactl mensa --campus wolfenbuettel
+  faliactl mensa --week
+  faliactl mensa --week=next
+  faliactl mensa --days 3 --date 2026-10-19
+  faliactl mensa --from 2026-10-19 --to 2026-10-30 --ics mensa.ics`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		// --week has an optional value, so "--week next" leaves "next" behind as an argument
+		if len(args) > 0 && cmd.Flags().Changed("week") {
+			return fmt.Errorf("pass the week with an equals sign: --week=%s", args[0])
+		}
+		return cobra.NoArgs(cmd, args)
+	},
+	SilenceUsage: true,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		campusName, _ := cmd.Flags().GetString("campus")
+		icsPath, _ := cmd.Flags().GetString("ics")
+		width, _ := cmd.Flags().GetInt("width")
 
-		client := mensa.NewClient()
+		dates, err := mensaDates(cmd, time.Now())
+		if err != nil {
+			return err
+		}
+
+		client := clients.Mensa()
 
-		locID, ok := campusMap[campusName]
+		// The campus registry knows the main Mensa of every site
+		var locID int
+		site, ok := campus.Default().Lookup(campusName)
+		if ok && len(site.MensaIDs) > 0 {
+			locID = site.MensaIDs[0]
+		}
 		if campusID != 0 {
 			locID = campusID
-		} else if !ok {
+		} else if locID == 0 {
 			// Fallback: fetch dynamically and substring match
 			var locations []mensa.Location
-			var err error
-			_ = spinner.New().
-				Title("Searching for Mensa location...").
-				Action(func() {
-					locations, err = client.FetchLocations()
-				}).
-				Run()
+			err := runSpinner(cmd.Context(), "Searching for Mensa location...", func(ctx context.Context) (err error) {
+				locations, err = client.FetchLocationsContext(ctx)
+				return err
+			})
 
 			if err == nil {
 				for _, loc := range locations {
@@ -66,25 +88,39 @@ var mensaCmd = &cobra.Command{
 		if fetchDate == "" {
 			fetchDate = time.Now().Format("2006-01-02")
 		}
+		if dates == nil && icsPath != "" {
+			dates = []string{fetchDate}
+		}
+		if dates != nil {
+			return runMensaDays(cmd, client, locID, dates, icsPath, width)
+		}
 
 		var menu *mensa.MenuResponse
-		var err error
-		_ = spinner.New().
-			Title(fmt.Sprintf("Fetching menu for %s...", fetchDate)).
-			Action(func() {
-				menu, err = client.FetchMenu(locID, fetchDate)
-			}).
-			Run()
+		err = runSpinner(cmd.Context(), fmt.Sprintf("Fetching menu for %s...", fetchDate), func(ctx context.Context) (err error) {
+			menu, err = client.FetchMenuContext(ctx, locID, fetchDate)
+			return err
+		})
 
 		if err != nil {
 			return fmt.Errorf("could not fetch menu: %w", err)
 		}
 
+		if structured(cmd) {
+			return printStructured(cmd, menuOutput{Date: fetchDate, LocationID: locID, MenuResponse: menu})
+		}
 		printMenu(menu, fetchDate)
 		return nil
 	},
 }
 
+// menuOutput is the schema of `mensa --output json`: the announcements and meals of the
+// Mensa API, plus the date and location they are for
+type menuOutput struct {
+	Date       string `json:"date"`
+	LocationID int    `json:"location_id"`
+	*mensa.MenuResponse
+}
+
 func printMenu(menu *mensa.MenuResponse, date string) {
 	titleStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("99")).Bold(true).Padding(1, 0)
 	priceStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("42"))
@@ -110,10 +146,8 @@ func printMenu(menu *mensa.MenuResponse, date string) {
 
 	for _, meal := range menu.Meals {
 		vegan := ""
-		for _, cat := range meal.Tags.Categories {
-			if cat.ID == "VEGA" {
-				vegan = lipgloss.NewStyle().Foreground(lipgloss.Color("46")).Render(" [Vegan]")
-			}
+		if meal.Vegan() {
+			vegan = lipgloss.NewStyle().Foreground(lipgloss.Color("46")).Render(" [Vegan]")
 		}
 
 		extras := []string{}
@@ -145,9 +179,256 @@ func printMenu(menu *mensa.MenuResponse, date string) {
 	}
 }
 
+// maxMensaDays is the longest range fetched at once, to keep the number of requests sane
+const maxMensaDays = 31
+
+// mensaDates resolves --week, --days and --from/--to to the days to fetch. It returns nil when
+// none of them is given and the single day of --date is shown.
+func mensaDates(cmd *cobra.Command, now time.Time) ([]string, error) {
+	weekSpec, _ := cmd.Flags().GetString("week")
+	days, _ := cmd.Flags().GetInt("days")
+	from, _ := cmd.Flags().GetString("from")
+	to, _ := cmd.Flags().GetString("to")
+
+	week := cmd.Flags().Changed("week")
+	span := cmd.Flags().Changed("days")
+	between := from != "" || to != ""
+	if (week && span) || (week && between) || (span && between) {
+		return nil, fmt.Errorf("--week, --days and --from/--to cannot be combined")
+	}
+	if dateStr != "" && (week || between) {
+		return nil, fmt.Errorf("--date only combines with --days")
+	}
+
+	var first, last time.Time
+	switch {
+	case week:
+		monday, err := timetable.ParseWeek(weekSpec, now)
+		if err != nil {
+			return nil, err
+		}
+		first, last = monday, monday.AddDate(0, 0, 4)
+	case span:
+		if days < 1 || days > maxMensaDays {
+			return nil, fmt.Errorf("--days must be between 1 and %d", maxMensaDays)
+		}
+		first = time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+		if dateStr != "" {
+			start, err := time.Parse("2006-01-02", dateStr)
+			if err != nil {
+				return nil, fmt.Errorf("invalid --date %q, expected YYYY-MM-DD", dateStr)
+			}
+			first = start
+		}
+		last = first.AddDate(0, 0, days-1)
+	case between:
+		if from == "" || to == "" {
+			return nil, fmt.Errorf("--from and --to must be given together")
+		}
+		var err error
+		if first, err = time.Parse("2006-01-02", from); err != nil {
+			return nil, fmt.Errorf("invalid --from %q, expected YYYY-MM-DD", from)
+		}
+		if last, err = time.Parse("2006-01-02", to); err != nil {
+			return nil, fmt.Errorf("invalid --to %q, expected YYYY-MM-DD", to)
+		}
+		if last.Before(first) {
+			return nil, fmt.Errorf("--to %s is before --from %s", to, from)
+		}
+	default:
+		return nil, nil
+	}
+
+	var dates []string
+	for d := first; !d.After(last); d = d.AddDate(0, 0, 1) {
+		dates = append(dates, d.Format("2006-01-02"))
+	}
+	if len(dates) > maxMensaDays {
+		return nil, fmt.Errorf("at most %d days can be fetched at once", maxMensaDays)
+	}
+	return dates, nil
+}
+
+// menuDaysOutput is the schema of `mensa --output json` with --week, --days or --from/--to
+type menuDaysOutput struct {
+	LocationID int             `json:"location_id"`
+	From       string          `json:"from"`
+	To         string          `json:"to"`
+	Days       []mensa.DayMenu `json:"days"`
+}
+
+// runMensaDays fetches several days concurrently and shows them as a matrix, as structured
+// output or as an ICS calendar
+func runMensaDays(cmd *cobra.Command, client *mensa.Client, locID int, dates []string, icsPath string, width int) error {
+	title := fmt.Sprintf("Fetching menus for %s...", dates[0])
+	if len(dates) > 1 {
+		title = fmt.Sprintf("Fetching menus for %s – %s...", dates[0], dates[len(dates)-1])
+	}
+
+	var days []mensa.DayMenu
+	err := runSpinner(cmd.Context(), title, func(ctx context.Context) (err error) {
+		days, err = client.FetchMenusContext(ctx, locID, dates)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("could not fetch menus: %w", err)
+	}
+
+	switch {
+	case icsPath == "-":
+		return mensa.WriteICS(os.Stdout, locID, days)
+	case icsPath != "":
+		file, err := os.Create(icsPath)
+		if err != nil {
+			return fmt.Errorf("failed to create output file: %w", err)
+		}
+		defer file.Close()
+		if err := mensa.WriteICS(file, locID, days); err != nil {
+			return err
+		}
+		fmt.Printf("Wrote %d day(s) of menus to %s\n", len(days), icsPath)
+		return nil
+	case structured(cmd):
+		return printStructured(cmd, menuDaysOutput{LocationID: locID, From: dates[0], To: dates[len(dates)-1], Days: days})
+	}
+
+	if width <= 0 {
+		width = 120
+		if w, _, err := term.GetSize(os.Stdout.Fd()); err == nil && w > 0 {
+			width = w
+		}
+	}
+	printMenuMatrix(days, width)
+	return nil
+}
+
+const matrixDayWidth = 11 // "Mon 19.10. "
+
+// printMenuMatrix draws one row per day and one column per lane, with the meals of a lane
+// stacked in its cell, student price first. Announcements and closed days span the row.
+func printMenuMatrix(days []mensa.DayMenu, width int) {
+	titleStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("99")).Bold(true)
+	headerStyle := lipgloss.NewStyle().Bold(true)
+	ruleStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("238"))
+	warnStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("196"))
+	veganStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("46"))
+	sep := ruleStyle.Render("│")
+
+	fmt.Println(titleStyle.Render(fmt.Sprintf("Mensa Menu %s – %s", days[0].Date, days[len(days)-1].Date)))
+
+	// Lanes in the order they first appear
+	var lanes []string
+	laneIndex := make(map[string]int)
+	for _, d := range days {
+		for _, m := range d.Meals {
+			if _, ok := laneIndex[m.Lane.Name]; !ok {
+				laneIndex[m.Lane.Name] = len(lanes)
+				lanes = append(lanes, m.Lane.Name)
+			}
+		}
+	}
+
+	laneWidth := 20
+	if len(lanes) > 0 {
+		laneWidth = (width - matrixDayWidth - len(lanes)) / len(lanes)
+		if laneWidth < 12 {
+			laneWidth = 12
+		}
+	}
+	rule := ruleStyle.Render(strings.Repeat("─", matrixDayWidth+len(lanes)*(laneWidth+1)))
+
+	header := padCell("", matrixDayWidth)
+	for _, lane := range lanes {
+		header += sep + headerStyle.Render(padCell(lane, laneWidth))
+	}
+	fmt.Println(header)
+	fmt.Println(rule)
+
+	for _, d := range days {
+		label := d.Date
+		if date, err := time.Parse("2006-01-02", d.Date); err == nil {
+			label = date.Format("Mon 02.01.")
+		}
+		// The date only goes on the first line of the day
+		line := func(s string) {
+			fmt.Println(padCell(label, matrixDayWidth) + s)
+			label = ""
+		}
+
+		announcedClosed := false
+		for _, a := range d.Announcements {
+			text := a.Text
+			if a.Closed {
+				text = "CLOSED: " + text
+				announcedClosed = true
+			}
+			line(warnStyle.Render("⚠ " + text))
+		}
+		if d.Closed {
+			if !announcedClosed {
+				line(warnStyle.Render("closed"))
+			}
+			fmt.Println(rule)
+			continue
+		}
+
+		cells := make([][]string, len(lanes))
+		rows := 0
+		for _, m := range d.Meals {
+			text := m.Name
+			if m.Price.Student != "" {
+				text = m.Price.Student + " " + text
+			}
+			cell := padCell(text, laneWidth)
+			if m.Vegan() {
+				cell = veganStyle.Render(cell)
+			}
+			i := laneIndex[m.Lane.Name]
+			cells[i] = append(cells[i], cell)
+			rows = max(rows, len(cells[i]))
+		}
+		if rows == 0 {
+			line("No meals")
+		}
+		for r := 0; r < rows; r++ {
+			var b strings.Builder
+			for i := range lanes {
+				b.WriteString(sep)
+				if r < len(cells[i]) {
+					b.WriteString(cells[i][r])
+				} else {
+					b.WriteString(padCell("", laneWidth))
+				}
+			}
+			line(b.String())
+		}
+		fmt.Println(rule)
+	}
+	fmt.Println(veganStyle.Render("vegan") + ", prices for students in €")
+}
+
+// padCell truncates or right-pads s to exactly width terminal cells
+func padCell(s string, width int) string {
+	if w := lipgloss.Width(s); w <= width {
+		return s + strings.Repeat(" ", width-w)
+	}
+	runes := []rune(s)
+	for len(runes) > 0 && lipgloss.Width(string(runes))+1 > width {
+		runes = runes[:len(runes)-1]
+	}
+	return string(runes) + "…"
+}
+
 func init() {
 	rootCmd.AddCommand(mensaCmd)
-	mensaCmd.Flags().StringP("campus", "c", "wolfenbuettel", "Campus name (wolfenbuettel, wolfsburg, suderburg, salzgitter)")
+	mensaCmd.Flags().StringP("campus", "c", "wolfenbuettel", "Campus ID, alias or city (wolfenbuettel, wolfsburg, suderburg, salzgitter), or part of a Mensa name")
 	mensaCmd.Flags().IntVar(&campusID, "id", 0, "Direct Mensa Location ID (overrides campus flag)")
-	mensaCmd.Flags().StringVarP(&dateStr, "date", "d", "", "Date to fetch (format: YYYY-MM-DD), defaults to today")
+	mensaCmd.Flags().StringVarP(&dateStr, "date", "d", "", "Date to fetch (format: YYYY-MM-DD), defaults to today; the first day with --days")
+	mensaCmd.Flags().StringP("week", "w", "", "Show Monday to Friday of a week: --week, --week=next or --week=2026-W42")
+	mensaCmd.Flags().Lookup("week").NoOptDefVal = "this"
+	mensaCmd.Flags().Int("days", 0, "Show this many days, starting today or at --date")
+	mensaCmd.Flags().String("from", "", "First day to show (YYYY-MM-DD), together with --to")
+	mensaCmd.Flags().String("to", "", "Last day to show (YYYY-MM-DD)")
+	mensaCmd.Flags().String("ics", "", "Write the days as all-day calendar events to this file, or - for stdout")
+	mensaCmd.Flags().Int("width", 0, "Terminal width for the multi-day matrix (default: detected)")
 }
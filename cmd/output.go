@@ -0,0 +1,93 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/charmbracelet/huh/spinner"
+	"github.com/charmbracelet/x/term"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// Values of the global --output flag
+const (
+	outputText = "text"
+	outputJSON = "json"
+	outputYAML = "yaml"
+)
+
+// outputFormat returns the global --output flag. It is read from the root because export
+// keeps its own --output for the file path.
+func outputFormat(cmd *cobra.Command) string {
+	format, _ := cmd.Root().PersistentFlags().GetString("output")
+	return format
+}
+
+func validateOutputFormat(cmd *cobra.Command) error {
+	switch outputFormat(cmd) {
+	case outputText, outputJSON, outputYAML:
+		return nil
+	}
+	return fmt.Errorf("invalid --output %q, expected text, json or yaml", outputFormat(cmd))
+}
+
+// structured reports whether the command should print its data instead of the text view
+func structured(cmd *cobra.Command) bool {
+	return outputFormat(cmd) != outputText
+}
+
+// printStructured writes v to stdout in the --output format
+func printStructured(cmd *cobra.Command, v any) error {
+	return writeStructured(os.Stdout, outputFormat(cmd), v)
+}
+
+// writeStructured encodes v as indented JSON, or as YAML with the same field names and order,
+// so both formats share one schema: the json tags of the output types
+func writeStructured(w io.Writer, format string, v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	if format != outputYAML {
+		_, err = fmt.Fprintf(w, "%s\n", data)
+		return err
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return err
+	}
+	blockStyle(&doc)
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(&doc); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+// blockStyle drops the flow style and quoting the nodes keep from the JSON input. The encoder
+// quotes strings again where they would otherwise read as another type.
+func blockStyle(n *yaml.Node) {
+	n.Style = 0
+	for _, child := range n.Content {
+		blockStyle(child)
+	}
+}
+
+// runSpinner runs action behind a spinner. Without a terminal on stdout, e.g. when piped into
+// jq or a status bar, the action runs silently so no escape codes end up in the output.
+func runSpinner(ctx context.Context, title string, action func(ctx context.Context) error) error {
+	if !term.IsTerminal(os.Stdout.Fd()) {
+		return action(ctx)
+	}
+	return spinner.New().
+		Title(title).
+		Context(ctx).
+		ActionWithErr(action).
+		Run()
+}
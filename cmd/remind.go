@@ -0,0 +1,88 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"faliactl/pkg/clients"
+	"faliactl/pkg/commute"
+	"faliactl/pkg/config"
+	"faliactl/pkg/scraper"
+
+	"github.com/spf13/cobra"
+)
+
+var remindCmd = &cobra.Command{
+	Use:   "remind",
+	Short: "Run in the background and tell you when to leave for your first class",
+	Long: `Finds the first saved class of the day, plans the journey from your home station with
+live HAFAS data and re-plans it more often as the departure approaches, so delays are taken
+into account. --lead before the departure a reminder is sent to the configured notification
+sinks (see 'faliactl watch --help'). SIGINT and SIGTERM stop it cleanly.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := config.Load()
+		if err != nil {
+			return err
+		}
+		if cfg.HomeStationID == "" {
+			return fmt.Errorf("home station is not configured: run 'faliactl config' first")
+		}
+		if len(cfg.SavedGroupURLs) == 0 || len(cfg.SavedCourses) == 0 {
+			return fmt.Errorf("no saved courses configured: run 'Settings' -> 'Set Saved Courses' in the interactive menu first")
+		}
+
+		lead := time.Duration(cfg.CommuteLeadMinutes) * time.Minute
+		if cmd.Flags().Changed("lead") || lead <= 0 {
+			lead, _ = cmd.Flags().GetDuration("lead")
+		}
+
+		sinks := notifySinks(cmd, cfg)
+		if len(sinks) == 0 {
+			log.Printf("Warning: no notification sinks configured, reminders will only be logged")
+		}
+
+		statePath, err := commute.DefaultStatePath()
+		if err != nil {
+			return err
+		}
+
+		scraperClient := clients.Scraper()
+		r := &commute.Reminder{
+			Transit:       clients.Transit(),
+			HomeStationID: cfg.HomeStationID,
+			SavedCourses:  cfg.SavedCourses,
+			Classes: func() ([]scraper.Course, error) {
+				var all []scraper.Course
+				for _, url := range cfg.SavedGroupURLs {
+					courses, err := scraperClient.FetchScheduleContext(cmd.Context(), url)
+					if err != nil {
+						return nil, err
+					}
+					all = append(all, courses...)
+				}
+				return all, nil
+			},
+			Lead:      lead,
+			Sinks:     sinks,
+			StatePath: statePath,
+			Logf:      log.Printf,
+		}
+
+		ctx := cmd.Context()
+
+		log.Printf("Sending commute reminders %s before departure", lead)
+		if err := r.Run(ctx); err != nil {
+			return err
+		}
+		log.Printf("Shutting down")
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(remindCmd)
+
+	remindCmd.Flags().Duration("lead", commute.DefaultLead, "How long before the departure to send the reminder (default from config, then 15m)")
+	addNotifyFlags(remindCmd)
+}
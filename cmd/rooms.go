@@ -0,0 +1,181 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"faliactl/pkg/campus"
+	"faliactl/pkg/clients"
+	"faliactl/pkg/rooms"
+	"faliactl/pkg/scraper"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/spf13/cobra"
+)
+
+var roomsCmd = &cobra.Command{
+	Use:   "rooms",
+	Short: "Find rooms using the booked slots of every study group",
+}
+
+var roomsFreeCmd = &cobra.Command{
+	Use:   "free",
+	Short: "List rooms without a scheduled course in a time window",
+	Long: `Loads the schedule of every study group on the intranet (from the cache where possible),
+builds an index of which room is booked when and lists the rooms of a campus that have no
+course in the requested window.
+
+Only rooms that appear in some schedule are known, and bookings outside the timetable (exams,
+events, other users) are invisible, so a "free" room may still be taken.`,
+	Example: `  faliactl rooms free --campus wf-exer
+  faliactl rooms free --campus wf-exer --at "2026-10-20 10:00" --for 90m
+  faliactl rooms free --campus wolfenbuettel --at 14:00 --building 2`,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		campusName, _ := cmd.Flags().GetString("campus")
+		at, _ := cmd.Flags().GetString("at")
+		duration, _ := cmd.Flags().GetDuration("for")
+		building, _ := cmd.Flags().GetString("building")
+		concurrency, _ := cmd.Flags().GetInt("concurrency")
+		refresh, _ := cmd.Flags().GetBool("refresh")
+
+		if campusName != "" && !campus.Known(campusName) {
+			return fmt.Errorf("unknown campus %q", campusName)
+		}
+		if duration <= 0 {
+			return fmt.Errorf("--for must be positive")
+		}
+		from, err := parseAt(at, time.Now())
+		if err != nil {
+			return err
+		}
+		to := from.Add(duration)
+
+		client := clients.Scraper()
+		var groups []scraper.Group
+		var result *scraper.BulkResult
+		err = runSpinner(cmd.Context(), "Loading the schedules of all groups...", func(ctx context.Context) (err error) {
+			if groups, err = client.FetchGroupsContext(ctx); err != nil {
+				return err
+			}
+			result, err = client.FetchAll(ctx, groups, scraper.BulkOptions{Workers: concurrency, Refresh: refresh})
+			return err
+		})
+		if err != nil {
+			return fmt.Errorf("failed to load the schedules: %w", err)
+		}
+		var failed []string
+		for _, res := range result.Failed() {
+			if res.Status != scraper.CacheStale {
+				failed = append(failed, groupID(res.Group))
+			}
+		}
+		if len(failed) > 0 {
+			warn := lipgloss.NewStyle().Foreground(lipgloss.Color("214"))
+			fmt.Fprintln(os.Stderr, warn.Render(fmt.Sprintf("⚠ %d of %d groups could not be loaded; their bookings are missing: %s",
+				len(failed), len(groups), strings.Join(failed, ", "))))
+			if len(failed) == len(groups) {
+				return fmt.Errorf("no schedule could be loaded")
+			}
+		}
+
+		index := rooms.NewIndex(campus.Default(), result.Courses())
+		var free []rooms.FreeRoom
+		for _, r := range index.Free(campusName, from, to) {
+			if building == "" || strings.EqualFold(r.Room.Building, building) {
+				free = append(free, r)
+			}
+		}
+
+		if structured(cmd) {
+			out := freeRoomsOutput{From: from, To: to, KnownRooms: index.Len(), Rooms: []freeRoomOutput{}}
+			for _, r := range free {
+				out.Rooms = append(out.Rooms, freeRoomOutput{
+					Room:   r.Room.Code,
+					Campus: r.Campus.ID,
+					Since:  r.Since.In(scraper.Berlin),
+					Until:  r.Until.In(scraper.Berlin),
+				})
+			}
+			return printStructured(cmd, out)
+		}
+
+		fmt.Println(lipgloss.NewStyle().Bold(true).Render(fmt.Sprintf("Free rooms %s, %s–%s",
+			from.Format("Mon 02.01.2006"), from.Format("15:04"), to.Format("15:04"))))
+		if len(free) == 0 {
+			fmt.Printf("None of the %d known rooms is free.\n", index.Len())
+			return nil
+		}
+
+		tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "ROOM\tCAMPUS\tFREE")
+		for _, r := range free {
+			fmt.Fprintf(tw, "%s\t%s\t%s\n", r.Room.Code, r.Campus.ID, freeSpan(r))
+		}
+		if err := tw.Flush(); err != nil {
+			return err
+		}
+		fmt.Printf("\n%d of %d known rooms free, from %d groups.\n", len(free), index.Len(), len(groups)-len(failed))
+		return nil
+	},
+}
+
+// freeRoomsOutput is the schema of `rooms free --output json`
+type freeRoomsOutput struct {
+	From       time.Time        `json:"from"`
+	To         time.Time        `json:"to"`
+	KnownRooms int              `json:"known_rooms"`
+	Rooms      []freeRoomOutput `json:"rooms"`
+}
+
+type freeRoomOutput struct {
+	Room   string    `json:"room"`
+	Campus string    `json:"campus"`
+	Since  time.Time `json:"since,omitzero"` // End of the previous booking that day, missing if free all morning
+	Until  time.Time `json:"until,omitzero"` // Start of the next booking that day, missing if it stays free
+}
+
+// parseAt reads --at: "2026-10-20 10:00", a time today ("10:00") or "" for now
+func parseAt(at string, now time.Time) (time.Time, error) {
+	at = strings.TrimSpace(at)
+	if at == "" || at == "now" {
+		return now.In(scraper.Berlin).Truncate(time.Minute), nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02 15:04", at, scraper.Berlin); err == nil {
+		return t, nil
+	}
+	if t, err := time.ParseInLocation("15:04", at, scraper.Berlin); err == nil {
+		today := now.In(scraper.Berlin)
+		return time.Date(today.Year(), today.Month(), today.Day(), t.Hour(), t.Minute(), 0, 0, scraper.Berlin), nil
+	}
+	return time.Time{}, fmt.Errorf("invalid --at %q, expected e.g. \"2026-10-20 10:00\" or 10:00", at)
+}
+
+func freeSpan(r rooms.FreeRoom) string {
+	switch {
+	case r.Since.IsZero() && r.Until.IsZero():
+		return "all day"
+	case r.Since.IsZero():
+		return "until " + r.Until.In(scraper.Berlin).Format("15:04")
+	case r.Until.IsZero():
+		return "from " + r.Since.In(scraper.Berlin).Format("15:04")
+	default:
+		return r.Since.In(scraper.Berlin).Format("15:04") + "–" + r.Until.In(scraper.Berlin).Format("15:04")
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(roomsCmd)
+	roomsCmd.AddCommand(roomsFreeCmd)
+
+	roomsFreeCmd.Flags().String("campus", "", "Campus ID, alias or city, e.g. wf-exer or salzgitter (default: all)")
+	roomsFreeCmd.Flags().String("at", "", "Start of the window: \"2026-10-20 10:00\" or a time today (default: now)")
+	roomsFreeCmd.Flags().Duration("for", 90*time.Minute, "Length of the window")
+	roomsFreeCmd.Flags().String("building", "", "Only rooms in this building, e.g. 2")
+	roomsFreeCmd.Flags().Int("concurrency", 4, "Groups loaded in parallel")
+	roomsFreeCmd.Flags().Bool("refresh", false, "Revalidate every schedule with the intranet instead of using fresh cache entries")
+}
@@ -1,8 +1,17 @@
 package cmd
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
+	"os/signal"
+	"syscall"
+
+	"faliactl/pkg/campus"
+	"faliactl/pkg/clients"
+	"faliactl/pkg/fixture"
+	"faliactl/pkg/upstream"
 
 	"github.com/spf13/cobra"
 )
@@ -12,12 +21,89 @@ var rootCmd = &cobra.Command{
 	Short: "A CLI and TUI for Ostfalia timetables",
 	Long: `faliactl is an application for students at Ostfalia University 
 to easily scrape their course schedule and export it to an .ics file.`,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		// The flags are mirrored into the environment so every client constructor picks them up
+		record, _ := cmd.Flags().GetString("record")
+		replay, _ := cmd.Flags().GetString("replay")
+		if record != "" {
+			os.Setenv(fixture.EnvRecord, record)
+		}
+		if replay != "" {
+			os.Setenv(fixture.EnvReplay, replay)
+		}
+
+		// Room codes, stops and Mensa IDs resolve through the registry from here on
+		campus.SetDefault(clients.Campuses())
+
+		if err := validateOutputFormat(cmd); err != nil {
+			return err
+		}
+		_, err := fixture.TransportFromEnv()
+		return err
+	},
 }
 
 // Execute adds all child commands to the root command and sets flags appropriately.
+// Ctrl-C or SIGTERM cancel the context of the running command, so pending requests and
+// retries stop; a second Ctrl-C kills the process as usual.
 func Execute() {
-	if err := rootCmd.Execute(); err != nil {
-		fmt.Println(err)
-		os.Exit(1)
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+
+	if err := rootCmd.ExecuteContext(ctx); err != nil {
+		if !errors.Is(err, errFindings) {
+			fmt.Fprintln(os.Stderr, err)
+		}
+		code := exitCode(err)
+		if ctx.Err() != nil {
+			code = exitInterrupted // Spinners report the signal as their own error
+		}
+		os.Exit(code)
+	}
+}
+
+// Exit codes, so scripts and cron jobs can tell a missing group from an outage
+const (
+	exitError       = 1   // Any other failure, and the findings of --exit-code
+	exitNotFound    = 2   // The group, menu or stop does not exist upstream
+	exitUnavailable = 3   // An upstream is down or unreachable; retrying later may help
+	exitParse       = 4   // An upstream answered in an unexpected format, e.g. after a layout change
+	exitInterrupted = 130 // Cancelled with Ctrl-C, like a shell reports SIGINT
+)
+
+// errFindings is returned by commands with --exit-code when they found something. The
+// findings are already printed, so the error itself is not.
+var errFindings = errors.New("findings reported")
+
+// findings makes the command return errFindings without cobra printing it
+func findings(cmd *cobra.Command) error {
+	cmd.SilenceErrors = true
+	return errFindings
+}
+
+func exitCode(err error) int {
+	var parseErr *upstream.ParseError
+	switch {
+	case errors.Is(err, errFindings):
+		return exitError
+	case errors.Is(err, context.Canceled):
+		return exitInterrupted
+	case errors.As(err, &parseErr):
+		return exitParse
+	case errors.Is(err, upstream.ErrUpstreamUnavailable):
+		return exitUnavailable
+	case errors.Is(err, upstream.ErrNotFound):
+		return exitNotFound
 	}
+	return exitError
+}
+
+func init() {
+	rootCmd.PersistentFlags().String("output", outputText, "Print results as text, json or yaml (export keeps --output for its file, use --format there)")
+	rootCmd.PersistentFlags().String("record", "", "Record every upstream response into this directory (or set "+fixture.EnvRecord+")")
+	rootCmd.PersistentFlags().String("replay", "", "Serve upstream responses from a recorded directory instead of the network (or set "+fixture.EnvReplay+")")
 }
@@ -0,0 +1,209 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"faliactl/pkg/clients"
+	"faliactl/pkg/exporter"
+	"faliactl/pkg/scraper"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/term"
+	"github.com/spf13/cobra"
+)
+
+var scrapeCmd = &cobra.Command{
+	Use:   "scrape",
+	Short: "Fetch many study groups at once, e.g. to pre-warm the cache",
+	Long: `Fetches the schedules of the given groups, or of every group on the intranet with --all,
+using a small pool of workers with a global rate limit, a cap on parallel requests and retries
+with exponential backoff. Every schedule lands in the cache, so a nightly run keeps 'serve'
+and 'rooms free' fast; --out additionally writes one file per group.
+
+Groups that cannot be fetched are listed at the end and make the command exit non-zero, the
+other groups are still saved.`,
+	Example: `  faliactl scrape --all
+  faliactl scrape --all --out ./schedules --format ics
+  faliactl scrape --group 161902 --group 161903 --refresh`,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		all, _ := cmd.Flags().GetBool("all")
+		groupIDs, _ := cmd.Flags().GetStringSlice("group")
+		outDir, _ := cmd.Flags().GetString("out")
+		formatName, _ := cmd.Flags().GetString("format")
+		opts := scraper.BulkOptions{}
+		opts.Workers, _ = cmd.Flags().GetInt("workers")
+		opts.Rate, _ = cmd.Flags().GetDuration("rate")
+		opts.PerHost, _ = cmd.Flags().GetInt("per-host")
+		opts.Retries, _ = cmd.Flags().GetInt("retries")
+		opts.Refresh, _ = cmd.Flags().GetBool("refresh")
+
+		if all == (len(groupIDs) > 0) {
+			return errors.New("pass either --all or at least one --group")
+		}
+		format, ok := exporter.Lookup(formatName)
+		if !ok {
+			return fmt.Errorf("unknown format %q (available: %s)", formatName, strings.Join(exporter.Formats(), ", "))
+		}
+		if opts.Retries == 0 {
+			opts.Retries = -1 // --retries 0 means no retries, not the default
+		}
+
+		client := clients.Scraper()
+		var groups []scraper.Group
+		if all {
+			var err error
+			if groups, err = client.FetchGroupsContext(cmd.Context()); err != nil {
+				return fmt.Errorf("failed to fetch groups: %w", err)
+			}
+		} else {
+			for _, id := range groupIDs {
+				groups = append(groups, scraper.Group{Name: id, URL: scraper.GroupPath(strings.TrimSpace(id))})
+			}
+		}
+		if outDir != "" {
+			if err := os.MkdirAll(outDir, 0755); err != nil {
+				return err
+			}
+		}
+
+		opts.Progress = scrapeProgress(len(groups))
+		result, err := client.FetchAll(cmd.Context(), groups, opts)
+		if term.IsTerminal(os.Stderr.Fd()) {
+			fmt.Fprintln(os.Stderr)
+		}
+
+		counts := make(map[scraper.CacheStatus]int)
+		var failures []string
+		output := scrapeOutput{Results: []scrapeResultOutput{}}
+		for _, res := range result.Results {
+			row := scrapeResultOutput{Group: groupID(res.Group), Status: res.Status.String(), Courses: len(res.Courses), Attempts: res.Attempts}
+			if res.Err != nil {
+				failures = append(failures, fmt.Sprintf("%s: %v", groupID(res.Group), res.Err))
+				row.Error = res.Err.Error()
+			} else {
+				counts[res.Status]++
+			}
+			if outDir != "" && len(res.Courses) > 0 {
+				path := filepath.Join(outDir, groupID(res.Group)+"."+format.Name())
+				if werr := writeScraped(path, format, res.Courses); werr != nil {
+					failures = append(failures, fmt.Sprintf("%s: %v", path, werr))
+					row.FileError = werr.Error()
+				} else {
+					row.File = path
+				}
+			}
+			output.Results = append(output.Results, row)
+		}
+
+		if structured(cmd) {
+			output.Groups = len(groups)
+			output.Cached, output.Revalidated, output.Downloaded = counts[scraper.CacheHit], counts[scraper.CacheRevalidated], counts[scraper.CacheMiss]
+			output.Failed = len(result.Failed())
+			if perr := printStructured(cmd, output); perr != nil {
+				return perr
+			}
+		} else {
+			fmt.Printf("Scraped %d groups: %d from cache, %d revalidated, %d downloaded, %d failed\n",
+				len(groups), counts[scraper.CacheHit], counts[scraper.CacheRevalidated], counts[scraper.CacheMiss], len(result.Failed()))
+			if outDir != "" {
+				fmt.Printf("Wrote %s files to %s\n", format.Name(), outDir)
+			}
+		}
+		if err != nil {
+			return err
+		}
+		if len(failures) > 0 {
+			errStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("196"))
+			for _, f := range failures {
+				fmt.Fprintln(os.Stderr, errStyle.Render("✘ "+f))
+			}
+			return fmt.Errorf("%d problem(s) while scraping", len(failures))
+		}
+		return nil
+	},
+}
+
+// scrapeOutput is the schema of `scrape --output json`: the summary line plus one entry per group
+type scrapeOutput struct {
+	Groups      int                  `json:"groups"`
+	Cached      int                  `json:"cached"`
+	Revalidated int                  `json:"revalidated"`
+	Downloaded  int                  `json:"downloaded"`
+	Failed      int                  `json:"failed"`
+	Results     []scrapeResultOutput `json:"results"`
+}
+
+// scrapeResultOutput is one group of `scrape --output json`
+type scrapeResultOutput struct {
+	Group     string `json:"group"`
+	Status    string `json:"status"` // miss, hit, revalidated or stale, as in the progress line
+	Courses   int    `json:"courses"`
+	Attempts  int    `json:"attempts"`
+	Error     string `json:"error,omitempty"`
+	File      string `json:"file,omitempty"`       // Written with --out
+	FileError string `json:"file_error,omitempty"` // Writing to --out failed
+}
+
+// scrapeProgress redraws a single status line on a terminal and stays quiet otherwise
+func scrapeProgress(total int) func(scraper.Progress) {
+	if !term.IsTerminal(os.Stderr.Fd()) {
+		return nil
+	}
+	return func(p scraper.Progress) {
+		line := fmt.Sprintf("[%d/%d] %s (%s)", p.Done, total, groupID(p.Result.Group), p.Result.Status)
+		if p.Failed > 0 {
+			line += lipgloss.NewStyle().Foreground(lipgloss.Color("214")).Render(fmt.Sprintf(", %d failed", p.Failed))
+		}
+		fmt.Fprintf(os.Stderr, "\r\033[K%s", line)
+	}
+}
+
+func groupID(g scraper.Group) string {
+	return strings.TrimSuffix(filepath.Base(g.URL), ".html")
+}
+
+// writeScraped exports the courses and replaces path atomically
+func writeScraped(path string, format exporter.Exporter, courses []scraper.Course) error {
+	var buf bytes.Buffer
+	if err := format.Export(courses, &buf, exporter.Options{}); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".scrape-*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(buf.Bytes()); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+func init() {
+	rootCmd.AddCommand(scrapeCmd)
+
+	scrapeCmd.Flags().Bool("all", false, "Scrape every group listed on the intranet")
+	scrapeCmd.Flags().StringSliceP("group", "g", nil, "Group ID(s) to scrape instead of --all")
+	scrapeCmd.Flags().StringP("out", "o", "", "Also write one file per group into this directory")
+	scrapeCmd.Flags().StringP("format", "F", "json", "Format of the files written to --out: "+strings.Join(exporter.Formats(), ", "))
+	scrapeCmd.Flags().Int("workers", scraper.DefaultBulkWorkers, "Groups processed in parallel")
+	scrapeCmd.Flags().Duration("rate", scraper.DefaultBulkRate, "Minimum interval between two requests to the intranet (negative disables)")
+	scrapeCmd.Flags().Int("per-host", scraper.DefaultBulkPerHost, "Maximum parallel requests to the intranet")
+	scrapeCmd.Flags().Int("retries", scraper.DefaultBulkRetries, "Retries per group, with exponential backoff")
+	scrapeCmd.Flags().Bool("refresh", false, "Revalidate every group, even when its cache entry is fresh")
+}
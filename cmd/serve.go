@@ -1,135 +1,86 @@
 package cmd
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
-	"strings"
+	"time"
 
-	"faliactl/pkg/exporter"
-	"faliactl/pkg/scraper"
+	"faliactl/pkg/clients"
+	"faliactl/pkg/server"
+	"faliactl/pkg/sets"
 
 	"github.com/spf13/cobra"
 )
 
-var setsFilePath string
+// shutdownTimeout is how long running requests may take once the server is asked to stop
+const shutdownTimeout = 10 * time.Second
 
 var serveCmd = &cobra.Command{
 	Use:   "serve",
-	Short: "Start an HTTP server to serve dynamic ICS calendars",
-	Long:  `Starts a web server. You can subscribe to dynamic calendars via URL, e.g., http://localhost:8080/161902.ics`,
-	RunE: func(cmd *cobra.Command, args []string) error {
-		port, _ := cmd.Flags().GetString("port")
-		setsFilePath, _ = cmd.Flags().GetString("sets")
-
-		http.HandleFunc("/", handleCalendarRequest)
-
-		fmt.Printf("Starting server on port %s...\n", port)
-		fmt.Printf("Using sets file: %s (if exists)\n", setsFilePath)
-		fmt.Printf("Subscribe to calendars at http://localhost:%s/<group_or_set>.ics\n", port)
-		return http.ListenAndServe(":"+port, nil)
-	},
-}
-
-func handleCalendarRequest(w http.ResponseWriter, r *http.Request) {
-	if r.URL.Path == "/health" {
-		w.WriteHeader(http.StatusOK)
-		w.Write([]byte("ok"))
-		return
-	}
-
-	// Extract identifier from path
-	path := strings.TrimPrefix(r.URL.Path, "/")
-	if path == "" || path == "favicon.ico" {
-		http.NotFound(w, r)
-		return
-	}
+	Short: "Start an HTTP server to serve dynamic calendars",
+	Long: `Starts a web server. You can subscribe to dynamic calendars via URL, e.g., http://localhost:8080/161902.ics
 
-	identifier := strings.TrimSuffix(path, ".ics")
-	log.Printf("Received request for identifier %s from %s", identifier, r.RemoteAddr)
+Other formats are selected by the extension: /161902.json, .csv, .md, .jcal, or .html for a
+week view in the browser.
 
-	client := scraper.NewClient()
-	var allCourses []scraper.Course
-
-	// Check if identifier matches a subscription set
-	sets, err := loadSetsConfig(setsFilePath)
-	if err != nil {
-		log.Printf("Warning: failed to load sets config: %v", err)
-	}
+Calendars are compiled on first request, kept in memory and rebuilt in the background every
+--refresh interval. Responses carry ETag/Last-Modified headers, so polling clients get a
+304 Not Modified and never reach the intranet directly.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		port, _ := cmd.Flags().GetString("port")
+		setsFilePath, _ := cmd.Flags().GetString("sets")
+		refresh, _ := cmd.Flags().GetDuration("refresh")
 
-	if set, ok := sets[identifier]; ok {
-		// It's a set
-		seenEvent := make(map[string]bool)
-		for _, group := range set.Groups {
-			urlPath := group
-			if !strings.HasSuffix(urlPath, ".html") {
-				urlPath += ".html"
-			}
-			groupCourses, fetchErr := client.FetchSchedule(urlPath)
-			if fetchErr != nil {
-				log.Printf("Error fetching schedule for group %s in set %s: %v", group, identifier, fetchErr)
-				continue
-			}
-			for _, c := range groupCourses {
-				key := fmt.Sprintf("%s|%s|%s|%s", c.Name, c.DateStr, c.StartTime, c.EndTime)
-				if !seenEvent[key] {
-					seenEvent[key] = true
-					allCourses = append(allCourses, c)
-				}
-			}
+		store, err := sets.NewStore(setsFilePath)
+		if err != nil {
+			return fmt.Errorf("failed to load sets file %s: %w", setsFilePath, err)
 		}
 
-		// Filter courses if specific ones are defined
-		if len(set.Courses) > 0 {
-			courseMap := make(map[string]bool)
-			for _, name := range set.Courses {
-				courseMap[name] = true
-			}
-
-			var filtered []scraper.Course
-			for _, c := range allCourses {
-				if courseMap[c.Name] {
-					filtered = append(filtered, c)
-				}
-			}
-			allCourses = filtered
-		}
-	} else {
-		// Fallback: Treat as a single group URL
-		urlPath := identifier
-		if !strings.HasSuffix(urlPath, ".html") {
-			urlPath += ".html"
+		srv := &server.Server{
+			Client:          clients.Scraper(),
+			Sets:            store,
+			CancelGrace:     cancelGrace(cmd),
+			RefreshInterval: refresh,
+			Logf:            log.Printf,
 		}
-		var fetchErr error
-		allCourses, fetchErr = client.FetchSchedule(urlPath)
-		if fetchErr != nil {
-			log.Printf("Error fetching schedule for %s: %v\n", identifier, fetchErr)
-			http.Error(w, "Failed to fetch schedule", http.StatusInternalServerError)
-			return
+
+		// Edits to the sets file take effect without a restart; an invalid edit keeps the old sets
+		store.Logf = log.Printf
+		store.OnChange = func(changed []string) {
+			srv.Invalidate(changed...)
 		}
-	}
+		ctx := cmd.Context()
+		go store.Watch(ctx, sets.DefaultReloadInterval)
 
-	if len(allCourses) == 0 {
-		http.NotFound(w, r)
-		return
-	}
+		go srv.RunRefresher(ctx)
 
-	// Set headers for ICS file download / subscription
-	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
-	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s.ics\"", identifier))
-	// Encourage caching clients (like Google Calendar) not to over-poll (12 hours)
-	w.Header().Set("Cache-Control", "public, max-age=43200")
+		// Ctrl-C or SIGTERM stop accepting connections and let running requests finish
+		httpServer := &http.Server{Addr: ":" + port, Handler: srv}
+		go func() {
+			<-ctx.Done()
+			shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+			defer cancel()
+			httpServer.Shutdown(shutdownCtx)
+		}()
 
-	err = exporter.GenerateICS(allCourses, w)
-	if err != nil {
-		log.Printf("Error generating ICS for %s: %v\n", identifier, err)
-	} else {
-		log.Printf("Successfully served calendar for %s\n", identifier)
-	}
+		fmt.Printf("Starting server on port %s...\n", port)
+		fmt.Printf("Using sets file: %s (%d sets, reloaded on change)\n", setsFilePath, len(store.Current().Sets))
+		fmt.Printf("Subscribe to calendars at http://localhost:%s/<group_or_set>.ics\n", port)
+		if err := httpServer.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+			return err
+		}
+		log.Printf("Shutting down")
+		return nil
+	},
 }
 
 func init() {
 	rootCmd.AddCommand(serveCmd)
 	serveCmd.Flags().StringP("port", "p", "8080", "Port to listen on")
 	serveCmd.Flags().StringP("sets", "s", "sets.json", "Path to sets configuration file")
+	serveCmd.Flags().Duration("refresh", server.DefaultRefreshInterval, "How often cached calendars are rebuilt in the background")
+	serveCmd.Flags().Int("cancel-grace-days", 0, "Days a removed lecture stays in served calendars as cancelled (default from config, then 14; negative disables)")
 }
@@ -1,29 +1,319 @@
 package cmd
 
 import (
-	"encoding/json"
+	"context"
+	"fmt"
 	"os"
+	"strings"
+	"text/tabwriter"
+
+	"faliactl/pkg/clients"
+	"faliactl/pkg/scraper"
+	"faliactl/pkg/sets"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/spf13/cobra"
 )
 
-type SubscriptionSet struct {
-	Groups  []string `json:"groups"`
-	Courses []string `json:"courses"`
+var setsCmd = &cobra.Command{
+	Use:   "sets",
+	Short: "Manage the subscription sets served by 'faliactl serve'",
+	Long: `A set combines several study groups into one calendar, optionally restricted to some
+course names. Sets live in a JSON file (sets.json by default) that 'faliactl serve' reloads
+automatically when it changes.`,
+}
+
+var setsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List all sets",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		f, err := loadSetsFile(cmd)
+		if err != nil {
+			return err
+		}
+		if structured(cmd) {
+			out := []setOutput{}
+			for _, name := range f.Names() {
+				out = append(out, setOutput{Name: name, Set: f.Sets[name]})
+			}
+			return printStructured(cmd, out)
+		}
+		if len(f.Sets) == 0 {
+			fmt.Println("No sets defined.")
+			return nil
+		}
+
+		tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "NAME\tGROUPS\tCOURSES")
+		for _, name := range f.Names() {
+			set := f.Sets[name]
+			courses := "all"
+			if len(set.Courses) > 0 {
+				courses = fmt.Sprintf("%d", len(set.Courses))
+			}
+			fmt.Fprintf(tw, "%s\t%s\t%s\n", name, strings.Join(set.Groups, ", "), courses)
+		}
+		return tw.Flush()
+	},
+}
+
+var setsShowCmd = &cobra.Command{
+	Use:          "show <name>",
+	Short:        "Show the groups and courses of a set",
+	Args:         cobra.ExactArgs(1),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		f, err := loadSetsFile(cmd)
+		if err != nil {
+			return err
+		}
+		set, ok := f.Lookup(args[0])
+		if !ok {
+			return unknownSetError(f, args[0])
+		}
+		if structured(cmd) {
+			return printStructured(cmd, setOutput{Name: args[0], Set: set})
+		}
+
+		fmt.Printf("Set:     %s\n", args[0])
+		fmt.Printf("Groups:  %s\n", strings.Join(set.Groups, ", "))
+		if len(set.Courses) == 0 {
+			fmt.Println("Courses: all")
+			return nil
+		}
+		fmt.Println("Courses:")
+		for _, c := range set.Courses {
+			fmt.Printf("  - %s\n", c)
+		}
+		return nil
+	},
+}
+
+// setOutput is the schema of `sets list` and `sets show` with --output json: the name next to
+// the set as it is stored in the sets file
+type setOutput struct {
+	Name string `json:"name"`
+	sets.Set
+}
+
+var setsAddCmd = &cobra.Command{
+	Use:          "add <name>",
+	Short:        "Create a set or add groups and courses to an existing one",
+	Args:         cobra.ExactArgs(1),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		groups, _ := cmd.Flags().GetStringSlice("group")
+		courses, _ := cmd.Flags().GetStringArray("course")
+		force, _ := cmd.Flags().GetBool("force")
+		offline, _ := cmd.Flags().GetBool("offline")
+
+		f, err := loadSetsFile(cmd)
+		if err != nil {
+			return err
+		}
+
+		name := args[0]
+		set := f.Sets[name]
+		set.Groups = appendMissing(set.Groups, groups...)
+		set.Courses = appendMissing(set.Courses, courses...)
+		f.Sets[name] = set
+
+		if problems := f.Validate(); len(problems) > 0 {
+			return fmt.Errorf("invalid set: %s", strings.Join(problems, "; "))
+		}
+
+		if !offline {
+			single := &sets.File{Sets: map[string]sets.Set{name: set}}
+			issues, err := checkSetsOnline(cmd.Context(), single)
+			if err != nil {
+				return err
+			}
+			if len(issues) > 0 {
+				printIssues(issues)
+				if !force {
+					return fmt.Errorf("not saving %q; fix the problems above or pass --force", name)
+				}
+			}
+		}
+
+		if err := f.Save(setsFilePath(cmd)); err != nil {
+			return err
+		}
+		fmt.Printf("Saved set %q (%d groups, %d courses) to %s\n", name, len(set.Groups), len(set.Courses), setsFilePath(cmd))
+		return nil
+	},
+}
+
+var setsRemoveCmd = &cobra.Command{
+	Use:          "remove <name>",
+	Short:        "Remove a set, or only some of its groups and courses",
+	Args:         cobra.ExactArgs(1),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		groups, _ := cmd.Flags().GetStringSlice("group")
+		courses, _ := cmd.Flags().GetStringArray("course")
+
+		f, err := loadSetsFile(cmd)
+		if err != nil {
+			return err
+		}
+
+		name := args[0]
+		set, ok := f.Lookup(name)
+		if !ok {
+			return unknownSetError(f, name)
+		}
+
+		if len(groups) == 0 && len(courses) == 0 {
+			delete(f.Sets, name)
+			fmt.Printf("Removed set %q\n", name)
+		} else {
+			set.Groups = removeAll(set.Groups, groups...)
+			set.Courses = removeAll(set.Courses, courses...)
+			if len(set.Groups) == 0 {
+				return fmt.Errorf("set %q would have no groups left; remove the whole set instead", name)
+			}
+			f.Sets[name] = set
+			fmt.Printf("Updated set %q (%d groups, %d courses)\n", name, len(set.Groups), len(set.Courses))
+		}
+
+		return f.Save(setsFilePath(cmd))
+	},
+}
+
+var setsValidateCmd = &cobra.Command{
+	Use:   "validate",
+	Short: "Check the sets file against its schema and the intranet",
+	Long: `Checks the structure of the sets file, then verifies that every group exists and every
+course name occurs in at least one of the set's groups, suggesting close matches for typos.
+Exits non-zero when problems are found.`,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		offline, _ := cmd.Flags().GetBool("offline")
+
+		f, err := loadSetsFile(cmd)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("%s: structure OK (%d sets)\n", setsFilePath(cmd), len(f.Sets))
+		if offline || len(f.Sets) == 0 {
+			return nil
+		}
+
+		issues, err := checkSetsOnline(cmd.Context(), f)
+		if err != nil {
+			return err
+		}
+		if len(issues) > 0 {
+			printIssues(issues)
+			return fmt.Errorf("%d problem(s) found", len(issues))
+		}
+		fmt.Println("All groups and courses exist.")
+		return nil
+	},
+}
+
+var setsSchemaCmd = &cobra.Command{
+	Use:   "schema",
+	Short: "Print the JSON Schema of the sets file",
+	Run: func(cmd *cobra.Command, args []string) {
+		os.Stdout.Write(sets.Schema)
+	},
+}
+
+func setsFilePath(cmd *cobra.Command) string {
+	path, _ := cmd.Flags().GetString("file")
+	return path
+}
+
+func loadSetsFile(cmd *cobra.Command) (*sets.File, error) {
+	f, err := sets.Load(setsFilePath(cmd))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", setsFilePath(cmd), err)
+	}
+	return f, nil
+}
+
+func unknownSetError(f *sets.File, name string) error {
+	if suggestions := sets.Suggest(name, f.Names(), 3); len(suggestions) > 0 {
+		return fmt.Errorf("no set named %q (did you mean %s?)", name, strings.Join(suggestions, ", "))
+	}
+	return fmt.Errorf("no set named %q", name)
 }
 
-type SetsConfig map[string]SubscriptionSet
+// checkSetsOnline fetches the group list and the schedules of the referenced groups
+func checkSetsOnline(ctx context.Context, f *sets.File) ([]sets.Issue, error) {
+	client := clients.Scraper()
+	var groups []scraper.Group
+	var issues []sets.Issue
+	var err error
+
+	err = runSpinner(ctx, "Checking groups and courses against the intranet...", func(ctx context.Context) (err error) {
+		if groups, err = client.FetchGroupsContext(ctx); err != nil {
+			return err
+		}
+		issues = sets.Check(f, groups, func(groupPath string) ([]scraper.Course, error) {
+			return client.FetchScheduleContext(ctx, groupPath)
+		})
+		return nil
+	})
 
-func loadSetsConfig(path string) (SetsConfig, error) {
-	data, err := os.ReadFile(path)
 	if err != nil {
-		if os.IsNotExist(err) {
-			return make(SetsConfig), nil
+		return nil, fmt.Errorf("failed to fetch groups: %w", err)
+	}
+	return issues, nil
+}
+
+func printIssues(issues []sets.Issue) {
+	errStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("196"))
+	for _, issue := range issues {
+		fmt.Println(errStyle.Render("✘ " + issue.String()))
+	}
+}
+
+func appendMissing(list []string, values ...string) []string {
+	for _, v := range values {
+		found := false
+		for _, existing := range list {
+			if existing == v {
+				found = true
+				break
+			}
+		}
+		if !found {
+			list = append(list, v)
 		}
-		return nil, err
 	}
+	return list
+}
 
-	var sets SetsConfig
-	if err := json.Unmarshal(data, &sets); err != nil {
-		return nil, err
+func removeAll(list []string, values ...string) []string {
+	drop := make(map[string]bool)
+	for _, v := range values {
+		drop[v] = true
+	}
+	var out []string
+	for _, v := range list {
+		if !drop[v] {
+			out = append(out, v)
+		}
 	}
-	return sets, nil
+	return out
+}
+
+func init() {
+	rootCmd.AddCommand(setsCmd)
+	setsCmd.AddCommand(setsListCmd, setsShowCmd, setsAddCmd, setsRemoveCmd, setsValidateCmd, setsSchemaCmd)
+
+	setsCmd.PersistentFlags().StringP("file", "f", "sets.json", "Path to the sets file")
+
+	setsAddCmd.Flags().StringSliceP("group", "g", nil, "Group ID(s) to add (e.g. 161902)")
+	setsAddCmd.Flags().StringArrayP("course", "c", nil, "Course name to add (repeatable; names may contain commas)")
+	setsAddCmd.Flags().Bool("force", false, "Save even if groups or courses cannot be found")
+	setsAddCmd.Flags().Bool("offline", false, "Skip checking groups and courses against the intranet")
+
+	setsRemoveCmd.Flags().StringSliceP("group", "g", nil, "Only remove these group(s) from the set")
+	setsRemoveCmd.Flags().StringArrayP("course", "c", nil, "Only remove this course from the set (repeatable)")
+
+	setsValidateCmd.Flags().Bool("offline", false, "Only check the structure, don't contact the intranet")
 }
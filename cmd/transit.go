@@ -1,31 +1,24 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"strings"
 	"time"
 
+	"faliactl/pkg/campus"
+	"faliactl/pkg/clients"
 	"faliactl/pkg/config"
 	"faliactl/pkg/transit"
 
 	ics "github.com/arran4/golang-ical"
-	"github.com/charmbracelet/huh/spinner"
+	"github.com/charmbracelet/lipgloss"
 	"github.com/spf13/cobra"
 	"golang.org/x/text/cases"
 	"golang.org/x/text/language"
 )
 
-// Map standard Ostfalia campus names to their HAFAS Station IDs
-var transitCampusMap = map[string]string{
-	"wf-haupt":      "891097",    // Wolfenbüttel Hauptcampus
-	"wf-exer":       "891011",    // Wolfenbüttel Am Exer
-	"wolfenbuettel": "891097",    // Default WF to Hauptcampus
-	"salzgitter":    "991604089", // Salzgitter, Ostfalia Hochschule
-	"suderburg":     "991604106", // Suderburg, Ostfalia Hochschule
-	"braunschweig":  "8000049",   // Braunschweig Hbf (Generic proxy for now)
-}
-
 var transitCmd = &cobra.Command{
 	Use:   "transit",
 	Short: "View live bus and train departures for Ostfalia campuses",
@@ -40,42 +33,63 @@ var transitCmd = &cobra.Command{
 		}
 
 		campuses := strings.Split(campusFlag, ",")
-		client := transit.NewClient()
+		client := clients.Transit()
 		var firstErr error
 		processedAny := false
+		results := []any{} // One entry per campus for --output
 
 		for _, campusName := range campuses {
 			campusName = strings.TrimSpace(strings.ToLower(campusName))
-			stationID, ok := transitCampusMap[campusName]
-			if !ok {
+			site, ok := campus.Default().Lookup(campusName)
+			stationID := site.StationID
+			if !ok || stationID == "" {
 				fmt.Fprintf(os.Stderr, "Warning: unknown campus %q, skipping\n", campusName)
 				continue
 			}
 			processedAny = true
 
 			if exportWeek {
-				if err := exportTransitICS(client, campusName, stationID); err != nil {
+				if err := exportTransitICS(cmd.Context(), client, campusName, stationID); err != nil {
 					fmt.Fprintf(os.Stderr, "Failed to export transit ICS for %s: %v\n", campusName, err)
 					if firstErr == nil {
 						firstErr = err
 					}
 				}
 			} else if routeHome {
-				if err := printRouteHome(client, campusName, stationID); err != nil {
+				home, err := fetchRouteHome(cmd.Context(), client, campusName, stationID)
+				if err != nil {
 					fmt.Fprintf(os.Stderr, "Failed to find route home from %s: %v\n", campusName, err)
 					if firstErr == nil {
 						firstErr = err
 					}
+				} else if structured(cmd) {
+					results = append(results, home)
+				} else {
+					printRouteHome(home)
 				}
 			} else {
-				if err := printDepartures(client, campusName, stationID); err != nil {
+				deps, err := fetchDepartures(cmd.Context(), client, campusName, stationID)
+				if err != nil {
 					fmt.Fprintf(os.Stderr, "Failed to fetch departures for %s: %v\n", campusName, err)
 					if firstErr == nil {
 						firstErr = err
 					}
+				} else if structured(cmd) {
+					results = append(results, deps)
+				} else {
+					printDepartures(deps)
 				}
 			}
-			fmt.Println()
+			if !structured(cmd) {
+				fmt.Println()
+			}
+		}
+
+		// The campuses that worked are printed even if another one failed
+		if structured(cmd) && !exportWeek && processedAny && (len(results) > 0 || firstErr == nil) {
+			if err := printStructured(cmd, results); err != nil {
+				return err
+			}
 		}
 
 		if firstErr != nil {
@@ -88,37 +102,59 @@ var transitCmd = &cobra.Command{
 	},
 }
 
-func printDepartures(client *transit.Client, campusName string, stationID string) error {
+// departuresOutput is one campus of `transit --output json`
+type departuresOutput struct {
+	Campus    string                    `json:"campus"`
+	StationID string                    `json:"station_id"`
+	Routes    []transit.SummarizedRoute `json:"routes"` // At most two departures per line and direction
+}
+
+// routeHomeOutput is one campus of `transit --home --output json`. The journeys are in the
+// order HAFAS returns them; the text view only shows the first.
+type routeHomeOutput struct {
+	Campus    string            `json:"campus"`
+	StationID string            `json:"station_id"`
+	To        string            `json:"to"`
+	Journeys  []transit.Journey `json:"journeys"`
+}
+
+func fetchDepartures(ctx context.Context, client *transit.Client, campusName string, stationID string) (departuresOutput, error) {
 	var deps []transit.Departure
-	var err error
 
-	_ = spinner.New().
-		Title(fmt.Sprintf("Fetching live departures for %s...", campusName)).
-		Action(func() {
-			deps, err = client.FetchDepartures(stationID, 60)
-		}).
-		Run()
+	err := runSpinner(ctx, fmt.Sprintf("Fetching live departures for %s...", campusName), func(ctx context.Context) (err error) {
+		deps, err = client.FetchDeparturesContext(ctx, stationID, 60)
+		return err
+	})
 
 	if err != nil {
-		return err
+		return departuresOutput{}, err
 	}
 
-	fmt.Printf("\n--- 🚌 Next Departures: Ostfalia Campus %s ---\n", cases.Title(language.German).String(campusName))
+	return departuresOutput{
+		Campus:    campusName,
+		StationID: stationID,
+		Routes:    transit.SummarizeDepartures(deps, 2),
+	}, nil
+}
+
+func printDepartures(out departuresOutput) {
+	lineStyle := lipgloss.NewStyle().Bold(true)
+	delayStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("196"))
 
-	if len(deps) == 0 {
+	fmt.Printf("\n--- 🚌 Next Departures: Ostfalia Campus %s ---\n", cases.Title(language.German).String(out.Campus))
+
+	if len(out.Routes) == 0 {
 		fmt.Println("No upcoming departures found in the next 60 minutes.")
-		return nil
+		return
 	}
 
-	summary := transit.SummarizeDepartures(deps, 2)
-
-	for _, route := range summary {
-		fmt.Printf("\n🚐 \033[1m%s\033[0m -> %s\n", route.LineName, route.Direction)
+	for _, route := range out.Routes {
+		fmt.Printf("\n🚐 %s -> %s\n", lineStyle.Render(route.LineName), route.Direction)
 
 		for _, d := range route.Departures {
 			delayStr := ""
 			if d.Delay != nil && *d.Delay > 0 {
-				delayStr = fmt.Sprintf("\033[31m (+%d min delay)\033[0m", *d.Delay/60)
+				delayStr = delayStyle.Render(fmt.Sprintf(" (+%d min delay)", *d.Delay/60))
 			}
 			fmt.Printf("  • [%s]%s\n",
 				d.When.Local().Format("15:04"),
@@ -126,38 +162,43 @@ func printDepartures(client *transit.Client, campusName string, stationID string
 			)
 		}
 	}
-	return nil
 }
 
-func printRouteHome(client *transit.Client, campusName string, fromStationID string) error {
+func fetchRouteHome(ctx context.Context, client *transit.Client, campusName string, fromStationID string) (routeHomeOutput, error) {
 	cfg, err := config.Load()
 	if err != nil || cfg.HomeStationID == "" {
-		return fmt.Errorf("home address is not configured. Please run 'faliactl config --set-home \"Your Address\"' first")
+		return routeHomeOutput{}, fmt.Errorf("home address is not configured. Please run 'faliactl config --set-home \"Your Address\"' first")
 	}
 
 	var journeys []transit.Journey
-	var fetchErr error
 
-	_ = spinner.New().
-		Title(fmt.Sprintf("Routing trip from %s to %s...", campusName, cfg.HomeAddress)).
-		Action(func() {
-			journeys, fetchErr = client.FetchJourneys(fromStationID, cfg.HomeStationID)
-		}).
-		Run()
+	err = runSpinner(ctx, fmt.Sprintf("Routing trip from %s to %s...", campusName, cfg.HomeAddress), func(ctx context.Context) (err error) {
+		journeys, err = client.FetchJourneysContext(ctx, fromStationID, cfg.HomeStationID)
+		return err
+	})
 
-	if fetchErr != nil {
-		return fetchErr
+	if err != nil {
+		return routeHomeOutput{}, err
 	}
 
-	fmt.Printf("\n--- 🧭 Route Home %s -> %s ---\n", cases.Title(language.German).String(campusName), cfg.HomeAddress)
+	return routeHomeOutput{
+		Campus:    campusName,
+		StationID: fromStationID,
+		To:        cfg.HomeAddress,
+		Journeys:  journeys,
+	}, nil
+}
 
-	if len(journeys) == 0 {
+func printRouteHome(out routeHomeOutput) {
+	fmt.Printf("\n--- 🧭 Route Home %s -> %s ---\n", cases.Title(language.German).String(out.Campus), out.To)
+
+	if len(out.Journeys) == 0 {
 		fmt.Println("No routes could be found. It might be too late at night.")
-		return nil
+		return
 	}
 
 	// Just print the fastest/closest journey for now
-	firstJourney := journeys[0]
+	firstJourney := out.Journeys[0]
 
 	for i, leg := range firstJourney.Legs {
 		lineName := "Walk🚶"
@@ -172,11 +213,9 @@ func printRouteHome(client *transit.Client, campusName string, fromStationID str
 			leg.Destination.Name,
 			leg.Arrival.Local().Format("15:04"))
 	}
-
-	return nil
 }
 
-func exportTransitICS(client *transit.Client, campusName string, fromStationID string) error {
+func exportTransitICS(ctx context.Context, client *transit.Client, campusName string, fromStationID string) error {
 	cfg, err := config.Load()
 	if err != nil || cfg.HomeStationID == "" {
 		return fmt.Errorf("home address is not configured. Please run 'faliactl config --set-home \"Your Address\"' first")
@@ -193,12 +232,10 @@ func exportTransitICS(client *transit.Client, campusName string, fromStationID s
 	var journeys []transit.Journey
 	var fetchErr error
 
-	_ = spinner.New().
-		Title(fmt.Sprintf("Exporting commute template from %s to %s...", campusName, cfg.HomeAddress)).
-		Action(func() {
-			journeys, fetchErr = client.FetchJourneys(fromStationID, cfg.HomeStationID)
-		}).
-		Run()
+	fetchErr = runSpinner(ctx, fmt.Sprintf("Exporting commute template from %s to %s...", campusName, cfg.HomeAddress), func(ctx context.Context) (err error) {
+		journeys, err = client.FetchJourneysContext(ctx, fromStationID, cfg.HomeStationID)
+		return err
+	})
 
 	if fetchErr != nil {
 		return fetchErr
@@ -257,7 +294,7 @@ func exportTransitICS(client *transit.Client, campusName string, fromStationID s
 
 func init() {
 	rootCmd.AddCommand(transitCmd)
-	transitCmd.Flags().StringP("campus", "c", "", "Ostfalia campus (salzgitter, wolfenbuettel, suderburg)")
+	transitCmd.Flags().StringP("campus", "c", "", "Ostfalia campus (wf-haupt, wf-exer, salzgitter, suderburg, braunschweig)")
 	transitCmd.Flags().BoolP("home", "r", false, "Route directly from the campus to your saved home address")
 	transitCmd.Flags().BoolP("export-week", "e", false, "Export a 7-day commute template to an .ics calendar file")
 }
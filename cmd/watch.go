@@ -0,0 +1,114 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+
+	"faliactl/pkg/clients"
+	"faliactl/pkg/config"
+	"faliactl/pkg/notify"
+	"faliactl/pkg/scraper"
+	"faliactl/pkg/watch"
+
+	"github.com/spf13/cobra"
+)
+
+var watchCmd = &cobra.Command{
+	Use:   "watch",
+	Short: "Continuously watch your saved groups and notify you about schedule changes",
+	Long: `Re-scrapes the saved study groups (or the ones given with --group) every --interval and
+reports added, removed, moved and renamed lectures to the notification sinks configured in
+~/.faliactl.json ("notify": hook, webhook, desktop, smtp) or passed as flags.
+
+The last reported schedule is stored in ~/.faliactl_cache/watch, so restarting the daemon
+never repeats a notification. SIGINT and SIGTERM stop it cleanly.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		groups, _ := cmd.Flags().GetStringSlice("group")
+		interval, _ := cmd.Flags().GetDuration("interval")
+		once, _ := cmd.Flags().GetBool("once")
+
+		cfg, err := config.Load()
+		if err != nil {
+			return err
+		}
+
+		if len(groups) == 0 {
+			groups = cfg.SavedGroupURLs
+		}
+		if len(groups) == 0 {
+			return fmt.Errorf("no groups to watch: pass --group or save groups via the interactive exporter")
+		}
+		for i, group := range groups {
+			groups[i] = scraper.GroupPath(group)
+		}
+
+		sinks := notifySinks(cmd, cfg)
+		if len(sinks) == 0 {
+			log.Printf("Warning: no notification sinks configured, changes will only be logged")
+		}
+
+		statePath, err := watch.DefaultStatePath()
+		if err != nil {
+			return err
+		}
+
+		w := &watch.Watcher{
+			Client:    clients.Scraper(),
+			Groups:    groups,
+			Sinks:     sinks,
+			Interval:  interval,
+			StatePath: statePath,
+			Logf:      log.Printf,
+		}
+
+		ctx := cmd.Context()
+
+		if once {
+			return w.Check(ctx)
+		}
+
+		log.Printf("Watching %d group(s) every %s", len(groups), interval)
+		if err := w.Run(ctx); err != nil {
+			return err
+		}
+		log.Printf("Shutting down")
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(watchCmd)
+
+	watchCmd.Flags().StringSliceP("group", "g", nil, "Group ID(s) to watch (defaults to your saved groups)")
+	watchCmd.Flags().Duration("interval", watch.DefaultInterval, "How often to re-scrape the groups")
+	watchCmd.Flags().Bool("once", false, "Check once and exit, e.g. when driven by cron or a systemd timer")
+	addNotifyFlags(watchCmd)
+}
+
+// addNotifyFlags registers the sink flags shared by the long-running commands
+func addNotifyFlags(cmd *cobra.Command) {
+	cmd.Flags().String("hook", "", "Shell command to run per event; the event is passed as JSON on stdin")
+	cmd.Flags().String("webhook", "", "URL to POST events to as JSON")
+	cmd.Flags().Bool("desktop", false, "Show desktop notifications via notify-send")
+}
+
+// notifySinks combines the configured notification sinks with the ones given as flags
+func notifySinks(cmd *cobra.Command, cfg *config.AppConfig) []notify.Sink {
+	hook, _ := cmd.Flags().GetString("hook")
+	webhook, _ := cmd.Flags().GetString("webhook")
+	desktop, _ := cmd.Flags().GetBool("desktop")
+
+	notifyCfg := config.NotifyConfig{}
+	if cfg.Notify != nil {
+		notifyCfg = *cfg.Notify
+	}
+	if hook != "" {
+		notifyCfg.Hook = hook
+	}
+	if webhook != "" {
+		notifyCfg.Webhook = webhook
+	}
+	notifyCfg.Desktop = notifyCfg.Desktop || desktop
+
+	return notify.FromConfig(&notifyCfg)
+}
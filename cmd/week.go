@@ -0,0 +1,108 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"faliactl/pkg/exporter"
+	"faliactl/pkg/scraper"
+	"faliactl/pkg/timetable"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/term"
+	"github.com/spf13/cobra"
+)
+
+var weekCmd = &cobra.Command{
+	Use:   "week",
+	Short: "Show a week of your timetable as a Mon–Fri grid",
+	Long: `Lays out one week of courses as a time grid in the terminal, colored by course type
+(lecture, exercise, lab, seminar, exam). Overlapping courses are drawn side by side.
+
+Without --group or --set your saved groups and courses are shown. --html writes the same grid
+as a standalone, print-ready HTML page.`,
+	Example: `  faliactl week
+  faliactl week --group 161902 --week 2026-W42
+  faliactl week --set my-classes --week next --html week.html`,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		weekSpec, _ := cmd.Flags().GetString("week")
+		htmlPath, _ := cmd.Flags().GetString("html")
+		width, _ := cmd.Flags().GetInt("width")
+
+		monday, err := timetable.ParseWeek(weekSpec, time.Now())
+		if err != nil {
+			return err
+		}
+
+		courses, err := loadSelection(cmd)
+		if err != nil {
+			return err
+		}
+
+		grid := timetable.Build(courses, monday)
+		_, number := monday.ISOWeek()
+		title := fmt.Sprintf("Week %d: %s – %s", number, monday.Format("02.01."), monday.AddDate(0, 0, 4).Format("02.01.2006"))
+
+		if structured(cmd) && htmlPath == "" {
+			var inWeek []scraper.Course
+			for _, day := range grid.Days {
+				for _, slot := range day.Slots {
+					inWeek = append(inWeek, slot.Course)
+				}
+			}
+			year, _ := monday.ISOWeek()
+			return printStructured(cmd, weekOutput{
+				Week:    fmt.Sprintf("%d-W%02d", year, number),
+				Monday:  monday.Format("2006-01-02"),
+				Courses: exporter.CourseRecords(inWeek),
+			})
+		}
+
+		if htmlPath != "" {
+			file, err := os.Create(htmlPath)
+			if err != nil {
+				return fmt.Errorf("failed to create output file: %w", err)
+			}
+			defer file.Close()
+			if err := timetable.WriteHTML(file, "Timetable", []*timetable.Grid{grid}); err != nil {
+				return err
+			}
+			fmt.Printf("Wrote %s (%d courses) to %s\n", title, grid.Courses(), htmlPath)
+			return nil
+		}
+
+		if width <= 0 {
+			width = 120
+			if w, _, err := term.GetSize(os.Stdout.Fd()); err == nil && w > 0 {
+				width = w
+			}
+		}
+
+		fmt.Println(lipgloss.NewStyle().Bold(true).Render(title))
+		if grid.Courses() == 0 {
+			fmt.Println("No courses this week.")
+			return nil
+		}
+		fmt.Print(grid.Render(width))
+		fmt.Println(timetable.Legend())
+		return nil
+	},
+}
+
+// weekOutput is the schema of `week --output json`
+type weekOutput struct {
+	Week    string                  `json:"week"`   // ISO week, e.g. 2026-W42
+	Monday  string                  `json:"monday"` // YYYY-MM-DD
+	Courses []exporter.CourseRecord `json:"courses"`
+}
+
+func init() {
+	rootCmd.AddCommand(weekCmd)
+
+	addSelectionFlags(weekCmd)
+	weekCmd.Flags().StringP("week", "w", "", "Week to show: 2026-W42, a date inside the week, next or last (default: this week)")
+	weekCmd.Flags().String("html", "", "Write the grid as a print-ready HTML file instead")
+	weekCmd.Flags().Int("width", 0, "Terminal width to lay out for (default: detected)")
+}
@@ -0,0 +1,296 @@
+// Package campus knows the Ostfalia sites: their addresses, coordinates, HAFAS stops,
+// Mensa locations and the room-code prefixes that identify them
+package campus
+
+import (
+	"strings"
+	"sync/atomic"
+)
+
+// Campus describes one site. Overrides in ~/.faliactl.json use the same JSON shape.
+type Campus struct {
+	ID          string  `json:"id"`             // Stable key, e.g. "wf-exer"
+	Name        string  `json:"name,omitempty"` // Display name
+	City        string  `json:"city,omitempty"` // City key shared by the sites of one town, e.g. "wolfenbuettel"
+	Address     string  `json:"address,omitempty"`
+	Latitude    float64 `json:"lat,omitempty"`
+	Longitude   float64 `json:"lon,omitempty"`
+	StationID   string  `json:"station_id,omitempty"` // HAFAS stop next to the campus
+	StationName string  `json:"station_name,omitempty"`
+	MensaIDs    []int   `json:"mensa_ids,omitempty"` // Studentenwerk location IDs, the main Mensa first
+	// RoomPrefixes are the leading segments of room codes on this campus, e.g. "WF-EX" or "SZ"
+	RoomPrefixes []string `json:"room_prefixes,omitempty"`
+	// Aliases are further names accepted by Lookup, e.g. for --campus flags
+	Aliases []string `json:"aliases,omitempty"`
+	// Fallback marks the campus used for rooms without a known prefix
+	Fallback bool `json:"fallback,omitempty"`
+	// StopOnly marks places that can be routed to but are not a campus, like Braunschweig Hbf
+	StopOnly bool `json:"stop_only,omitempty"`
+}
+
+// Builtin lists the known sites. Coordinates point at the main entrance.
+var Builtin = []Campus{
+	{
+		ID:           "wf-haupt",
+		Name:         "Wolfenbüttel (Hauptcampus)",
+		City:         "wolfenbuettel",
+		Address:      "Salzdahlumer Str. 46/48, 38302 Wolfenbüttel",
+		Latitude:     52.1777,
+		Longitude:    10.5478,
+		StationID:    "891097",
+		StationName:  "Ostfalia Hauptcampus (Salzdahlumer Str.)",
+		MensaIDs:     []int{130},
+		RoomPrefixes: []string{"WF"},
+		Aliases:      []string{"wolfenbuettel", "wolfenbüttel", "wf"},
+		Fallback:     true,
+	},
+	{
+		ID:           "wf-exer",
+		Name:         "Wolfenbüttel (Am Exer)",
+		City:         "wolfenbuettel",
+		Address:      "Am Exer 2, 38302 Wolfenbüttel",
+		Latitude:     52.1592,
+		Longitude:    10.5356,
+		StationID:    "891011",
+		StationName:  "Ostfalia Am Exer",
+		MensaIDs:     []int{130},
+		RoomPrefixes: []string{"WF-EX", "EX"},
+		Aliases:      []string{"exer", "am-exer"},
+	},
+	{
+		ID:           "salzgitter",
+		Name:         "Salzgitter (Ostfalia Campus)",
+		City:         "salzgitter",
+		Address:      "Karl-Scharfenberg-Straße 55-57, 38229 Salzgitter",
+		Latitude:     52.0884,
+		Longitude:    10.3796,
+		StationID:    "991604089",
+		StationName:  "Ostfalia Salzgitter",
+		MensaIDs:     []int{200},
+		RoomPrefixes: []string{"SZ"},
+		Aliases:      []string{"sz"},
+	},
+	{
+		ID:           "suderburg",
+		Name:         "Suderburg (Ostfalia Campus)",
+		City:         "suderburg",
+		Address:      "Herbert-Meyer-Straße 7, 29556 Suderburg",
+		Latitude:     52.8963,
+		Longitude:    10.4477,
+		StationID:    "991604106",
+		StationName:  "Ostfalia Suderburg",
+		MensaIDs:     []int{134},
+		RoomPrefixes: []string{"SUD"},
+		Aliases:      []string{"sud"},
+	},
+	{
+		ID:           "wolfsburg",
+		Name:         "Wolfsburg (Ostfalia Campus)",
+		City:         "wolfsburg",
+		Address:      "Robert-Koch-Platz 12, 38440 Wolfsburg",
+		Latitude:     52.4212,
+		Longitude:    10.7863,
+		MensaIDs:     []int{112},
+		RoomPrefixes: []string{"WOB"},
+		Aliases:      []string{"wob"},
+	},
+	{
+		ID:          "braunschweig",
+		Name:        "Braunschweig Hbf",
+		City:        "braunschweig",
+		Latitude:    52.2527,
+		Longitude:   10.5400,
+		StationID:   "8000049",
+		StationName: "Braunschweig Hbf",
+		Aliases:     []string{"bs"},
+		StopOnly:    true,
+	},
+}
+
+// Registry resolves campus names and room codes
+type Registry struct {
+	campuses []Campus
+}
+
+// NewRegistry builds a registry from the given sites
+func NewRegistry(campuses []Campus) *Registry {
+	return &Registry{campuses: append([]Campus(nil), campuses...)}
+}
+
+// WithOverrides returns a copy where the overrides replace the non-empty fields of the campus
+// with the same ID. Overrides with unknown IDs are added as new sites.
+func (r *Registry) WithOverrides(overrides []Campus) *Registry {
+	out := NewRegistry(r.campuses)
+	for _, o := range overrides {
+		if o.ID == "" {
+			continue
+		}
+		i := out.index(o.ID)
+		if i < 0 {
+			out.campuses = append(out.campuses, o)
+			continue
+		}
+		c := &out.campuses[i]
+		if o.Name != "" {
+			c.Name = o.Name
+		}
+		if o.City != "" {
+			c.City = o.City
+		}
+		if o.Address != "" {
+			c.Address = o.Address
+		}
+		if o.Latitude != 0 || o.Longitude != 0 {
+			c.Latitude, c.Longitude = o.Latitude, o.Longitude
+		}
+		if o.StationID != "" {
+			c.StationID = o.StationID
+		}
+		if o.StationName != "" {
+			c.StationName = o.StationName
+		}
+		if len(o.MensaIDs) > 0 {
+			c.MensaIDs = o.MensaIDs
+		}
+		if len(o.RoomPrefixes) > 0 {
+			c.RoomPrefixes = o.RoomPrefixes
+		}
+		if len(o.Aliases) > 0 {
+			c.Aliases = o.Aliases
+		}
+		if o.Fallback {
+			for j := range out.campuses {
+				out.campuses[j].Fallback = false
+			}
+			c.Fallback = true
+		}
+	}
+	return out
+}
+
+func (r *Registry) index(id string) int {
+	for i, c := range r.campuses {
+		if strings.EqualFold(c.ID, id) {
+			return i
+		}
+	}
+	return -1
+}
+
+// All returns every site in registry order
+func (r *Registry) All() []Campus {
+	return append([]Campus(nil), r.campuses...)
+}
+
+// Campuses returns the sites that are actual campuses, leaving out StopOnly places
+func (r *Registry) Campuses() []Campus {
+	var out []Campus
+	for _, c := range r.campuses {
+		if !c.StopOnly {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// Lookup finds a site by ID, alias or city (the first site of that city), ignoring case
+func (r *Registry) Lookup(name string) (Campus, bool) {
+	name = normalize(name)
+	if name == "" {
+		return Campus{}, false
+	}
+	for _, c := range r.campuses {
+		if normalize(c.ID) == name {
+			return c, true
+		}
+	}
+	for _, c := range r.campuses {
+		for _, alias := range c.Aliases {
+			if normalize(alias) == name {
+				return c, true
+			}
+		}
+	}
+	for _, c := range r.campuses {
+		if normalize(c.City) == name {
+			return c, true
+		}
+	}
+	return Campus{}, false
+}
+
+// Known reports whether name is the ID, alias or city of a site in the default registry
+func Known(name string) bool {
+	n := normalize(name)
+	for _, c := range Default().campuses {
+		if c.Is(n) {
+			return true
+		}
+	}
+	return false
+}
+
+// Is reports whether name is the campus's ID, one of its aliases or its city, ignoring case.
+// "wolfenbuettel" therefore matches both Wolfenbüttel sites.
+func (c Campus) Is(name string) bool {
+	name = normalize(name)
+	if name == "" {
+		return false
+	}
+	if normalize(c.ID) == name || normalize(c.City) == name {
+		return true
+	}
+	for _, alias := range c.Aliases {
+		if normalize(alias) == name {
+			return true
+		}
+	}
+	return false
+}
+
+// ForRoom returns the campus of a room code, falling back to the fallback campus
+func (r *Registry) ForRoom(room string) Campus {
+	return r.campus(r.ParseRoom(room).Campus)
+}
+
+// Fallback returns the campus used for rooms without a known prefix
+func (r *Registry) Fallback() Campus {
+	for _, c := range r.campuses {
+		if c.Fallback {
+			return c
+		}
+	}
+	if len(r.campuses) > 0 {
+		return r.campuses[0]
+	}
+	return Campus{}
+}
+
+func (r *Registry) campus(id string) Campus {
+	if i := r.index(id); i >= 0 {
+		return r.campuses[i]
+	}
+	return r.Fallback()
+}
+
+// normalize folds case and German umlauts so "Wolfenbüttel" finds "wolfenbuettel"
+func normalize(s string) string {
+	s = strings.ToLower(strings.TrimSpace(s))
+	return strings.NewReplacer("ü", "ue", "ö", "oe", "ä", "ae", "ß", "ss", " ", "-", "_", "-").Replace(s)
+}
+
+var current atomic.Pointer[Registry]
+
+// Default returns the registry used by the rest of faliactl: the built-in sites, plus the
+// user's overrides once SetDefault has been called with them
+func Default() *Registry {
+	if r := current.Load(); r != nil {
+		return r
+	}
+	return NewRegistry(Builtin)
+}
+
+// SetDefault replaces the registry returned by Default
+func SetDefault(r *Registry) {
+	current.Store(r)
+}
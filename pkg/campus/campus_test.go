@@ -0,0 +1,98 @@
+package campus
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestForRoom(t *testing.T) {
+	r := NewRegistry(Builtin)
+	cases := map[string]string{
+		"SZ-A-101":    "991604089",
+		"SUD-H-1":     "991604106",
+		"WF-EX-7/3":   "891011",
+		"WF-C-015":    "891097",
+		"":            "891097",
+		"wf-ex-2/127": "891011",
+		"EX-2/21":     "891011",
+		"Online":      "891097",
+	}
+	for room, want := range cases {
+		if got := r.ForRoom(room).StationID; got != want {
+			t.Errorf("ForRoom(%q).StationID = %s, want %s", room, got, want)
+		}
+	}
+	if got := r.ForRoom("WOB-A-12").Address; got != "Robert-Koch-Platz 12, 38440 Wolfsburg" {
+		t.Errorf("unexpected Wolfsburg address %q", got)
+	}
+}
+
+func TestParseRoom(t *testing.T) {
+	r := NewRegistry(Builtin)
+	cases := map[string]Room{
+		"WF-EX-7/3":   {Code: "WF-EX-7/3", Campus: "wf-exer", Building: "7", Number: "3"},
+		"WF-EX-2/127": {Code: "WF-EX-2/127", Campus: "wf-exer", Building: "2", Floor: "1", Number: "127"},
+		"WF-C-015":    {Code: "WF-C-015", Campus: "wf-haupt", Building: "C", Floor: "0", Number: "015"},
+		"SZ-A-101":    {Code: "SZ-A-101", Campus: "salzgitter", Building: "A", Floor: "1", Number: "101"},
+		"sud-h-1":     {Code: "sud-h-1", Campus: "suderburg", Building: "H", Number: "1"},
+		"Aula":        {Code: "Aula", Number: "AULA"},
+	}
+	for code, want := range cases {
+		if got := r.ParseRoom(code); !reflect.DeepEqual(got, want) {
+			t.Errorf("ParseRoom(%q) = %+v, want %+v", code, got, want)
+		}
+	}
+	if got := r.ParseRoom("WF-EX-2/127").String(); got != "building 2, floor 1, room 127" {
+		t.Errorf("unexpected String(): %q", got)
+	}
+}
+
+func TestLookup(t *testing.T) {
+	r := NewRegistry(Builtin)
+	cases := map[string]string{
+		"wf-exer":       "wf-exer",
+		"Exer":          "wf-exer",
+		"wolfenbuettel": "wf-haupt",
+		"Wolfenbüttel":  "wf-haupt",
+		"SALZGITTER":    "salzgitter",
+		"braunschweig":  "braunschweig",
+	}
+	for name, want := range cases {
+		if c, ok := r.Lookup(name); !ok || c.ID != want {
+			t.Errorf("Lookup(%q) = %q, %v; want %q", name, c.ID, ok, want)
+		}
+	}
+	if _, ok := r.Lookup("atlantis"); ok {
+		t.Error("expected an unknown campus to fail")
+	}
+
+	for _, c := range r.Campuses() {
+		if c.StopOnly {
+			t.Errorf("Campuses() must leave out %s", c.ID)
+		}
+	}
+}
+
+func TestWithOverrides(t *testing.T) {
+	base := NewRegistry(Builtin)
+	r := base.WithOverrides([]Campus{
+		{ID: "salzgitter", Address: "Neue Straße 1, 38226 Salzgitter"},
+		{ID: "gifhorn", Name: "Gifhorn", City: "gifhorn", StationID: "123", RoomPrefixes: []string{"GF"}},
+	})
+
+	sz, _ := r.Lookup("salzgitter")
+	if sz.Address != "Neue Straße 1, 38226 Salzgitter" || sz.StationID != "991604089" {
+		t.Errorf("override should replace only the given fields: %+v", sz)
+	}
+	if got := r.ForRoom("GF-1/12").ID; got != "gifhorn" {
+		t.Errorf("added campus not resolved from its prefix: %s", got)
+	}
+	if orig, _ := base.Lookup("salzgitter"); orig.Address == sz.Address {
+		t.Error("WithOverrides must not modify the original registry")
+	}
+
+	r = base.WithOverrides([]Campus{{ID: "wf-exer", Fallback: true}})
+	if got := r.ForRoom("Aula").ID; got != "wf-exer" {
+		t.Errorf("fallback override ignored: %s", got)
+	}
+}
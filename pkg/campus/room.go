@@ -0,0 +1,96 @@
+package campus
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Room is a parsed room code. "WF-EX-2/127" is building 2, room 127 on floor 1 of Am Exer.
+type Room struct {
+	Code     string // The code as given
+	Campus   string // Campus ID, empty when no prefix matched
+	Building string
+	Floor    string // Empty when the room number doesn't encode it
+	Number   string
+}
+
+// ParseRoom splits a room code into campus, building, floor and room number. The campus is
+// the site with the longest matching room prefix. The rest follows "<building>-<room>" or
+// "<building>/<room>"; for room numbers with three or more digits the first one is the floor.
+func (r *Registry) ParseRoom(code string) Room {
+	room := Room{Code: code}
+	upper := strings.ToUpper(strings.TrimSpace(code))
+
+	rest := upper
+	longest := 0
+	for _, c := range r.campuses {
+		for _, prefix := range c.RoomPrefixes {
+			prefix = strings.ToUpper(prefix)
+			if len(prefix) > longest && hasSegmentPrefix(upper, prefix) {
+				longest = len(prefix)
+				room.Campus = c.ID
+				rest = upper[len(prefix):]
+			}
+		}
+	}
+	rest = strings.TrimLeft(rest, "- ")
+	if rest == "" {
+		return room
+	}
+
+	if building, number, ok := strings.Cut(rest, "-"); ok {
+		room.Building, room.Number = building, number
+	} else {
+		room.Number = rest
+	}
+	// "7/3" is building 7, room 3, whether or not a dash came before it
+	if building, number, ok := strings.Cut(room.Number, "/"); ok && room.Building == "" {
+		room.Building, room.Number = building, number
+	} else if building, number, ok := strings.Cut(room.Building, "/"); ok {
+		room.Building, room.Number = building, number+"-"+room.Number
+	}
+
+	if digits := leadingDigits(room.Number); len(digits) >= 3 {
+		room.Floor = strings.TrimLeft(digits[:len(digits)-2], "0")
+		if room.Floor == "" {
+			room.Floor = "0"
+		}
+	}
+	return room
+}
+
+// hasSegmentPrefix reports whether code starts with prefix followed by a separator, a digit or nothing
+func hasSegmentPrefix(code, prefix string) bool {
+	if !strings.HasPrefix(code, prefix) {
+		return false
+	}
+	if len(code) == len(prefix) {
+		return true
+	}
+	next := rune(code[len(prefix)])
+	return next == '-' || next == ' ' || next == '/' || unicode.IsDigit(next)
+}
+
+func leadingDigits(s string) string {
+	for i, r := range s {
+		if !unicode.IsDigit(r) {
+			return s[:i]
+		}
+	}
+	return s
+}
+
+// String renders the parsed parts, e.g. "building 2, floor 1, room 127"
+func (r Room) String() string {
+	var parts []string
+	if r.Building != "" {
+		parts = append(parts, "building "+r.Building)
+	}
+	if r.Floor != "" {
+		parts = append(parts, "floor "+r.Floor)
+	}
+	if r.Number != "" {
+		parts = append(parts, "room "+r.Number)
+	}
+	return strings.Join(parts, ", ")
+}
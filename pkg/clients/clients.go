@@ -0,0 +1,90 @@
+// Package clients builds the intranet, Mensa and HAFAS clients with the user's
+// endpoint overrides from ~/.faliactl.json and the environment applied, including
+// fixture record/replay mode.
+package clients
+
+import (
+	"net/http"
+	"os"
+	"time"
+
+	"faliactl/pkg/campus"
+	"faliactl/pkg/config"
+	"faliactl/pkg/fixture"
+	"faliactl/pkg/mensa"
+	"faliactl/pkg/scraper"
+	"faliactl/pkg/transit"
+)
+
+// Environment variables that override the configured upstream base URLs
+const (
+	EnvIntranetURL = "FALIACTL_INTRANET_URL"
+	EnvMensaURL    = "FALIACTL_MENSA_URL"
+	EnvTransitURL  = "FALIACTL_TRANSIT_URL"
+)
+
+// Scraper returns an intranet client. Extra options are applied after the overrides.
+func Scraper(opts ...scraper.Option) *scraper.Client {
+	if hc := fixtureHTTPClient(); hc != nil {
+		opts = append([]scraper.Option{scraper.WithHTTPClient(hc)}, opts...)
+	}
+	if url := resolve(EnvIntranetURL, func(c *config.AppConfig) string { return c.IntranetURL }); url != "" {
+		opts = append([]scraper.Option{scraper.WithBaseURL(url)}, opts...)
+	}
+	return scraper.NewClient(opts...)
+}
+
+// Mensa returns a Studentenwerk API client. Extra options are applied after the overrides.
+func Mensa(opts ...mensa.Option) *mensa.Client {
+	if hc := fixtureHTTPClient(); hc != nil {
+		opts = append([]mensa.Option{mensa.WithHTTPClient(hc)}, opts...)
+	}
+	if url := resolve(EnvMensaURL, func(c *config.AppConfig) string { return c.MensaURL }); url != "" {
+		opts = append([]mensa.Option{mensa.WithBaseURL(url)}, opts...)
+	}
+	return mensa.NewClient(opts...)
+}
+
+// Transit returns a HAFAS client. Extra options are applied after the overrides.
+func Transit(opts ...transit.Option) *transit.Client {
+	if hc := fixtureHTTPClient(); hc != nil {
+		opts = append([]transit.Option{transit.WithHTTPClient(hc)}, opts...)
+	}
+	if url := resolve(EnvTransitURL, func(c *config.AppConfig) string { return c.TransitURL }); url != "" {
+		opts = append([]transit.Option{transit.WithBaseURL(url)}, opts...)
+	}
+	return transit.NewClient(opts...)
+}
+
+// Campuses returns the built-in campus registry with the overrides from ~/.faliactl.json applied
+func Campuses() *campus.Registry {
+	registry := campus.NewRegistry(campus.Builtin)
+	cfg, err := config.Load()
+	if err != nil || cfg == nil {
+		return registry
+	}
+	return registry.WithOverrides(cfg.Campuses)
+}
+
+// resolve prefers the environment over the config file and returns "" for the built-in default
+func resolve(env string, fromConfig func(*config.AppConfig) string) string {
+	if url := os.Getenv(env); url != "" {
+		return url
+	}
+	cfg, err := config.Load()
+	if err != nil || cfg == nil {
+		return ""
+	}
+	return fromConfig(cfg)
+}
+
+// fixtureHTTPClient returns an http.Client recording to or replaying from the directory named
+// by FALIACTL_RECORD/FALIACTL_REPLAY, or nil in normal operation. Misconfiguration is
+// reported up front by the root command, so errors are treated as "no fixtures" here.
+func fixtureHTTPClient() *http.Client {
+	rt, err := fixture.TransportFromEnv()
+	if err != nil || rt == nil {
+		return nil
+	}
+	return &http.Client{Transport: rt, Timeout: 30 * time.Second}
+}
@@ -0,0 +1,73 @@
+package clients
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func newStub(t *testing.T, hits *int, body string) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*hits++
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestEnvironmentOverridesConfig(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+
+	var configHits, envHits int
+	fromConfig := newStub(t, &configHits, `[]`)
+	fromEnv := newStub(t, &envHits, `[]`)
+
+	cfg := `{"mensa_url": "` + fromConfig.URL + `"}`
+	if err := os.WriteFile(filepath.Join(home, ".faliactl.json"), []byte(cfg), 0644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+
+	if _, err := Mensa().FetchLocations(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if configHits != 1 {
+		t.Errorf("expected the config file override to be used, got %d hits", configHits)
+	}
+
+	t.Setenv(EnvMensaURL, fromEnv.URL)
+	if _, err := Mensa().FetchLocations(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if envHits != 1 || configHits != 1 {
+		t.Errorf("expected the environment to take precedence, got env=%d config=%d", envHits, configHits)
+	}
+}
+
+func TestScraperAndTransitOverrides(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+
+	var scraperHits, transitHits int
+	intranet := newStub(t, &scraperHits, `<select id="group"><option value="1.html">One</option></select>`)
+	hafas := newStub(t, &transitHits, `[]`)
+
+	t.Setenv(EnvIntranetURL, intranet.URL)
+	t.Setenv(EnvTransitURL, hafas.URL)
+
+	groups, err := Scraper().FetchGroups()
+	if err != nil || len(groups) != 1 {
+		t.Fatalf("expected 1 group from the stub intranet, got %v (%v)", groups, err)
+	}
+	if _, err := Transit().FetchLocations("Wolfenbüttel"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if scraperHits != 1 || transitHits != 1 {
+		t.Errorf("expected both stubs to be hit once, got intranet=%d hafas=%d", scraperHits, transitHits)
+	}
+}
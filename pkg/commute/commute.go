@@ -0,0 +1,102 @@
+package commute
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"faliactl/pkg/campus"
+	"faliactl/pkg/scraper"
+	"faliactl/pkg/transit"
+)
+
+// FirstClasses returns the first saved course of every day that starts after `after` and
+// before `before`, sorted chronologically. We only commute ONCE per day, to the FIRST class.
+func FirstClasses(courses []scraper.Course, savedCourses []string, after, before time.Time) []scraper.Course {
+	saved := make(map[string]bool)
+	for _, name := range savedCourses {
+		saved[name] = true
+	}
+
+	var upcoming []scraper.Course
+	for _, c := range courses {
+		if !saved[c.Name] {
+			continue
+		}
+		start, _, err := c.Times()
+		if err != nil {
+			continue
+		}
+		if start.After(after) && start.Before(before) {
+			c.Start = start
+			upcoming = append(upcoming, c)
+		}
+	}
+
+	sort.SliceStable(upcoming, func(i, j int) bool {
+		return upcoming[i].Start.Before(upcoming[j].Start)
+	})
+
+	var firsts []scraper.Course
+	seenDates := make(map[string]bool)
+	for _, c := range upcoming {
+		date := c.Start.In(scraper.Berlin).Format("2006-01-02")
+		if !seenDates[date] {
+			seenDates[date] = true
+			firsts = append(firsts, c)
+		}
+	}
+	return firsts
+}
+
+// BestJourney asks HAFAS for connections from the home station that arrive before the course
+// starts. Journeys without legs are skipped, so the returned one always has at least one.
+func BestJourney(ctx context.Context, client *transit.Client, homeStationID string, course scraper.Course) (*transit.Journey, error) {
+	arrivalTime, _, err := course.Times()
+	if err != nil {
+		return nil, fmt.Errorf("could not parse class start time: %w", err)
+	}
+
+	journeys, err := client.FetchJourneysByArrivalContext(ctx, homeStationID, campus.Default().ForRoom(course.Room).StationID, arrivalTime)
+	if err != nil {
+		return nil, err
+	}
+
+	// HAFAS occasionally returns journeys without legs, which have no departure to plan with
+	var usable []transit.Journey
+	for _, j := range journeys {
+		if len(j.Legs) > 0 {
+			usable = append(usable, j)
+		}
+	}
+	journeys = usable
+	if len(journeys) == 0 {
+		return nil, fmt.Errorf("no routes found")
+	}
+
+	// HAFAS returns results sorted by arrival time ASCENDING. The last one is the closest
+	// to the required arrival edge; if even that one is late, fall back to the first.
+	best := journeys[len(journeys)-1]
+	if best.Legs[len(best.Legs)-1].Arrival.After(arrivalTime) {
+		best = journeys[0]
+	}
+	return &best, nil
+}
+
+// Steps renders every leg of the journey as "15:04 Line -> Destination"
+func Steps(journey *transit.Journey) []string {
+	var steps []string
+	for _, leg := range journey.Legs {
+		lineName := "Walk🚶"
+		if leg.Line != nil {
+			lineName = leg.Line.Name
+		}
+		step := fmt.Sprintf("%s %s -> %s", leg.Departure.In(scraper.Berlin).Format("15:04"), lineName, leg.Destination.Name)
+		if d := leg.Delay(); d > 0 {
+			step += fmt.Sprintf(" (+%d)", int(d.Minutes()))
+		}
+		steps = append(steps, step)
+	}
+	return steps
+}
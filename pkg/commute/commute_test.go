@@ -0,0 +1,239 @@
+package commute
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"faliactl/pkg/notify"
+	"faliactl/pkg/scraper"
+	"faliactl/pkg/transit"
+)
+
+func course(name, date, start, end, room string) scraper.Course {
+	c := scraper.Course{Name: name, DateStr: date, StartTime: start, EndTime: end, Room: room}
+	c.Start, c.End, _ = scraper.ParseCourseTimes(date, start, end)
+	return c
+}
+
+func TestFirstClasses(t *testing.T) {
+	courses := []scraper.Course{
+		course("Programmieren 2", "04.03.2026", "10:00", "11:30", "WF-EX-2/127"),
+		course("Lineare Algebra", "04.03.2026", "08:15", "09:45", "WF-EX-7/3"),
+		course("Sport", "05.03.2026", "07:00", "08:00", "WF-C-015"), // not saved
+		course("Programmieren 2", "05.03.2026", "12:00", "13:30", "WF-EX-2/127"),
+		course("Lineare Algebra", "12.03.2026", "08:15", "09:45", "WF-EX-7/3"), // beyond the window
+	}
+	saved := []string{"Lineare Algebra", "Programmieren 2"}
+
+	after := time.Date(2026, 3, 4, 0, 0, 0, 0, scraper.Berlin)
+	firsts := FirstClasses(courses, saved, after, after.AddDate(0, 0, 7))
+
+	if len(firsts) != 2 {
+		t.Fatalf("expected 2 first classes, got %d: %+v", len(firsts), firsts)
+	}
+	if firsts[0].Name != "Lineare Algebra" || firsts[1].StartTime != "12:00" {
+		t.Errorf("unexpected first classes: %+v", firsts)
+	}
+}
+
+// journeyClient returns a transit client whose every journey lookup answers with body
+func journeyClient(t *testing.T, body string) *transit.Client {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(server.Close)
+	return transit.NewClient(transit.WithBaseURL(server.URL))
+}
+
+func TestBestJourney_SkipsJourneysWithoutLegs(t *testing.T) {
+	class := course("Lineare Algebra", "04.03.2026", "08:15", "09:45", "WF-EX-7/3")
+
+	// The empty journey is last, where BestJourney looks first
+	client := journeyClient(t, `{"journeys": [{"legs": [
+		{"origin": {"name": "Home"}, "destination": {"name": "Exer Süd"},
+		 "departure": "2026-03-04T07:30:00+01:00", "arrival": "2026-03-04T08:00:00+01:00", "line": {"name": "Bus 420"}}
+	]}, {"legs": []}]}`)
+	journey, err := BestJourney(context.Background(), client, "123", class)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(journey.Legs) != 1 {
+		t.Errorf("expected the journey with a leg, got %+v", journey)
+	}
+
+	client = journeyClient(t, `{"journeys": [{"legs": []}]}`)
+	if _, err := BestJourney(context.Background(), client, "123", class); err == nil {
+		t.Errorf("expected an error when no journey has legs")
+	}
+}
+
+type recordingSink struct {
+	mu     sync.Mutex
+	events []notify.Event
+}
+
+func (s *recordingSink) Name() string { return "recording" }
+
+func (s *recordingSink) Notify(ctx context.Context, event notify.Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.events = append(s.events, event)
+	return nil
+}
+
+type failingSink struct{ calls int }
+
+func (s *failingSink) Name() string { return "failing" }
+
+func (s *failingSink) Notify(ctx context.Context, event notify.Event) error {
+	s.calls++
+	return errors.New("unreachable")
+}
+
+// newTestReminder plans the 08:15 class with a single bus leaving at 07:30
+func newTestReminder(t *testing.T, sink notify.Sink, now *time.Time) *Reminder {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"journeys": [{"legs": [
+			{"origin": {"name": "Home"}, "destination": {"name": "Exer Süd"},
+			 "departure": "2026-03-04T07:30:00+01:00", "plannedDeparture": "2026-03-04T07:30:00+01:00",
+			 "arrival": "2026-03-04T08:00:00+01:00", "line": {"name": "Bus 420"}}
+		]}]}`))
+	}))
+	t.Cleanup(server.Close)
+
+	return &Reminder{
+		Transit:       transit.NewClient(transit.WithBaseURL(server.URL)),
+		HomeStationID: "123",
+		SavedCourses:  []string{"Lineare Algebra"},
+		Classes: func() ([]scraper.Course, error) {
+			return []scraper.Course{course("Lineare Algebra", "04.03.2026", "08:15", "09:45", "WF-EX-7/3")}, nil
+		},
+		Lead:      15 * time.Minute,
+		Sinks:     []notify.Sink{sink},
+		StatePath: filepath.Join(t.TempDir(), "reminders.json"),
+		Now:       func() time.Time { return *now },
+	}
+}
+
+func TestReminder_SkipsDepartedConnection(t *testing.T) {
+	// The reminder only gets to run after the bus has left
+	now := time.Date(2026, 3, 4, 7, 40, 0, 0, scraper.Berlin)
+	sink := &recordingSink{}
+	r := newTestReminder(t, sink, &now)
+
+	if _, err := r.Step(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if len(sink.events) != 0 {
+		t.Fatalf("expected no reminder for a departed bus, got %+v", sink.events)
+	}
+
+	// The class counts as handled, so the reminder goes idle instead of re-planning it
+	wait, err := r.Step(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if wait != idleWait {
+		t.Errorf("expected an idle wait, got %s", wait)
+	}
+}
+
+func TestReminder_RetriesWhenEverySinkFails(t *testing.T) {
+	now := time.Date(2026, 3, 4, 7, 15, 0, 0, scraper.Berlin)
+	sink := &failingSink{}
+	r := newTestReminder(t, sink, &now)
+
+	wait, err := r.Step(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sink.calls != 1 || wait != minRequery {
+		t.Fatalf("expected one attempt and a retry in %s, got %d attempts and %s", minRequery, sink.calls, wait)
+	}
+
+	// The class was not marked as sent, so the next step tries again
+	now = now.Add(wait)
+	if _, err := r.Step(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if sink.calls != 2 {
+		t.Errorf("expected a second attempt, got %d", sink.calls)
+	}
+}
+
+func TestReminder_FiresOnceAtLeadTime(t *testing.T) {
+	// The bus is planned for 07:30 but running 5 minutes late
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"journeys": [{"legs": [
+			{"origin": {"name": "Home"}, "destination": {"name": "Exer Süd"},
+			 "departure": "2026-03-04T07:35:00+01:00", "plannedDeparture": "2026-03-04T07:30:00+01:00", "departureDelay": 300,
+			 "arrival": "2026-03-04T08:05:00+01:00", "line": {"name": "Bus 420"}}
+		]}]}`))
+	}))
+	defer server.Close()
+
+	now := time.Date(2026, 3, 4, 6, 0, 0, 0, scraper.Berlin)
+	sink := &recordingSink{}
+	r := &Reminder{
+		Transit:       transit.NewClient(transit.WithBaseURL(server.URL)),
+		HomeStationID: "123",
+		SavedCourses:  []string{"Lineare Algebra"},
+		Classes: func() ([]scraper.Course, error) {
+			return []scraper.Course{
+				course("Lineare Algebra", "04.03.2026", "08:15", "09:45", "WF-EX-7/3"),
+				course("Lineare Algebra", "04.03.2026", "12:00", "13:30", "WF-EX-7/3"),
+			}, nil
+		},
+		Lead:      15 * time.Minute,
+		Sinks:     []notify.Sink{sink},
+		StatePath: filepath.Join(t.TempDir(), "reminders.json"),
+		Now:       func() time.Time { return now },
+	}
+
+	// 80 minutes before the reminder is due: re-query after half of that
+	wait, err := r.Step(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if wait != 40*time.Minute {
+		t.Errorf("expected to re-query in 40m, got %s", wait)
+	}
+
+	// Close to the reminder the wait never overshoots it
+	now = time.Date(2026, 3, 4, 7, 19, 30, 0, scraper.Berlin)
+	if wait, _ = r.Step(context.Background()); wait != 30*time.Second {
+		t.Errorf("expected to wait 30s for the reminder, got %s", wait)
+	}
+	if len(sink.events) != 0 {
+		t.Fatalf("reminder fired too early")
+	}
+
+	// 07:20 = delayed departure 07:35 minus the 15 minute lead
+	now = time.Date(2026, 3, 4, 7, 20, 0, 0, scraper.Berlin)
+	if _, err := r.Step(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if len(sink.events) != 1 {
+		t.Fatalf("expected one reminder, got %d", len(sink.events))
+	}
+	c := sink.events[0].Commute
+	if c == nil || c.Delay != 5 || c.Course.StartTime != "08:15" || len(c.Steps) != 1 {
+		t.Errorf("unexpected reminder payload: %+v", c)
+	}
+
+	// Neither the same class nor the second lecture of that day trigger another reminder
+	now = time.Date(2026, 3, 4, 9, 0, 0, 0, scraper.Berlin)
+	wait, err = r.Step(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sink.events) != 1 || wait != idleWait {
+		t.Errorf("expected no further reminders and an idle wait, got %d events and %s", len(sink.events), wait)
+	}
+}
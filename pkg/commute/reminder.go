@@ -0,0 +1,227 @@
+package commute
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"faliactl/pkg/notify"
+	"faliactl/pkg/scraper"
+	"faliactl/pkg/transit"
+)
+
+// DefaultLead is how long before the planned departure the reminder fires
+const DefaultLead = 15 * time.Minute
+
+const (
+	// horizon bounds how far ahead the reminder looks for the next first class
+	horizon = 36 * time.Hour
+	// idleWait is how long to sleep when no class is coming up
+	idleWait = time.Hour
+	// retryWait is how long to wait after a failed schedule or journey lookup
+	retryWait = 5 * time.Minute
+	// minRequery and maxRequery bound the interval between journey lookups
+	minRequery = time.Minute
+	maxRequery = time.Hour
+)
+
+// Reminder re-plans the commute to the next first class of the day with live data and
+// notifies the sinks Lead before the (possibly delayed) departure
+type Reminder struct {
+	Transit       *transit.Client
+	HomeStationID string
+	SavedCourses  []string
+	// Classes returns the current schedule of the saved groups
+	Classes   func() ([]scraper.Course, error)
+	Lead      time.Duration
+	Sinks     []notify.Sink
+	StatePath string
+	// Logf receives progress and error messages (defaults to discarding them)
+	Logf func(format string, args ...any)
+	// Now is the clock (defaults to time.Now)
+	Now func() time.Time
+}
+
+// reminderState remembers which classes were already announced, keyed by Course.Key()
+type reminderState struct {
+	Sent map[string]time.Time `json:"sent"`
+}
+
+// DefaultStatePath returns the reminder state file inside the faliactl cache directory
+func DefaultStatePath() (string, error) {
+	cacheDir, err := scraper.CacheDir()
+	if err != nil {
+		return "", err
+	}
+	dir := filepath.Join(cacheDir, "commute")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("could not create commute state directory: %w", err)
+	}
+	return filepath.Join(dir, "reminders.json"), nil
+}
+
+// Run keeps planning and sending reminders until ctx is cancelled
+func (r *Reminder) Run(ctx context.Context) error {
+	for {
+		wait, err := r.Step(ctx)
+		if err != nil {
+			return err
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil
+		case <-timer.C:
+		}
+	}
+}
+
+// Step plans the next reminder once and returns how long to wait before calling it again.
+// The wait shrinks as the departure approaches, so delays reported late are still picked up.
+func (r *Reminder) Step(ctx context.Context) (time.Duration, error) {
+	state, err := r.loadState()
+	if err != nil {
+		return 0, err
+	}
+
+	now := r.now()
+	courses, err := r.Classes()
+	if err != nil {
+		r.logf("Failed to load schedule: %v", err)
+		return retryWait, nil
+	}
+
+	// First classes are determined per whole day, so the second lecture of a day
+	// never becomes a "first class" once the first one has started
+	local := now.In(scraper.Berlin)
+	today := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, scraper.Berlin)
+	var next *scraper.Course
+	for _, c := range FirstClasses(courses, r.SavedCourses, today, now.Add(horizon)) {
+		if c.Start.After(now) && state.Sent[c.Key()].IsZero() {
+			next = &c
+			break
+		}
+	}
+	if next == nil {
+		return idleWait, nil
+	}
+
+	journey, err := BestJourney(ctx, r.Transit, r.HomeStationID, *next)
+	if err != nil {
+		r.logf("Failed to plan commute to %s: %v", next.Name, err)
+		return retryWait, nil
+	}
+
+	// BestJourney only returns journeys with legs
+	first := journey.Legs[0]
+	last := journey.Legs[len(journey.Legs)-1]
+	fireAt := first.Departure.Add(-r.lead())
+
+	if now.Before(fireAt) {
+		wait := fireAt.Sub(now)
+		requery := min(max(wait/2, minRequery), maxRequery)
+		r.logf("Next: %s at %s, leave at %s, reminding at %s", next.Name, next.Start.Format("Mon 15:04"), first.Departure.In(scraper.Berlin).Format("15:04"), fireAt.In(scraper.Berlin).Format("15:04"))
+		return min(wait, requery), nil
+	}
+
+	// Reminding of a connection that has already left would only send the user to an empty
+	// stop, so the class is marked as handled and the reminder moves on to the next one
+	if !first.Departure.After(now) {
+		r.logf("Departure to %s at %s has already passed, skipping the reminder", next.Name, first.Departure.In(scraper.Berlin).Format("15:04"))
+		return 0, r.markSent(state, *next, now)
+	}
+
+	event := notify.Event{
+		Kind: notify.KindCommuteReminder,
+		Time: now,
+		Commute: &notify.Commute{
+			Course:  *next,
+			LeaveAt: first.Departure,
+			Arrival: last.Arrival,
+			Delay:   int(first.Delay().Minutes()),
+			Steps:   Steps(journey),
+		},
+	}
+	r.logf("%s", event.Summary())
+	errs := notify.NotifyAll(ctx, r.Sinks, event)
+	for _, err := range errs {
+		r.logf("Notification failed: %v", err)
+	}
+	if ctx.Err() != nil {
+		return 0, nil
+	}
+	if len(r.Sinks) > 0 && len(errs) == len(r.Sinks) {
+		// Nobody got the reminder: try again shortly while the departure is still ahead
+		return minRequery, nil
+	}
+
+	return 0, r.markSent(state, *next, now)
+}
+
+// markSent records the class as announced and forgets announcements older than a week
+func (r *Reminder) markSent(state *reminderState, c scraper.Course, now time.Time) error {
+	state.Sent[c.Key()] = now
+	for key, sent := range state.Sent {
+		if now.Sub(sent) > 7*24*time.Hour {
+			delete(state.Sent, key)
+		}
+	}
+	return r.saveState(state)
+}
+
+func (r *Reminder) lead() time.Duration {
+	if r.Lead <= 0 {
+		return DefaultLead
+	}
+	return r.Lead
+}
+
+func (r *Reminder) loadState() (*reminderState, error) {
+	state := &reminderState{Sent: make(map[string]time.Time)}
+
+	data, err := os.ReadFile(r.StatePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return state, nil
+		}
+		return nil, fmt.Errorf("failed to read reminder state: %w", err)
+	}
+	if err := json.Unmarshal(data, state); err != nil {
+		return nil, fmt.Errorf("failed to parse reminder state %s: %w", r.StatePath, err)
+	}
+	if state.Sent == nil {
+		state.Sent = make(map[string]time.Time)
+	}
+	return state, nil
+}
+
+func (r *Reminder) saveState(state *reminderState) error {
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to serialize reminder state: %w", err)
+	}
+
+	tmp := r.StatePath + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("failed to write reminder state: %w", err)
+	}
+	return os.Rename(tmp, r.StatePath)
+}
+
+func (r *Reminder) logf(format string, args ...any) {
+	if r.Logf != nil {
+		r.Logf(format, args...)
+	}
+}
+
+func (r *Reminder) now() time.Time {
+	if r.Now != nil {
+		return r.Now()
+	}
+	return time.Now()
+}
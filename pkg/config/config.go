@@ -5,6 +5,10 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"time"
+
+	"faliactl/pkg/campus"
+	"faliactl/pkg/filter"
 )
 
 // AppConfig holds all user-defined persistent settings
@@ -15,6 +19,50 @@ type AppConfig struct {
 	SavedCourses   []string `json:"saved_courses,omitempty"`
 	DefaultCampus  string   `json:"default_campus,omitempty"`
 	AccentColor    string   `json:"accent_color,omitempty"`
+
+	// CancelGraceDays is how long lectures that vanished from the schedule are still published
+	// as cancelled in exported calendars. 0 uses the default of 14 days, negative disables it.
+	CancelGraceDays int `json:"cancel_grace_days,omitempty"`
+	// Filter narrows and renames the courses of `faliactl export` and the interactive course picker
+	Filter *filter.Filter `json:"filter,omitempty"`
+
+	// Campuses overrides fields of the built-in campuses (matched by id) or adds new ones,
+	// e.g. a corrected address or another room prefix
+	Campuses []campus.Campus `json:"campuses,omitempty"`
+
+	// Notify configures where `faliactl watch` and `faliactl remind` send their events
+	Notify *NotifyConfig `json:"notify,omitempty"`
+	// CommuteLeadMinutes is how long before the departure `faliactl remind` fires (default 15)
+	CommuteLeadMinutes int `json:"commute_lead_minutes,omitempty"`
+
+	// Upstream overrides, e.g. to point the CLI at a local mirror or a stub server in CI.
+	// The FALIACTL_*_URL environment variables take precedence over these.
+	IntranetURL string `json:"intranet_url,omitempty"`
+	MensaURL    string `json:"mensa_url,omitempty"`
+	TransitURL  string `json:"transit_url,omitempty"`
+}
+
+// NotifyConfig lists the notification sinks used by the background commands
+type NotifyConfig struct {
+	Hook    string      `json:"hook,omitempty"`    // Shell command receiving the change event as JSON on stdin
+	Webhook string      `json:"webhook,omitempty"` // URL the change event is POSTed to as JSON
+	Desktop bool        `json:"desktop,omitempty"` // Show a notify-send desktop notification
+	SMTP    *SMTPConfig `json:"smtp,omitempty"`
+}
+
+// SMTPConfig holds the mail server settings for email notifications
+type SMTPConfig struct {
+	Host     string   `json:"host"`
+	Port     int      `json:"port,omitempty"`
+	Username string   `json:"username,omitempty"`
+	Password string   `json:"password,omitempty"`
+	From     string   `json:"from"`
+	To       []string `json:"to"`
+}
+
+// CancelGrace converts CancelGraceDays into the duration understood by the ICS exporter
+func (c *AppConfig) CancelGrace() time.Duration {
+	return time.Duration(c.CancelGraceDays) * 24 * time.Hour
 }
 
 // getConfigPath returns the absolute path to ~/.faliactl.json
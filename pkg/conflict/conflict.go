@@ -0,0 +1,250 @@
+// Package conflict finds problems in a combined timetable: overlapping lectures, campus
+// changes that cannot be made in the break, and long idle gaps
+package conflict
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"faliactl/pkg/campus"
+	"faliactl/pkg/scraper"
+	"faliactl/pkg/transit"
+)
+
+// DefaultMaxGap is the longest break between two lectures of a day that is not reported
+const DefaultMaxGap = 3 * time.Hour
+
+// Kind classifies a conflict
+type Kind string
+
+const (
+	KindOverlap Kind = "overlap" // Two courses take place at the same time
+	KindTravel  Kind = "travel"  // The break is too short to get to the next campus
+	KindGap     Kind = "gap"     // The break between two courses is longer than MaxGap
+)
+
+// Conflict is one problem between two courses of the same day. First starts before Second.
+type Conflict struct {
+	Kind   Kind           `json:"kind"`
+	First  scraper.Course `json:"first"`
+	Second scraper.Course `json:"second"`
+	// Duration is the overlap for KindOverlap, the break for KindTravel and the gap for KindGap
+	Duration time.Duration `json:"duration"`
+	// Travel is the estimated travel time between the campuses, KindTravel only
+	Travel time.Duration `json:"travel,omitempty"`
+	From   string        `json:"from,omitempty"` // Campus stop of First, KindTravel only
+	To     string        `json:"to,omitempty"`   // Campus stop of Second, KindTravel only
+}
+
+func (c Conflict) String() string {
+	day := c.First.Start.In(scraper.Berlin).Format("Mon 02.01.")
+	switch c.Kind {
+	case KindOverlap:
+		return fmt.Sprintf("%s %s overlaps %s by %s", day, describe(c.First), describe(c.Second), minutes(c.Duration))
+	case KindTravel:
+		return fmt.Sprintf("%s %s → %s: %s break, but ~%s from %s to %s",
+			day, describe(c.First), describe(c.Second), minutes(c.Duration), minutes(c.Travel), c.From, c.To)
+	default:
+		return fmt.Sprintf("%s %s gap between %s and %s", day, minutes(c.Duration), describe(c.First), describe(c.Second))
+	}
+}
+
+func describe(c scraper.Course) string {
+	s := fmt.Sprintf("%s–%s %s", c.Start.In(scraper.Berlin).Format("15:04"), c.End.In(scraper.Berlin).Format("15:04"), c.Name)
+	if c.Room != "" {
+		s += " (" + c.Room + ")"
+	}
+	return s
+}
+
+func minutes(d time.Duration) string {
+	if d >= time.Hour && d%time.Hour == 0 {
+		return fmt.Sprintf("%dh", int(d.Hours()))
+	}
+	if d >= time.Hour {
+		return fmt.Sprintf("%dh%02d", int(d.Hours()), int(d.Minutes())%60)
+	}
+	return fmt.Sprintf("%d min", int(d.Minutes()))
+}
+
+// TravelFunc estimates how long it takes to get from one campus to another, arriving by arriveBy
+type TravelFunc func(ctx context.Context, from, to campus.Campus, arriveBy time.Time) (time.Duration, error)
+
+// Options tunes Analyze
+type Options struct {
+	// MaxGap is the longest break that is not reported. Zero means DefaultMaxGap, negative
+	// disables gap detection.
+	MaxGap time.Duration
+	// Travel estimates campus changes (defaults to StaticTravel). Errors fall back to StaticTravel.
+	Travel TravelFunc
+}
+
+// Analyze reports every overlap, impossible campus change and long gap, in chronological order
+func Analyze(courses []scraper.Course, opts Options) []Conflict {
+	return AnalyzeContext(context.Background(), courses, opts)
+}
+
+// AnalyzeContext is Analyze with a context that is handed to opts.Travel
+func AnalyzeContext(ctx context.Context, courses []scraper.Course, opts Options) []Conflict {
+	maxGap := opts.MaxGap
+	if maxGap == 0 {
+		maxGap = DefaultMaxGap
+	}
+	travel := opts.Travel
+	if travel == nil {
+		travel = StaticTravel
+	}
+
+	campuses := campus.Default()
+
+	// Group the courses by day, with Start/End filled in
+	days := make(map[string][]scraper.Course)
+	var dayKeys []string
+	for _, c := range courses {
+		start, end, err := c.Times()
+		if err != nil {
+			continue
+		}
+		c.Start, c.End = start, end
+		key := start.Format("2006-01-02")
+		if _, ok := days[key]; !ok {
+			dayKeys = append(dayKeys, key)
+		}
+		days[key] = append(days[key], c)
+	}
+	sort.Strings(dayKeys)
+
+	var conflicts []Conflict
+	for _, key := range dayKeys {
+		day := days[key]
+		sort.SliceStable(day, func(i, j int) bool { return day[i].Start.Before(day[j].Start) })
+
+		for i := range day {
+			for j := i + 1; j < len(day) && day[j].Start.Before(day[i].End); j++ {
+				end := day[i].End
+				if day[j].End.Before(end) {
+					end = day[j].End
+				}
+				conflicts = append(conflicts, Conflict{Kind: KindOverlap, First: day[i], Second: day[j], Duration: end.Sub(day[j].Start)})
+			}
+		}
+
+		// Breaks are measured from the latest end so far, so a long course spanning a short one
+		// doesn't produce a bogus break
+		prev := day[0]
+		for _, next := range day[1:] {
+			if !next.Start.Before(prev.End) {
+				gap := next.Start.Sub(prev.End)
+				// ForRoom would put "Online", empty and unknown rooms on the fallback campus and
+				// report travel that isn't needed, so only recognised rooms are compared
+				from, fromOK := roomCampus(campuses, prev.Room)
+				to, toOK := roomCampus(campuses, next.Room)
+				if fromOK && toOK && from.ID != to.ID {
+					needed, err := travel(ctx, from, to, next.Start)
+					if err != nil {
+						needed, _ = StaticTravel(ctx, from, to, next.Start)
+					}
+					if needed > gap {
+						conflicts = append(conflicts, Conflict{Kind: KindTravel, First: prev, Second: next, Duration: gap,
+							Travel: needed, From: stopName(from), To: stopName(to)})
+					}
+				}
+				if maxGap > 0 && gap > maxGap {
+					conflicts = append(conflicts, Conflict{Kind: KindGap, First: prev, Second: next, Duration: gap})
+				}
+			}
+			if next.End.After(prev.End) {
+				prev = next
+			}
+		}
+	}
+	return conflicts
+}
+
+// roomCampus returns the campus of a room whose prefix the registry knows
+func roomCampus(campuses *campus.Registry, room string) (campus.Campus, bool) {
+	id := campuses.ParseRoom(room).Campus
+	if id == "" {
+		return campus.Campus{}, false
+	}
+	return campuses.Lookup(id)
+}
+
+// staticMinutes is a rough public transport matrix between the campus IDs of the registry
+var staticMinutes = map[[2]string]int{
+	{"wf-haupt", "wf-exer"}:     15, // Hauptcampus ↔ Am Exer, Wolfenbüttel
+	{"wf-haupt", "salzgitter"}:  50, // Wolfenbüttel ↔ Salzgitter
+	{"wf-exer", "salzgitter"}:   55,
+	{"wf-haupt", "suderburg"}:   150, // Wolfenbüttel ↔ Suderburg
+	{"wf-exer", "suderburg"}:    150,
+	{"salzgitter", "suderburg"}: 180, // Salzgitter ↔ Suderburg
+}
+
+// StaticTravel looks the campus change up in a built-in matrix and never fails. Pairs
+// missing from it are estimated from the campus coordinates; without those they count as
+// no travel time.
+func StaticTravel(_ context.Context, from, to campus.Campus, _ time.Time) (time.Duration, error) {
+	if from.ID == to.ID {
+		return 0, nil
+	}
+	m, ok := staticMinutes[[2]string{from.ID, to.ID}]
+	if !ok {
+		m, ok = staticMinutes[[2]string{to.ID, from.ID}]
+	}
+	if !ok {
+		return estimate(from, to), nil
+	}
+	return time.Duration(m) * time.Minute, nil
+}
+
+// estimate assumes 15 minutes to get going plus 40 km/h as the crow flies
+func estimate(from, to campus.Campus) time.Duration {
+	if from.Latitude == 0 || to.Latitude == 0 {
+		return 0
+	}
+	const earthRadiusKm = 6371
+	lat1, lat2 := from.Latitude*math.Pi/180, to.Latitude*math.Pi/180
+	dLat, dLon := lat2-lat1, (to.Longitude-from.Longitude)*math.Pi/180
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLon/2)*math.Sin(dLon/2)
+	km := 2 * earthRadiusKm * math.Asin(math.Sqrt(a))
+	return (15*time.Minute + time.Duration(km/40*float64(time.Hour))).Round(5 * time.Minute)
+}
+
+// stopName names a campus by its stop, as the commute views do
+func stopName(c campus.Campus) string {
+	if c.StationName != "" {
+		return c.StationName
+	}
+	return c.Name
+}
+
+// TransitTravel asks HAFAS for connections arriving by the start of the next course and
+// returns the duration of the fastest one
+func TransitTravel(client *transit.Client) TravelFunc {
+	return func(ctx context.Context, from, to campus.Campus, arriveBy time.Time) (time.Duration, error) {
+		if from.StationID == "" || to.StationID == "" {
+			return 0, fmt.Errorf("no stop known for %s or %s", from.Name, to.Name)
+		}
+		journeys, err := client.FetchJourneysByArrivalContext(ctx, from.StationID, to.StationID, arriveBy)
+		if err != nil {
+			return 0, err
+		}
+		var best time.Duration
+		for _, j := range journeys {
+			if len(j.Legs) == 0 {
+				continue
+			}
+			d := j.Legs[len(j.Legs)-1].Arrival.Sub(j.Legs[0].Departure)
+			if best == 0 || d < best {
+				best = d
+			}
+		}
+		if best == 0 {
+			return 0, fmt.Errorf("no connection from %s to %s", stopName(from), stopName(to))
+		}
+		return best, nil
+	}
+}
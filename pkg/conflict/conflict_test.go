@@ -0,0 +1,123 @@
+package conflict
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"faliactl/pkg/campus"
+	"faliactl/pkg/scraper"
+)
+
+func course(name, room string, startH, startM, endH, endM int) scraper.Course {
+	return scraper.Course{
+		Name:  name,
+		Room:  room,
+		Start: time.Date(2026, 3, 4, startH, startM, 0, 0, scraper.Berlin),
+		End:   time.Date(2026, 3, 4, endH, endM, 0, 0, scraper.Berlin),
+	}
+}
+
+func kinds(conflicts []Conflict) []Kind {
+	var out []Kind
+	for _, c := range conflicts {
+		out = append(out, c.Kind)
+	}
+	return out
+}
+
+func TestAnalyze_Overlap(t *testing.T) {
+	conflicts := Analyze([]scraper.Course{
+		course("Physik", "WF-EX-2/20", 9, 0, 10, 30),
+		course("Mathe", "WF-EX-2/21", 8, 15, 9, 45),
+	}, Options{})
+	if len(conflicts) != 1 || conflicts[0].Kind != KindOverlap {
+		t.Fatalf("expected one overlap, got %v", conflicts)
+	}
+	c := conflicts[0]
+	if c.First.Name != "Mathe" || c.Duration != 45*time.Minute {
+		t.Errorf("unexpected overlap: %+v", c)
+	}
+	if !strings.Contains(c.String(), "overlaps") || !strings.Contains(c.String(), "45 min") {
+		t.Errorf("unexpected message %q", c.String())
+	}
+}
+
+func TestAnalyze_Travel(t *testing.T) {
+	courses := []scraper.Course{
+		course("Mathe", "WF-EX-2/21", 8, 15, 9, 45),
+		course("Chemie", "SZ-A-101", 10, 0, 11, 30),
+		course("Bio", "SZ-B-1", 11, 45, 13, 15), // Same campus: no travel needed
+	}
+	conflicts := Analyze(courses, Options{})
+	if len(conflicts) != 1 || conflicts[0].Kind != KindTravel {
+		t.Fatalf("expected one travel conflict, got %v", conflicts)
+	}
+	if c := conflicts[0]; c.Duration != 15*time.Minute || c.Travel != 55*time.Minute || c.To != "Ostfalia Salzgitter" {
+		t.Errorf("unexpected travel conflict: %+v", c)
+	}
+
+	// A custom estimate wins; a failing one falls back to the static matrix
+	fast := func(_ context.Context, from, to campus.Campus, _ time.Time) (time.Duration, error) {
+		return 10 * time.Minute, nil
+	}
+	if conflicts := Analyze(courses, Options{Travel: fast}); len(conflicts) != 0 {
+		t.Errorf("expected no conflict with a 10 min connection, got %v", conflicts)
+	}
+	failing := func(_ context.Context, from, to campus.Campus, _ time.Time) (time.Duration, error) {
+		return 0, errors.New("offline")
+	}
+	if conflicts := Analyze(courses, Options{Travel: failing}); len(conflicts) != 1 {
+		t.Errorf("expected the static fallback to report the conflict, got %v", conflicts)
+	}
+}
+
+func TestAnalyze_TravelSkipsUnknownRooms(t *testing.T) {
+	// None of these rooms names a campus, so none of the short breaks needs travel
+	courses := []scraper.Course{
+		course("Chemie", "SZ-A-101", 8, 15, 9, 45),
+		course("Webinar", "Online", 10, 0, 11, 30),
+		course("Mathe", "WF-EX-2/21", 11, 45, 12, 30),
+		course("Projekt", "", 12, 45, 13, 30),
+		course("Labor", "XY-99", 13, 45, 14, 30),
+		course("Physik", "SZ-A-101", 14, 45, 16, 15),
+	}
+	if conflicts := Analyze(courses, Options{}); len(conflicts) != 0 {
+		t.Errorf("expected no travel conflicts next to unknown rooms, got %v", conflicts)
+	}
+}
+
+func TestAnalyze_Gap(t *testing.T) {
+	courses := []scraper.Course{
+		course("Mathe", "WF-EX-2/21", 8, 15, 9, 45),
+		course("Lang", "WF-EX-2/21", 8, 15, 13, 0), // Spans the short one, so the gap starts at 13:00
+		course("Spät", "WF-EX-2/21", 17, 0, 18, 30),
+	}
+	got := kinds(Analyze(courses, Options{}))
+	if len(got) != 2 || got[0] != KindOverlap || got[1] != KindGap {
+		t.Fatalf("expected overlap and gap, got %v", got)
+	}
+	gap := Analyze(courses, Options{})[1]
+	if gap.Duration != 4*time.Hour || gap.First.Name != "Lang" {
+		t.Errorf("unexpected gap: %+v", gap)
+	}
+
+	if got := kinds(Analyze(courses, Options{MaxGap: -1})); len(got) != 1 {
+		t.Errorf("a negative MaxGap should disable gap detection, got %v", got)
+	}
+	if got := kinds(Analyze(courses, Options{MaxGap: 5 * time.Hour})); len(got) != 1 {
+		t.Errorf("a 4h gap is fine with MaxGap 5h, got %v", got)
+	}
+}
+
+func TestAnalyze_DaysAreIndependent(t *testing.T) {
+	evening := course("Abend", "WF-EX-2/21", 18, 0, 19, 30)
+	morning := course("Morgen", "SZ-A-101", 8, 0, 9, 30)
+	morning.Start = morning.Start.AddDate(0, 0, 1)
+	morning.End = morning.End.AddDate(0, 0, 1)
+	if conflicts := Analyze([]scraper.Course{evening, morning}, Options{}); len(conflicts) != 0 {
+		t.Errorf("courses on different days must not conflict, got %v", conflicts)
+	}
+}
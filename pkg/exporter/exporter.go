@@ -0,0 +1,96 @@
+package exporter
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+
+	"faliactl/pkg/scraper"
+)
+
+// Exporter renders courses in one output format
+type Exporter interface {
+	// Name identifies the format for --format and is the file extension served by `serve`
+	Name() string
+	// ContentType is the media type sent by `serve`
+	ContentType() string
+	// Export writes the courses. Formats without per-event state ignore opts.State.
+	Export(courses []scraper.Course, w io.Writer, opts Options) error
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   = make(map[string]Exporter)
+)
+
+// Register makes an exporter available under its name, replacing any previous one
+func Register(e Exporter) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	registry[e.Name()] = e
+}
+
+// Lookup returns the exporter registered under name (case-insensitive)
+func Lookup(name string) (Exporter, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	e, ok := registry[strings.ToLower(name)]
+	return e, ok
+}
+
+// MustLookup is Lookup for callers that name a built-in format
+func MustLookup(name string) Exporter {
+	e, ok := Lookup(name)
+	if !ok {
+		panic(fmt.Sprintf("exporter: format %q is not registered", name))
+	}
+	return e
+}
+
+// Formats lists the registered format names alphabetically
+func Formats() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func init() {
+	Register(icsExporter{})
+	Register(jcalExporter{})
+	Register(jsonExporter{})
+	Register(csvExporter{})
+	Register(markdownExporter{})
+	Register(htmlExporter{})
+}
+
+// ExportTracked writes the courses using the named state file, so repeated exports of the same
+// calendar keep their UIDs and bump SEQUENCE only on real changes
+func ExportTracked(name string, e Exporter, courses []scraper.Course, w io.Writer, opts Options) error {
+	stateMu.Lock()
+	defer stateMu.Unlock()
+
+	state, err := LoadNamedState(name)
+	if err != nil {
+		return err
+	}
+	opts.State = state
+	if err := e.Export(courses, w, opts); err != nil {
+		return err
+	}
+	return state.Save()
+}
+
+type icsExporter struct{}
+
+func (icsExporter) Name() string        { return "ics" }
+func (icsExporter) ContentType() string { return "text/calendar; charset=utf-8" }
+func (icsExporter) Export(courses []scraper.Course, w io.Writer, opts Options) error {
+	return GenerateICSWithOptions(courses, w, opts)
+}
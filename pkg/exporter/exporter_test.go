@@ -0,0 +1,112 @@
+package exporter
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func export(t *testing.T, format string, opts Options) string {
+	t.Helper()
+	e, ok := Lookup(format)
+	if !ok {
+		t.Fatalf("format %s is not registered", format)
+	}
+	var buf bytes.Buffer
+	if err := e.Export(testCourses(), &buf, opts); err != nil {
+		t.Fatalf("%s: %v", format, err)
+	}
+	return buf.String()
+}
+
+func TestFormats(t *testing.T) {
+	want := []string{"csv", "html", "ics", "jcal", "json", "md"}
+	if got := Formats(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Formats() = %v, want %v", got, want)
+	}
+	if _, ok := Lookup("JSON"); !ok {
+		t.Error("Lookup should ignore case")
+	}
+}
+
+func TestExport_JSON(t *testing.T) {
+	var courses []CourseRecord
+	if err := json.Unmarshal([]byte(export(t, "json", Options{})), &courses); err != nil {
+		t.Fatal(err)
+	}
+	if len(courses) != 3 {
+		t.Fatalf("expected 3 courses, got %d", len(courses))
+	}
+	if courses[0].Name != "Lineare Algebra" || !courses[0].Start.Equal(time.Date(2026, 3, 4, 7, 15, 0, 0, time.UTC)) {
+		t.Errorf("unexpected first course: %+v", courses[0])
+	}
+	if !strings.HasSuffix(courses[0].UID, "@faliactl") || courses[0].Address == "" {
+		t.Errorf("expected UID and address, got %+v", courses[0])
+	}
+}
+
+func TestExport_CSV(t *testing.T) {
+	rows, err := csv.NewReader(strings.NewReader(export(t, "csv", Options{}))).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"2026-03-04", "Wednesday", "08:15", "09:45", "Lineare Algebra", "Vorlesung", "WF-EX-7/3", "WI 2. Sem."}
+	if len(rows) != 4 || !reflect.DeepEqual(rows[1], want) {
+		t.Errorf("unexpected rows: %v", rows)
+	}
+}
+
+func TestExport_Markdown(t *testing.T) {
+	out := export(t, "md", Options{})
+	for _, want := range []string{
+		"## Week 10 (02.03.2026)",
+		"| Wed 04.03. | 08:15–09:45 | Lineare Algebra | Vorlesung | WF-EX-7/3 |",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in:\n%s", want, out)
+		}
+	}
+}
+
+func TestExport_HTML(t *testing.T) {
+	out := export(t, "html", Options{})
+	if !strings.Contains(out, "<h2>Wed 04.03.</h2>") || !strings.Contains(out, `<div class="name">Programmieren 2</div>`) {
+		t.Errorf("unexpected HTML:\n%s", out)
+	}
+	if strings.Contains(out, "Sat ") || strings.Contains(out, "<link") || strings.Contains(out, "<script") {
+		t.Errorf("expected a self-contained page without empty weekend columns:\n%s", out)
+	}
+}
+
+func TestExport_JCal(t *testing.T) {
+	var cal []any
+	if err := json.Unmarshal([]byte(export(t, "jcal", Options{})), &cal); err != nil {
+		t.Fatal(err)
+	}
+	if len(cal) != 3 || cal[0] != "vcalendar" {
+		t.Fatalf("not a jCal object: %v", cal)
+	}
+	events := cal[2].([]any)
+	if len(events) != 3 {
+		t.Fatalf("expected 3 events, got %d", len(events))
+	}
+	props := events[0].([]any)[1].([]any)
+	found := map[string]any{}
+	for _, p := range props {
+		prop := p.([]any)
+		found[prop[0].(string)] = prop[3]
+	}
+	if found["dtstart"] != "2026-03-04T07:15:00Z" || found["summary"] != "Lineare Algebra" {
+		t.Errorf("unexpected properties: %v", found)
+	}
+
+	// jCal publishes the same UIDs as the ICS output
+	ics := export(t, "ics", Options{})
+	if !strings.Contains(ics, "UID:"+found["uid"].(string)) {
+		t.Errorf("UID %v missing from ICS output", found["uid"])
+	}
+}
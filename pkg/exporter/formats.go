@@ -0,0 +1,154 @@
+package exporter
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"faliactl/pkg/campus"
+	"faliactl/pkg/scraper"
+	"faliactl/pkg/timetable"
+)
+
+// CourseRecord is the typed course record of the JSON format and of the CLI's --output json.
+// Its field names are part of the documented schema and only ever get extended.
+type CourseRecord struct {
+	UID     string    `json:"uid"`
+	Name    string    `json:"name"`
+	Type    string    `json:"type"`
+	Start   time.Time `json:"start"`
+	End     time.Time `json:"end"`
+	Room    string    `json:"room"`
+	Address string    `json:"address"`
+	Groups  string    `json:"groups"`
+}
+
+// jsonExporter writes the courses as a chronological JSON array
+type jsonExporter struct{}
+
+func (jsonExporter) Name() string        { return "json" }
+func (jsonExporter) ContentType() string { return "application/json" }
+
+func (jsonExporter) Export(courses []scraper.Course, w io.Writer, opts Options) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(CourseRecords(courses))
+}
+
+// CourseRecords turns the courses into chronological records, skipping those without valid
+// times. The result is never nil, so it encodes as an empty array.
+func CourseRecords(courses []scraper.Course) []CourseRecord {
+	out := []CourseRecord{}
+	for _, ev := range buildEvents(courses) {
+		out = append(out, ev.record())
+	}
+	return out
+}
+
+func (ev courseEvent) record() CourseRecord {
+	return CourseRecord{
+		UID:     ev.uid,
+		Name:    ev.course.Name,
+		Type:    ev.course.Type,
+		Start:   ev.start,
+		End:     ev.end,
+		Room:    ev.course.Room,
+		Address: campus.Default().ForRoom(ev.course.Room).Address,
+		Groups:  ev.course.GroupStr,
+	}
+}
+
+// csvExporter writes one row per lecture for spreadsheets
+type csvExporter struct{}
+
+func (csvExporter) Name() string        { return "csv" }
+func (csvExporter) ContentType() string { return "text/csv; charset=utf-8" }
+
+func (csvExporter) Export(courses []scraper.Course, w io.Writer, opts Options) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"Date", "Weekday", "Start", "End", "Course", "Type", "Room", "Groups"})
+	for _, ev := range buildEvents(courses) {
+		cw.Write([]string{
+			ev.start.Format("2006-01-02"),
+			ev.start.Weekday().String(),
+			ev.start.Format("15:04"),
+			ev.end.Format("15:04"),
+			ev.course.Name,
+			ev.course.Type,
+			ev.course.Room,
+			ev.course.GroupStr,
+		})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// week holds the lectures of one Monday-to-Sunday week
+type week struct {
+	Monday time.Time
+	Days   [7][]courseEvent // Index 0 is Monday
+}
+
+// Number returns the ISO week number
+func (w week) Number() int {
+	_, n := w.Monday.ISOWeek()
+	return n
+}
+
+// weeks groups the chronologically sorted events into calendar weeks
+func weeks(events []courseEvent) []*week {
+	var out []*week
+	for _, ev := range events {
+		day := time.Date(ev.start.Year(), ev.start.Month(), ev.start.Day(), 0, 0, 0, 0, scraper.Berlin)
+		offset := (int(day.Weekday()) + 6) % 7
+		monday := day.AddDate(0, 0, -offset)
+		if len(out) == 0 || !out[len(out)-1].Monday.Equal(monday) {
+			out = append(out, &week{Monday: monday})
+		}
+		out[len(out)-1].Days[offset] = append(out[len(out)-1].Days[offset], ev)
+	}
+	return out
+}
+
+// markdownExporter writes one table per week, ready to paste into a wiki
+type markdownExporter struct{}
+
+func (markdownExporter) Name() string        { return "md" }
+func (markdownExporter) ContentType() string { return "text/markdown; charset=utf-8" }
+
+func (markdownExporter) Export(courses []scraper.Course, w io.Writer, opts Options) error {
+	cell := strings.NewReplacer("|", `\|`, "\n", " ")
+	for i, wk := range weeks(buildEvents(courses)) {
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+		fmt.Fprintf(w, "## Week %d (%s)\n\n", wk.Number(), wk.Monday.Format("02.01.2006"))
+		fmt.Fprintln(w, "| Day | Time | Course | Type | Room |")
+		fmt.Fprintln(w, "| --- | --- | --- | --- | --- |")
+		for _, day := range wk.Days {
+			for _, ev := range day {
+				fmt.Fprintf(w, "| %s | %s–%s | %s | %s | %s |\n",
+					ev.start.Format("Mon 02.01."), ev.start.Format("15:04"), ev.end.Format("15:04"),
+					cell.Replace(ev.course.Name), cell.Replace(ev.course.Type), cell.Replace(ev.course.Room))
+			}
+		}
+	}
+	return nil
+}
+
+// htmlExporter writes the print-ready week grids of pkg/timetable as a self-contained page
+type htmlExporter struct{}
+
+func (htmlExporter) Name() string        { return "html" }
+func (htmlExporter) ContentType() string { return "text/html; charset=utf-8" }
+
+func (htmlExporter) Export(courses []scraper.Course, w io.Writer, opts Options) error {
+	var grids []*timetable.Grid
+	for _, wk := range weeks(buildEvents(courses)) {
+		grids = append(grids, timetable.Build(courses, wk.Monday))
+	}
+	return timetable.WriteHTML(w, "Timetable", grids)
+}
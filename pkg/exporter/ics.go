@@ -1,64 +1,197 @@
 package exporter
 
 import (
+	"crypto/sha1"
+	"encoding/hex"
 	"fmt"
 	"io"
+	"sort"
 	"strings"
 	"time"
 
+	"faliactl/pkg/campus"
 	"faliactl/pkg/scraper"
 
 	ics "github.com/arran4/golang-ical"
 )
 
+// DefaultCancelGrace is how long a lecture that vanished from the schedule keeps being
+// published as STATUS:CANCELLED, giving subscribers time to pick up the cancellation
+const DefaultCancelGrace = 14 * 24 * time.Hour
+
+// Options controls GenerateICSWithOptions and the other exporters
+type Options struct {
+	// State tracks SEQUENCE and LAST-MODIFIED across runs. Without it every event is
+	// emitted at SEQUENCE 0 with the current time as its modification date.
+	State *State
+	// Now is the modification time recorded for new or changed events (defaults to time.Now())
+	Now time.Time
+	// CancelGrace is how long removed events are emitted as cancelled. Zero means
+	// DefaultCancelGrace, a negative value disables cancellations. Requires State.
+	CancelGrace time.Duration
+	// Partial marks the course list as possibly incomplete, e.g. because one group of a set
+	// failed to load. Events missing from it are then re-published exactly as last time
+	// instead of being cancelled or dropped. Requires State.
+	Partial bool
+}
+
 // GenerateICS creates an ICS file from the slice of courses and writes it to the provided writer
 func GenerateICS(courses []scraper.Course, w io.Writer) error {
+	return GenerateICSWithOptions(courses, w, Options{})
+}
+
+// GenerateICSWithOptions is GenerateICS with persistent SEQUENCE/LAST-MODIFIED tracking.
+// The caller is responsible for saving opts.State afterwards.
+func GenerateICSWithOptions(courses []scraper.Course, w io.Writer, opts Options) error {
 	cal := ics.NewCalendar()
 	cal.SetMethod(ics.MethodPublish)
 
-	// Timezone location for Germany
-	loc, err := time.LoadLocation("Europe/Berlin")
-	if err != nil {
-		return fmt.Errorf("could not load timezone: %w", err)
+	for _, ev := range publishedEvents(courses, opts) {
+		event := addEvent(cal, ev.uid, ev.EventState)
+		if ev.cancelled {
+			event.SetStatus(ics.ObjectStatusCancelled)
+		}
 	}
 
-	for i, c := range courses {
-		// c.DateStr example: "04.03.2026 (Mittwoch)"
-		dateParts := strings.Split(c.DateStr, " ")
-		if len(dateParts) == 0 {
-			continue // Skip malformed dates
+	return cal.SerializeTo(w)
+}
+
+// publishedEvent is one VEVENT as it is published: a current lecture or a cancelled one
+type publishedEvent struct {
+	EventState
+	uid       string
+	cancelled bool
+}
+
+// publishedEvents turns the courses into calendar events and, with a State, appends the
+// lectures that vanished within the grace period as cancelled. Shared by ICS and jCal.
+func publishedEvents(courses []scraper.Course, opts Options) []publishedEvent {
+	now := opts.Now
+	if now.IsZero() {
+		now = time.Now()
+	}
+
+	var events []publishedEvent
+	seen := make(map[string]bool)
+	for _, ev := range buildEvents(courses) {
+		c := ev.course
+
+		fullAddress := campus.Default().ForRoom(c.Room).Address
+		rec := EventState{
+			Summary:     c.Name,
+			Location:    fmt.Sprintf("%s, %s", c.Room, fullAddress),
+			Description: fmt.Sprintf("Type: %s\nGroup: %s", c.Type, c.GroupStr),
+			Start:       ev.start,
+			End:         ev.end,
+		}
+		if opts.State != nil {
+			rec = opts.State.track(ev.uid, rec, now)
+		} else {
+			rec.Created, rec.LastModified = now, now
 		}
-		cleanDate := dateParts[0]
 
-		startStr := fmt.Sprintf("%s %s", cleanDate, c.StartTime)
-		endStr := fmt.Sprintf("%s %s", cleanDate, c.EndTime)
+		events = append(events, publishedEvent{EventState: rec, uid: ev.uid})
+		seen[ev.uid] = true
+	}
 
-		layout := "02.01.2006 15:04"
+	if opts.State == nil {
+		return events
+	}
 
-		startTime, err := time.ParseInLocation(layout, startStr, loc)
-		if err != nil {
-			continue // Skip invalid times
+	// The lectures of a group that failed to load would otherwise vanish from subscribed
+	// calendars until the next complete run, so their last publication is repeated as is
+	if opts.Partial {
+		unseen := opts.State.unseen(seen)
+		for _, uid := range sortedByStart(unseen) {
+			rec := unseen[uid]
+			events = append(events, publishedEvent{EventState: rec, uid: uid, cancelled: rec.RemovedAt != nil})
 		}
+		return events
+	}
 
-		endTime, err := time.ParseInLocation(layout, endStr, loc)
+	grace := opts.CancelGrace
+	if grace == 0 {
+		grace = DefaultCancelGrace
+	}
+	cancelled := opts.State.cancelled(seen, now, grace)
+	for _, uid := range sortedByStart(cancelled) {
+		events = append(events, publishedEvent{EventState: cancelled[uid], uid: uid, cancelled: true})
+	}
+	return events
+}
+
+// sortedByStart returns the UIDs of the records in chronological order
+func sortedByStart(records map[string]EventState) []string {
+	uids := make([]string, 0, len(records))
+	for uid := range records {
+		uids = append(uids, uid)
+	}
+	sort.Slice(uids, func(i, j int) bool {
+		return records[uids[i]].Start.Before(records[uids[j]].Start)
+	})
+	return uids
+}
+
+func addEvent(cal *ics.Calendar, uid string, rec EventState) *ics.VEvent {
+	event := cal.AddEvent(uid)
+	event.SetCreatedTime(rec.Created)
+	event.SetDtStampTime(rec.LastModified)
+	event.SetModifiedAt(rec.LastModified)
+	event.SetSequence(rec.Sequence)
+	event.SetStartAt(rec.Start)
+	event.SetEndAt(rec.End)
+	event.SetSummary(rec.Summary)
+	event.SetLocation(rec.Location)
+	event.SetDescription(rec.Description)
+	return event
+}
+
+type courseEvent struct {
+	uid        string
+	course     scraper.Course
+	start, end time.Time
+}
+
+// buildEvents sorts the usable courses chronologically and assigns each one its stable UID
+func buildEvents(courses []scraper.Course) []courseEvent {
+	var events []courseEvent
+	for _, c := range courses {
+		start, end, err := c.Times()
 		if err != nil {
-			continue
+			continue // Skip courses without a usable date or time
 		}
+		events = append(events, courseEvent{course: c, start: start, end: end})
+	}
 
-		event := cal.AddEvent(fmt.Sprintf("%s-%d", startTime.Format("20060102T150405Z"), i))
-		event.SetCreatedTime(time.Now())
-		event.SetDtStampTime(time.Now())
-		event.SetModifiedAt(time.Now())
-		event.SetStartAt(startTime)
-		event.SetEndAt(endTime)
-		event.SetSummary(c.Name)
-
-		fullAddress := scraper.GetCampusAddress(c.Room)
-		event.SetLocation(fmt.Sprintf("%s, %s", c.Room, fullAddress))
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].start.Before(events[j].start)
+	})
 
-		description := fmt.Sprintf("Type: %s\nGroup: %s", c.Type, c.GroupStr)
-		event.SetDescription(description)
+	ordinals := make(map[string]int)
+	for i := range events {
+		identity := courseIdentity(events[i].course, events[i].start)
+		events[i].uid = eventUID(identity, ordinals[identity])
+		ordinals[identity]++
 	}
+	return events
+}
 
-	return cal.SerializeTo(w)
+// courseIdentity is what makes a lecture "the same event" across regenerations: its group,
+// name and type on a given day. The time and room are deliberately left out so that a
+// lecture moved within the day, or to another room, keeps its UID and shows up as an update.
+func courseIdentity(c scraper.Course, start time.Time) string {
+	return strings.Join([]string{c.GroupStr, c.Name, c.Type, start.In(scraper.Berlin).Format("2006-01-02")}, "|")
+}
+
+// eventUID hashes the identity plus its ordinal among same-identity courses on that day
+func eventUID(identity string, ordinal int) string {
+	sum := sha1.Sum([]byte(fmt.Sprintf("%s|%d", identity, ordinal)))
+	return hex.EncodeToString(sum[:10]) + "@faliactl"
+}
+
+func contentHash(summary, location, description string, start, end time.Time) string {
+	sum := sha1.Sum([]byte(strings.Join([]string{
+		summary, location, description, start.UTC().Format(time.RFC3339), end.UTC().Format(time.RFC3339),
+	}, "\x00")))
+	return hex.EncodeToString(sum[:])
 }
@@ -2,8 +2,10 @@ package exporter
 
 import (
 	"bytes"
+	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"faliactl/pkg/scraper"
 )
@@ -42,3 +44,243 @@ func TestGenerateICS(t *testing.T) {
 		t.Errorf("Expected start time string in ICS (should be UTC), got: \n%s", output)
 	}
 }
+
+func testCourses() []scraper.Course {
+	return []scraper.Course{
+		{Name: "Lineare Algebra", Type: "Vorlesung", DateStr: "04.03.2026 (Mittwoch)", StartTime: "08:15", EndTime: "09:45", Room: "WF-EX-7/3", GroupStr: "WI 2. Sem."},
+		{Name: "Programmieren 2", Type: "Übung", DateStr: "04.03.2026 (Mittwoch)", StartTime: "10:00", EndTime: "11:30", Room: "WF-EX-2/127", GroupStr: "WI 2. Sem."},
+		{Name: "Programmieren 2", Type: "Übung", DateStr: "04.03.2026 (Mittwoch)", StartTime: "12:00", EndTime: "13:30", Room: "WF-EX-2/127", GroupStr: "WI 2. Sem."},
+	}
+}
+
+func eventsByUID(t *testing.T, ics string) map[string]string {
+	t.Helper()
+	events := make(map[string]string)
+	for _, block := range strings.Split(ics, "BEGIN:VEVENT")[1:] {
+		block, _, _ = strings.Cut(block, "END:VEVENT")
+		for _, line := range strings.Split(block, "\n") {
+			if strings.HasPrefix(line, "UID:") {
+				events[strings.TrimPrefix(line, "UID:")] = block
+			}
+		}
+	}
+	return events
+}
+
+func TestGenerateICS_StableUIDs(t *testing.T) {
+	courses := testCourses()
+
+	var first, second bytes.Buffer
+	if err := GenerateICS(courses, &first); err != nil {
+		t.Fatal(err)
+	}
+	// Reordering the input or inserting an unrelated course must not reshuffle UIDs
+	reordered := append([]scraper.Course{
+		{Name: "Mathe 1", Type: "Vorlesung", DateStr: "03.03.2026 (Dienstag)", StartTime: "08:15", EndTime: "09:45", GroupStr: "WI 2. Sem."},
+	}, courses[2], courses[0], courses[1])
+	if err := GenerateICS(reordered, &second); err != nil {
+		t.Fatal(err)
+	}
+
+	a, b := eventsByUID(t, first.String()), eventsByUID(t, second.String())
+	if len(a) != 3 || len(b) != 4 {
+		t.Fatalf("expected 3 and 4 distinct UIDs, got %d and %d", len(a), len(b))
+	}
+	for uid := range a {
+		if _, ok := b[uid]; !ok {
+			t.Errorf("UID %s disappeared after reordering", uid)
+		}
+	}
+}
+
+func TestGenerateICSWithOptions_Sequence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	day1 := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	day2 := day1.Add(24 * time.Hour)
+
+	state, err := LoadState(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := GenerateICSWithOptions(testCourses(), &buf, Options{State: state, Now: day1}); err != nil {
+		t.Fatal(err)
+	}
+	if err := state.Save(); err != nil {
+		t.Fatal(err)
+	}
+	before := eventsByUID(t, buf.String())
+
+	// The lecture moves to another room and later slot on the same day
+	moved := testCourses()
+	moved[0].StartTime, moved[0].EndTime, moved[0].Room = "14:00", "15:30", "WF-C-015"
+
+	state, err = LoadState(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	buf.Reset()
+	if err := GenerateICSWithOptions(moved, &buf, Options{State: state, Now: day2}); err != nil {
+		t.Fatal(err)
+	}
+	after := eventsByUID(t, buf.String())
+
+	changed := 0
+	for uid, ev := range after {
+		old, ok := before[uid]
+		if !ok {
+			t.Fatalf("moved lecture got a new UID %s instead of an update", uid)
+		}
+		if strings.Contains(ev, "LOCATION:WF-C-015") {
+			changed++
+			if !strings.Contains(ev, "SEQUENCE:1") || !strings.Contains(ev, "LAST-MODIFIED:20260302T120000Z") {
+				t.Errorf("moved event should have SEQUENCE:1 and a new LAST-MODIFIED, got:\n%s", ev)
+			}
+			if !strings.Contains(ev, "CREATED:20260301T120000Z") {
+				t.Errorf("CREATED must be kept across updates, got:\n%s", ev)
+			}
+			continue
+		}
+		if ev != old {
+			t.Errorf("unchanged event %s was re-emitted differently:\n%s\nvs\n%s", uid, old, ev)
+		}
+	}
+	if changed != 1 {
+		t.Errorf("expected exactly one updated event, got %d", changed)
+	}
+}
+
+func TestLoadState_Missing(t *testing.T) {
+	state, err := LoadState(filepath.Join(t.TempDir(), "missing.json"))
+	if err != nil {
+		t.Fatalf("missing state file should not be an error: %v", err)
+	}
+	if len(state.Events) != 0 {
+		t.Errorf("expected empty state, got %d events", len(state.Events))
+	}
+}
+
+func generateAt(t *testing.T, path string, courses []scraper.Course, opts Options) map[string]string {
+	t.Helper()
+	state, err := LoadState(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts.State = state
+
+	var buf bytes.Buffer
+	if err := GenerateICSWithOptions(courses, &buf, opts); err != nil {
+		t.Fatal(err)
+	}
+	if err := state.Save(); err != nil {
+		t.Fatal(err)
+	}
+	return eventsByUID(t, buf.String())
+}
+
+func TestGenerateICSWithOptions_Cancelled(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	day := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	before := generateAt(t, path, testCourses(), Options{Now: day})
+
+	// The second Programmieren 2 session disappears from the schedule
+	after := generateAt(t, path, testCourses()[:2], Options{Now: day.Add(24 * time.Hour)})
+	if len(after) != 3 {
+		t.Fatalf("expected the removed lecture to still be published, got %d events", len(after))
+	}
+
+	cancelled := 0
+	for uid, ev := range after {
+		if _, ok := before[uid]; !ok {
+			t.Errorf("unexpected new UID %s", uid)
+		}
+		if strings.Contains(ev, "STATUS:CANCELLED") {
+			cancelled++
+			if !strings.Contains(ev, "SEQUENCE:1") || !strings.Contains(ev, "DTSTART:20260304T110000Z") {
+				t.Errorf("cancelled event should keep its content and bump SEQUENCE, got:\n%s", ev)
+			}
+		}
+	}
+	if cancelled != 1 {
+		t.Fatalf("expected 1 cancelled event, got %d", cancelled)
+	}
+
+	// A partial fetch re-publishes the missing lectures exactly as before: no new SEQUENCE,
+	// no cancellation, and the already cancelled one stays cancelled
+	partial := generateAt(t, path, testCourses()[:1], Options{Now: day.Add(48 * time.Hour), Partial: true})
+	if len(partial) != 3 {
+		t.Fatalf("partial export should keep every published event, got %d", len(partial))
+	}
+	for uid, ev := range partial {
+		if ev != after[uid] {
+			t.Errorf("partial export changed event %s:\n%s\nwas:\n%s", uid, ev, after[uid])
+		}
+	}
+
+	// After the grace period the cancelled event is dropped entirely
+	later := generateAt(t, path, testCourses()[:2], Options{Now: day.Add(20 * 24 * time.Hour)})
+	if len(later) != 2 {
+		t.Errorf("expected the cancelled event to expire after the grace period, got %d events", len(later))
+	}
+}
+
+func TestGenerateICSWithOptions_Reinstated(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	day := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	generateAt(t, path, testCourses(), Options{Now: day})
+	generateAt(t, path, testCourses()[:2], Options{Now: day.Add(time.Hour)})
+	back := generateAt(t, path, testCourses(), Options{Now: day.Add(2 * time.Hour)})
+
+	for uid, ev := range back {
+		if strings.Contains(ev, "STATUS:CANCELLED") {
+			t.Errorf("reinstated event %s is still cancelled", uid)
+		}
+		if strings.Contains(ev, "DTSTART:20260304T110000Z") && !strings.Contains(ev, "SEQUENCE:2") {
+			t.Errorf("reinstated event should be at SEQUENCE:2, got:\n%s", ev)
+		}
+	}
+}
+
+func TestGenerateICSWithOptions_PastEventsAreNotCancelled(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	day := time.Date(2026, 3, 4, 7, 0, 0, 0, time.UTC) // Before the first lecture
+
+	generateAt(t, path, testCourses(), Options{Now: day})
+
+	// By 11:00 the first lecture is over and drops off the page, while the
+	// last one of the day vanishes before it took place
+	courses := testCourses()
+	after := generateAt(t, path, courses[1:2], Options{Now: day.Add(3 * time.Hour)})
+	if len(after) != 2 {
+		t.Fatalf("expected the current and the cancelled lecture, got %d events", len(after))
+	}
+	for _, ev := range after {
+		if strings.Contains(ev, "DTSTART:20260304T071500Z") {
+			t.Errorf("a past lecture must not be published as cancelled:\n%s", ev)
+		}
+		if strings.Contains(ev, "DTSTART:20260304T110000Z") && !strings.Contains(ev, "STATUS:CANCELLED") {
+			t.Errorf("a future lecture that vanished should be cancelled:\n%s", ev)
+		}
+	}
+
+	state, err := LoadState(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(state.Events) != 2 {
+		t.Errorf("expected the past lecture to be forgotten, %d events left in the state", len(state.Events))
+	}
+}
+
+func TestGenerateICSWithOptions_CancelDisabled(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	day := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	generateAt(t, path, testCourses(), Options{Now: day, CancelGrace: -1})
+	after := generateAt(t, path, testCourses()[:2], Options{Now: day.Add(time.Hour), CancelGrace: -1})
+	if len(after) != 2 {
+		t.Errorf("expected removed lectures to be dropped when cancellations are disabled, got %d events", len(after))
+	}
+}
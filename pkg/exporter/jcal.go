@@ -0,0 +1,60 @@
+package exporter
+
+import (
+	"encoding/json"
+	"io"
+	"time"
+
+	"faliactl/pkg/scraper"
+)
+
+// jcalExporter writes RFC 7265 jCal, the JSON mapping of iCalendar. It publishes exactly the
+// events of the ICS output, including UIDs, SEQUENCE and cancellations.
+type jcalExporter struct{}
+
+func (jcalExporter) Name() string        { return "jcal" }
+func (jcalExporter) ContentType() string { return "application/calendar+json" }
+
+func (jcalExporter) Export(courses []scraper.Course, w io.Writer, opts Options) error {
+	var components []any
+	for _, ev := range publishedEvents(courses, opts) {
+		props := []any{
+			jcalProp("uid", "text", ev.uid),
+			jcalProp("dtstamp", "date-time", jcalTime(ev.LastModified)),
+			jcalProp("created", "date-time", jcalTime(ev.Created)),
+			jcalProp("last-modified", "date-time", jcalTime(ev.LastModified)),
+			jcalProp("sequence", "integer", ev.Sequence),
+			jcalProp("dtstart", "date-time", jcalTime(ev.Start)),
+			jcalProp("dtend", "date-time", jcalTime(ev.End)),
+			jcalProp("summary", "text", ev.Summary),
+			jcalProp("location", "text", ev.Location),
+			jcalProp("description", "text", ev.Description),
+		}
+		if ev.cancelled {
+			props = append(props, jcalProp("status", "text", "CANCELLED"))
+		}
+		components = append(components, []any{"vevent", props, []any{}})
+	}
+	if components == nil {
+		components = []any{}
+	}
+
+	cal := []any{"vcalendar", []any{
+		jcalProp("version", "text", "2.0"),
+		jcalProp("prodid", "text", "-//faliactl//jCal//EN"),
+		jcalProp("method", "text", "PUBLISH"),
+	}, components}
+
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	return enc.Encode(cal)
+}
+
+func jcalProp(name, valueType string, value any) []any {
+	return []any{name, map[string]any{}, valueType, value}
+}
+
+// jcalTime formats a UTC date-time as RFC 7265 section 3.5.5 requires
+func jcalTime(t time.Time) string {
+	return t.UTC().Format("2006-01-02T15:04:05Z")
+}
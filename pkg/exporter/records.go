@@ -0,0 +1,72 @@
+package exporter
+
+import (
+	"faliactl/pkg/conflict"
+	"faliactl/pkg/scraper"
+)
+
+// NewCourseRecord turns a single course into its record. The UID is the one the course gets
+// as the first lecture of its kind that day; courses without valid times keep Start and End.
+func NewCourseRecord(c scraper.Course) CourseRecord {
+	ev := courseEvent{course: c, start: c.Start, end: c.End}
+	if start, end, err := c.Times(); err == nil {
+		ev.start, ev.end = start, end
+	}
+	ev.uid = eventUID(courseIdentity(c, ev.start), 0)
+	return ev.record()
+}
+
+// ChangeRecord is one schedule change of `diff --format json` and `diff --output json`
+type ChangeRecord struct {
+	Kind   scraper.ChangeKind `json:"kind"`
+	Old    *CourseRecord      `json:"old,omitempty"`
+	New    *CourseRecord      `json:"new,omitempty"`
+	Fields []string           `json:"fields,omitempty"`
+}
+
+// ChangeRecords maps the changes to records. The result is never nil.
+func ChangeRecords(changes []scraper.Change) []ChangeRecord {
+	out := []ChangeRecord{}
+	for _, c := range changes {
+		rec := ChangeRecord{Kind: c.Kind, Fields: c.Fields}
+		if c.Old != nil {
+			old := NewCourseRecord(*c.Old)
+			rec.Old = &old
+		}
+		if c.New != nil {
+			n := NewCourseRecord(*c.New)
+			rec.New = &n
+		}
+		out = append(out, rec)
+	}
+	return out
+}
+
+// ConflictRecord is one conflict of `conflicts --format json` and `conflicts --output json`.
+// Durations are whole minutes.
+type ConflictRecord struct {
+	Kind            conflict.Kind `json:"kind"`
+	First           CourseRecord  `json:"first"`
+	Second          CourseRecord  `json:"second"`
+	DurationMinutes int           `json:"duration_minutes"`
+	TravelMinutes   int           `json:"travel_minutes,omitempty"`
+	From            string        `json:"from,omitempty"`
+	To              string        `json:"to,omitempty"`
+}
+
+// ConflictRecords maps the conflicts to records. The result is never nil.
+func ConflictRecords(conflicts []conflict.Conflict) []ConflictRecord {
+	out := []ConflictRecord{}
+	for _, c := range conflicts {
+		out = append(out, ConflictRecord{
+			Kind:            c.Kind,
+			First:           NewCourseRecord(c.First),
+			Second:          NewCourseRecord(c.Second),
+			DurationMinutes: int(c.Duration.Minutes()),
+			TravelMinutes:   int(c.Travel.Minutes()),
+			From:            c.From,
+			To:              c.To,
+		})
+	}
+	return out
+}
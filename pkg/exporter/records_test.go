@@ -0,0 +1,80 @@
+package exporter
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"faliactl/pkg/conflict"
+	"faliactl/pkg/scraper"
+)
+
+// jsonKeys encodes v and returns the sorted keys of the resulting object
+func jsonKeys(t *testing.T, v any) []string {
+	t.Helper()
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var obj map[string]json.RawMessage
+	if err := json.Unmarshal(data, &obj); err != nil {
+		t.Fatal(err)
+	}
+	keys := make([]string, 0, len(obj))
+	for k := range obj {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+var courseRecordKeys = []string{"address", "end", "groups", "name", "room", "start", "type", "uid"}
+
+func TestChangeRecords_JSONKeys(t *testing.T) {
+	courses := testCourses()
+	moved := courses[0]
+	moved.Room = "WF-EX-2/127"
+	records := ChangeRecords(scraper.DiffSchedules(courses[:1], []scraper.Course{moved}))
+	if len(records) != 1 {
+		t.Fatalf("expected one change, got %+v", records)
+	}
+
+	if got, want := jsonKeys(t, records[0]), []string{"fields", "kind", "new", "old"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("change keys = %v, want %v", got, want)
+	}
+	if got := jsonKeys(t, records[0].New); !reflect.DeepEqual(got, courseRecordKeys) {
+		t.Errorf("course keys = %v, want %v", got, courseRecordKeys)
+	}
+	if records[0].Old.UID != records[0].New.UID || records[0].New.Room != "WF-EX-2/127" {
+		t.Errorf("a moved lecture should keep its UID: %+v", records[0])
+	}
+	if empty, _ := json.Marshal(ChangeRecords(nil)); string(empty) != "[]" {
+		t.Errorf("no changes should encode as [], got %s", empty)
+	}
+}
+
+func TestConflictRecords_JSONKeys(t *testing.T) {
+	c := conflict.Conflict{
+		Kind:     conflict.KindTravel,
+		First:    testCourses()[0],
+		Second:   testCourses()[1],
+		Duration: 15 * time.Minute,
+		Travel:   55 * time.Minute,
+		From:     "Exer Süd",
+		To:       "Ostfalia Salzgitter",
+	}
+	records := ConflictRecords([]conflict.Conflict{c})
+
+	want := []string{"duration_minutes", "first", "from", "kind", "second", "to", "travel_minutes"}
+	if got := jsonKeys(t, records[0]); !reflect.DeepEqual(got, want) {
+		t.Errorf("conflict keys = %v, want %v", got, want)
+	}
+	if got := jsonKeys(t, records[0].First); !reflect.DeepEqual(got, courseRecordKeys) {
+		t.Errorf("course keys = %v, want %v", got, courseRecordKeys)
+	}
+	if records[0].DurationMinutes != 15 || records[0].TravelMinutes != 55 {
+		t.Errorf("durations should be minutes, got %+v", records[0])
+	}
+}
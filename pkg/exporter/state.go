@@ -0,0 +1,179 @@
+package exporter
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sync"
+	"time"
+
+	"faliactl/pkg/scraper"
+)
+
+// State remembers what was last published for every event UID of one calendar,
+// so SEQUENCE and LAST-MODIFIED only change when an event actually changes
+type State struct {
+	Events map[string]*EventState `json:"events"`
+
+	path string
+}
+
+// EventState is the persisted publication record of a single event. The published content
+// is kept so the event can still be re-emitted as cancelled after it left the schedule.
+type EventState struct {
+	Hash         string     `json:"hash"`
+	Sequence     int        `json:"sequence"`
+	Created      time.Time  `json:"created"`
+	LastModified time.Time  `json:"last_modified"`
+	RemovedAt    *time.Time `json:"removed_at,omitempty"`
+
+	Summary     string    `json:"summary"`
+	Location    string    `json:"location"`
+	Description string    `json:"description"`
+	Start       time.Time `json:"start"`
+	End         time.Time `json:"end"`
+}
+
+var unsafeStateChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// StatePath returns the state file for a named calendar (a group, set or output file)
+// inside the faliactl cache directory
+func StatePath(name string) (string, error) {
+	cacheDir, err := scraper.CacheDir()
+	if err != nil {
+		return "", err
+	}
+
+	dir := filepath.Join(cacheDir, "ics")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("could not create ICS state directory: %w", err)
+	}
+	return filepath.Join(dir, unsafeStateChars.ReplaceAllString(name, "_")+".json"), nil
+}
+
+// LoadState reads the state file at path. A missing file yields an empty state.
+func LoadState(path string) (*State, error) {
+	state := &State{Events: make(map[string]*EventState), path: path}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return state, nil
+		}
+		return nil, fmt.Errorf("failed to read ICS state: %w", err)
+	}
+
+	if err := json.Unmarshal(data, state); err != nil {
+		return nil, fmt.Errorf("failed to parse ICS state %s: %w", path, err)
+	}
+	if state.Events == nil {
+		state.Events = make(map[string]*EventState)
+	}
+	return state, nil
+}
+
+// LoadNamedState is shorthand for LoadState(StatePath(name))
+func LoadNamedState(name string) (*State, error) {
+	path, err := StatePath(name)
+	if err != nil {
+		return nil, err
+	}
+	return LoadState(path)
+}
+
+// Save writes the state back to the file it was loaded from
+func (s *State) Save() error {
+	if s.path == "" {
+		return fmt.Errorf("ICS state has no file path")
+	}
+
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to serialize ICS state: %w", err)
+	}
+
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("failed to write ICS state: %w", err)
+	}
+	return os.Rename(tmp, s.path)
+}
+
+// track records the current content of an event and returns its publication record.
+// New events start at SEQUENCE 0; changed or reinstated events get the next sequence
+// and a new LAST-MODIFIED.
+func (s *State) track(uid string, content EventState, now time.Time) EventState {
+	content.Hash = contentHash(content.Summary, content.Location, content.Description, content.Start, content.End)
+
+	rec, ok := s.Events[uid]
+	if !ok {
+		content.Created, content.LastModified = now, now
+		s.Events[uid] = &content
+		return content
+	}
+
+	if rec.Hash != content.Hash || rec.RemovedAt != nil {
+		content.Created = rec.Created
+		content.Sequence = rec.Sequence + 1
+		content.LastModified = now
+		*rec = content
+	}
+	return *rec
+}
+
+// cancelled marks every event that is not in seen as removed and returns the ones that are
+// still inside the grace period, sorted by start. Events removed longer ago than grace are
+// forgotten. A negative grace disables cancellations altogether. Lectures that are already
+// over simply dropped off the intranet page, so they are forgotten instead of cancelled.
+func (s *State) cancelled(seen map[string]bool, now time.Time, grace time.Duration) map[string]EventState {
+	out := make(map[string]EventState)
+	for uid, rec := range s.Events {
+		if seen[uid] {
+			continue
+		}
+		if grace < 0 || rec.Start.IsZero() {
+			delete(s.Events, uid)
+			continue
+		}
+		if rec.RemovedAt == nil && rec.End.Before(now) {
+			delete(s.Events, uid)
+			continue
+		}
+		if rec.RemovedAt == nil {
+			removed := now
+			rec.RemovedAt = &removed
+			rec.Sequence++
+			rec.LastModified = now
+		}
+		if now.Sub(*rec.RemovedAt) > grace {
+			delete(s.Events, uid)
+			continue
+		}
+		out[uid] = *rec
+	}
+	return out
+}
+
+// unseen returns the last publication of every event that is not in seen, leaving the
+// records untouched. Records without content cannot be re-published and are skipped.
+func (s *State) unseen(seen map[string]bool) map[string]EventState {
+	out := make(map[string]EventState)
+	for uid, rec := range s.Events {
+		if !seen[uid] && !rec.Start.IsZero() {
+			out[uid] = *rec
+		}
+	}
+	return out
+}
+
+// stateMu serializes load/generate/save cycles, e.g. concurrent requests in serve
+var stateMu sync.Mutex
+
+// GenerateTrackedICS writes the calendar using the named state file, so repeated
+// exports of the same calendar keep their UIDs and bump SEQUENCE only on real changes
+func GenerateTrackedICS(name string, courses []scraper.Course, w io.Writer, opts Options) error {
+	return ExportTracked(name, icsExporter{}, courses, w, opts)
+}
@@ -0,0 +1,245 @@
+package filter
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"faliactl/pkg/campus"
+	"faliactl/pkg/scraper"
+)
+
+// Filter selects and renames courses. It is stored as JSON inside sets and the config file:
+//
+//	{
+//	  "include": [{"name": "Programmieren", "type": "Übung.*B"}],
+//	  "exclude": [{"type": "Labor", "weekdays": ["fri"]}],
+//	  "from": "2026-03-01",
+//	  "rename": [{"match": "^Programmieren (\\d)$", "to": "Prog $1"}],
+//	  "aliases": {"Lineare Algebra": "LinA"}
+//	}
+//
+// A course is kept when it lies inside the date window, matches at least one include rule
+// (or there are none) and matches no exclude rule. Renames and aliases are applied afterwards.
+type Filter struct {
+	Include []Match           `json:"include,omitempty"`
+	Exclude []Match           `json:"exclude,omitempty"`
+	From    string            `json:"from,omitempty"` // First day to keep, YYYY-MM-DD
+	To      string            `json:"to,omitempty"`   // Last day to keep, YYYY-MM-DD
+	Rename  []Rename          `json:"rename,omitempty"`
+	Aliases map[string]string `json:"aliases,omitempty"` // Exact course name -> display name
+}
+
+// Match is a single rule. All given conditions must hold; empty conditions are ignored.
+type Match struct {
+	Name     string   `json:"name,omitempty"`     // Regular expression on the course name
+	Type     string   `json:"type,omitempty"`     // Regular expression on the course type, e.g. "Vorlesung", "Übung", "Labor"
+	Room     string   `json:"room,omitempty"`     // Room prefix, e.g. "WF-EX"
+	Campus   string   `json:"campus,omitempty"`   // Campus ID, alias or city, e.g. wf-exer or salzgitter
+	Weekdays []string `json:"weekdays,omitempty"` // e.g. "mon", "Dienstag", "friday"
+}
+
+// Rename rewrites course names matching a regular expression; To may reference groups as $1
+type Rename struct {
+	Match string `json:"match"`
+	To    string `json:"to"`
+}
+
+// Empty reports whether the filter keeps every course unchanged
+func (f *Filter) Empty() bool {
+	return f == nil || (len(f.Include) == 0 && len(f.Exclude) == 0 && f.From == "" && f.To == "" &&
+		len(f.Rename) == 0 && len(f.Aliases) == 0)
+}
+
+// Compiled is a validated filter ready to be applied
+type Compiled struct {
+	include []compiledMatch
+	exclude []compiledMatch
+	from    time.Time
+	to      time.Time // Exclusive: midnight after the last day
+	rename  []compiledRename
+	aliases map[string]string
+}
+
+type compiledMatch struct {
+	name, typ *regexp.Regexp
+	room      string
+	campus    string
+	weekdays  map[time.Weekday]bool
+}
+
+type compiledRename struct {
+	re *regexp.Regexp
+	to string
+}
+
+// Compile validates the filter. A nil filter compiles to one that keeps everything.
+func (f *Filter) Compile() (*Compiled, error) {
+	c := &Compiled{}
+	if f == nil {
+		return c, nil
+	}
+
+	var err error
+	if c.include, err = compileMatches("include", f.Include); err != nil {
+		return nil, err
+	}
+	if c.exclude, err = compileMatches("exclude", f.Exclude); err != nil {
+		return nil, err
+	}
+
+	if f.From != "" {
+		if c.from, err = time.ParseInLocation("2006-01-02", f.From, scraper.Berlin); err != nil {
+			return nil, fmt.Errorf("from: expected YYYY-MM-DD, got %q", f.From)
+		}
+	}
+	if f.To != "" {
+		to, err := time.ParseInLocation("2006-01-02", f.To, scraper.Berlin)
+		if err != nil {
+			return nil, fmt.Errorf("to: expected YYYY-MM-DD, got %q", f.To)
+		}
+		c.to = to.AddDate(0, 0, 1)
+		if !c.from.IsZero() && !c.to.After(c.from) {
+			return nil, fmt.Errorf("to (%s) is before from (%s)", f.To, f.From)
+		}
+	}
+
+	for i, r := range f.Rename {
+		re, err := regexp.Compile(r.Match)
+		if err != nil {
+			return nil, fmt.Errorf("rename[%d]: %w", i, err)
+		}
+		c.rename = append(c.rename, compiledRename{re: re, to: r.To})
+	}
+	c.aliases = f.Aliases
+	return c, nil
+}
+
+func compileMatches(field string, matches []Match) ([]compiledMatch, error) {
+	var out []compiledMatch
+	for i, m := range matches {
+		var cm compiledMatch
+		var err error
+		if m.Name != "" {
+			if cm.name, err = regexp.Compile(m.Name); err != nil {
+				return nil, fmt.Errorf("%s[%d].name: %w", field, i, err)
+			}
+		}
+		if m.Type != "" {
+			if cm.typ, err = regexp.Compile(m.Type); err != nil {
+				return nil, fmt.Errorf("%s[%d].type: %w", field, i, err)
+			}
+		}
+		cm.room = strings.ToUpper(m.Room)
+		if m.Campus != "" {
+			if cm.campus = m.Campus; !campus.Known(m.Campus) {
+				return nil, fmt.Errorf("%s[%d].campus: unknown campus %q", field, i, m.Campus)
+			}
+		}
+		for _, day := range m.Weekdays {
+			wd, ok := ParseWeekday(day)
+			if !ok {
+				return nil, fmt.Errorf("%s[%d].weekdays: unknown weekday %q", field, i, day)
+			}
+			if cm.weekdays == nil {
+				cm.weekdays = make(map[time.Weekday]bool)
+			}
+			cm.weekdays[wd] = true
+		}
+		out = append(out, cm)
+	}
+	return out, nil
+}
+
+// Apply returns the kept courses, renamed. The input slice is not modified.
+func (c *Compiled) Apply(courses []scraper.Course) []scraper.Course {
+	var out []scraper.Course
+	for _, course := range courses {
+		if !c.Keep(course) {
+			continue
+		}
+		course.Name = c.DisplayName(course.Name)
+		out = append(out, course)
+	}
+	return out
+}
+
+// Keep reports whether a course passes the date window and the include/exclude rules
+func (c *Compiled) Keep(course scraper.Course) bool {
+	// Courses without a parsable date never match a date window or weekday condition
+	start, _, _ := course.Times()
+	if !c.from.IsZero() && (start.IsZero() || start.Before(c.from)) {
+		return false
+	}
+	if !c.to.IsZero() && (start.IsZero() || !start.Before(c.to)) {
+		return false
+	}
+
+	if len(c.include) > 0 {
+		included := false
+		for _, m := range c.include {
+			if m.matches(course, start) {
+				included = true
+				break
+			}
+		}
+		if !included {
+			return false
+		}
+	}
+	for _, m := range c.exclude {
+		if m.matches(course, start) {
+			return false
+		}
+	}
+	return true
+}
+
+// DisplayName applies the alias and rename rules to a course name
+func (c *Compiled) DisplayName(name string) string {
+	if alias, ok := c.aliases[name]; ok {
+		return alias
+	}
+	for _, r := range c.rename {
+		if r.re.MatchString(name) {
+			return r.re.ReplaceAllString(name, r.to)
+		}
+	}
+	return name
+}
+
+func (m compiledMatch) matches(c scraper.Course, start time.Time) bool {
+	if m.name != nil && !m.name.MatchString(c.Name) {
+		return false
+	}
+	if m.typ != nil && !m.typ.MatchString(c.Type) {
+		return false
+	}
+	if m.room != "" && !strings.HasPrefix(strings.ToUpper(c.Room), m.room) {
+		return false
+	}
+	if m.campus != "" && !campus.Default().ForRoom(c.Room).Is(m.campus) {
+		return false
+	}
+	if len(m.weekdays) > 0 && (start.IsZero() || !m.weekdays[start.In(scraper.Berlin).Weekday()]) {
+		return false
+	}
+	return true
+}
+
+var weekdayNames = map[string]time.Weekday{
+	"mo": time.Monday, "mon": time.Monday, "monday": time.Monday, "montag": time.Monday,
+	"tu": time.Tuesday, "tue": time.Tuesday, "tuesday": time.Tuesday, "di": time.Tuesday, "dienstag": time.Tuesday,
+	"we": time.Wednesday, "wed": time.Wednesday, "wednesday": time.Wednesday, "mi": time.Wednesday, "mittwoch": time.Wednesday,
+	"th": time.Thursday, "thu": time.Thursday, "thursday": time.Thursday, "do": time.Thursday, "donnerstag": time.Thursday,
+	"fr": time.Friday, "fri": time.Friday, "friday": time.Friday, "freitag": time.Friday,
+	"sa": time.Saturday, "sat": time.Saturday, "saturday": time.Saturday, "samstag": time.Saturday,
+	"su": time.Sunday, "sun": time.Sunday, "sunday": time.Sunday, "so": time.Sunday, "sonntag": time.Sunday,
+}
+
+// ParseWeekday accepts English and German weekday names and their usual abbreviations
+func ParseWeekday(s string) (time.Weekday, bool) {
+	wd, ok := weekdayNames[strings.ToLower(strings.TrimSpace(s))]
+	return wd, ok
+}
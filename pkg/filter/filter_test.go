@@ -0,0 +1,134 @@
+package filter
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"faliactl/pkg/scraper"
+)
+
+func course(name, typ, room string, day int, hour int) scraper.Course {
+	start := time.Date(2026, 3, day, hour, 15, 0, 0, scraper.Berlin)
+	return scraper.Course{
+		Name:      name,
+		Type:      typ,
+		Room:      room,
+		DateStr:   start.Format("02.01.2006"),
+		StartTime: start.Format("15:04"),
+		EndTime:   start.Add(90 * time.Minute).Format("15:04"),
+		Start:     start,
+		End:       start.Add(90 * time.Minute),
+	}
+}
+
+// 2026-03-02 is a Monday, 2026-03-06 a Friday
+var sample = []scraper.Course{
+	course("Programmieren", "Vorlesung", "WF-EX-2/127", 2, 8),
+	course("Programmieren", "Übung Gruppe A", "WF-EX-2/20", 3, 10),
+	course("Programmieren", "Übung Gruppe B", "WF-EX-2/21", 4, 10),
+	course("Programmieren", "Labor Gruppe B", "WF-EX-7/3", 6, 12),
+	course("Programmieren", "Labor Gruppe B", "WF-EX-7/3", 5, 12),
+	course("Mathematik", "Vorlesung", "SZ-A-101", 2, 10),
+}
+
+func names(courses []scraper.Course) []string {
+	var out []string
+	for _, c := range courses {
+		out = append(out, c.Name+"/"+c.Type+"/"+c.Start.Weekday().String()[:3])
+	}
+	return out
+}
+
+func apply(t *testing.T, f *Filter) []string {
+	t.Helper()
+	c, err := f.Compile()
+	if err != nil {
+		t.Fatalf("Compile: %v", err)
+	}
+	return names(c.Apply(sample))
+}
+
+func TestApply_GroupBWithoutFridayLabs(t *testing.T) {
+	got := apply(t, &Filter{
+		Include: []Match{{Name: "^Programmieren$", Type: "Vorlesung|Gruppe B"}},
+		Exclude: []Match{{Type: "^Labor", Weekdays: []string{"Freitag"}}},
+	})
+	want := []string{
+		"Programmieren/Vorlesung/Mon",
+		"Programmieren/Übung Gruppe B/Wed",
+		"Programmieren/Labor Gruppe B/Thu",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestApply_RoomCampusAndDates(t *testing.T) {
+	if got := apply(t, &Filter{Include: []Match{{Room: "wf-ex-7"}}}); len(got) != 2 {
+		t.Errorf("room prefix: got %v", got)
+	}
+	if got := apply(t, &Filter{Include: []Match{{Campus: "Salzgitter"}}}); !reflect.DeepEqual(got, []string{"Mathematik/Vorlesung/Mon"}) {
+		t.Errorf("campus: got %v", got)
+	}
+	if got := apply(t, &Filter{Exclude: []Match{{Campus: "wolfenbüttel"}}}); len(got) != 1 {
+		t.Errorf("campus city: got %v", got)
+	}
+	if got := apply(t, &Filter{Include: []Match{{Campus: "wf-exer"}}}); len(got) != 5 {
+		t.Errorf("campus id: got %v", got)
+	}
+	got := apply(t, &Filter{From: "2026-03-03", To: "2026-03-05"})
+	want := []string{
+		"Programmieren/Übung Gruppe A/Tue",
+		"Programmieren/Übung Gruppe B/Wed",
+		"Programmieren/Labor Gruppe B/Thu",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("date window: got %v, want %v", got, want)
+	}
+}
+
+func TestApply_RenameAndAliases(t *testing.T) {
+	c, err := (&Filter{
+		Rename:  []Rename{{Match: "^(Prog)\\w+$", To: "$1."}},
+		Aliases: map[string]string{"Mathematik": "Mathe"},
+	}).Compile()
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := c.Apply(sample)
+	if out[0].Name != "Prog." {
+		t.Errorf("rename: got %q", out[0].Name)
+	}
+	if out[5].Name != "Mathe" {
+		t.Errorf("alias: got %q", out[5].Name)
+	}
+	if sample[0].Name != "Programmieren" {
+		t.Error("Apply modified its input")
+	}
+}
+
+func TestCompile_Errors(t *testing.T) {
+	for _, f := range []*Filter{
+		{Include: []Match{{Name: "("}}},
+		{Exclude: []Match{{Weekdays: []string{"someday"}}}},
+		{Include: []Match{{Campus: "Atlantis"}}},
+		{From: "01.03.2026"},
+		{From: "2026-03-10", To: "2026-03-01"},
+		{Rename: []Rename{{Match: "[", To: "x"}}},
+	} {
+		if _, err := f.Compile(); err == nil {
+			t.Errorf("expected an error for %+v", f)
+		}
+	}
+}
+
+func TestNilFilterKeepsEverything(t *testing.T) {
+	var f *Filter
+	if !f.Empty() {
+		t.Error("nil filter should be empty")
+	}
+	if got := apply(t, f); len(got) != len(sample) {
+		t.Errorf("got %d courses, want %d", len(got), len(sample))
+	}
+}
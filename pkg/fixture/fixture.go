@@ -0,0 +1,187 @@
+// Package fixture records upstream HTTP responses as golden files and replays them,
+// so the CLI and the test suite can run hermetically and intranet HTML changes can be
+// attached to bug reports.
+package fixture
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// Environment variables that switch every upstream client into record or replay mode
+const (
+	EnvRecord = "FALIACTL_RECORD"
+	EnvReplay = "FALIACTL_REPLAY"
+)
+
+// Meta is the header file stored next to each recorded body
+type Meta struct {
+	Method string      `json:"method"`
+	URL    string      `json:"url"`
+	Status int         `json:"status"`
+	Header http.Header `json:"header,omitempty"`
+}
+
+var unsafeChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// Key returns the file name stem a request is stored under: a readable slug of the URL
+// followed by a short hash of the method and full URL to keep query variants apart.
+func Key(req *http.Request) string {
+	sum := sha256.Sum256([]byte(req.Method + " " + req.URL.String()))
+
+	slug := unsafeChars.ReplaceAllString(req.URL.Host+req.URL.Path, "_")
+	slug = strings.Trim(slug, "_")
+	if len(slug) > 80 {
+		slug = slug[len(slug)-80:]
+	}
+	return fmt.Sprintf("%s-%s", slug, hex.EncodeToString(sum[:4]))
+}
+
+// Recorder forwards requests to Next and writes every response into Dir
+type Recorder struct {
+	Dir  string
+	Next http.RoundTripper
+
+	mu sync.Mutex
+}
+
+// NewRecorder creates a Recorder on top of http.DefaultTransport
+func NewRecorder(dir string) *Recorder {
+	return &Recorder{Dir: dir, Next: http.DefaultTransport}
+}
+
+// RoundTrip implements http.RoundTripper
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	// Always record complete responses; a golden 304 would be useless on replay
+	req = req.Clone(req.Context())
+	req.Header.Del("If-None-Match")
+	req.Header.Del("If-Modified-Since")
+
+	next := r.Next
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	resp, err := next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response for recording: %w", err)
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	meta := Meta{
+		Method: req.Method,
+		URL:    req.URL.String(),
+		Status: resp.StatusCode,
+		Header: resp.Header,
+	}
+	if err := r.save(Key(req), meta, body); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (r *Recorder) save(key string, meta Meta, body []byte) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := os.MkdirAll(r.Dir, 0755); err != nil {
+		return fmt.Errorf("could not create fixture directory: %w", err)
+	}
+
+	metaJSON, err := json.MarshalIndent(meta, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(r.Dir, key+".json"), metaJSON, 0644); err != nil {
+		return fmt.Errorf("could not write fixture: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(r.Dir, key+".body"), body, 0644); err != nil {
+		return fmt.Errorf("could not write fixture: %w", err)
+	}
+	return nil
+}
+
+// Replayer answers requests from the golden files in Dir and never touches the network
+type Replayer struct {
+	Dir string
+}
+
+// NewReplayer creates a Replayer reading from dir
+func NewReplayer(dir string) *Replayer {
+	return &Replayer{Dir: dir}
+}
+
+// RoundTrip implements http.RoundTripper
+func (r *Replayer) RoundTrip(req *http.Request) (*http.Response, error) {
+	key := Key(req)
+	metaPath := filepath.Join(r.Dir, key+".json")
+
+	metaJSON, err := os.ReadFile(metaPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("no recorded response for %s %s (expected %s)", req.Method, req.URL, metaPath)
+		}
+		return nil, err
+	}
+
+	var meta Meta
+	if err := json.Unmarshal(metaJSON, &meta); err != nil {
+		return nil, fmt.Errorf("corrupt fixture %s: %w", metaPath, err)
+	}
+
+	body, err := os.ReadFile(filepath.Join(r.Dir, key+".body"))
+	if err != nil {
+		return nil, fmt.Errorf("missing body for fixture %s: %w", metaPath, err)
+	}
+
+	header := meta.Header
+	if header == nil {
+		header = http.Header{}
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", meta.Status, http.StatusText(meta.Status)),
+		StatusCode:    meta.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, nil
+}
+
+// TransportFromEnv returns a Recorder or Replayer when FALIACTL_RECORD or FALIACTL_REPLAY
+// is set, and nil when neither is
+func TransportFromEnv() (http.RoundTripper, error) {
+	record := os.Getenv(EnvRecord)
+	replay := os.Getenv(EnvReplay)
+
+	switch {
+	case record != "" && replay != "":
+		return nil, fmt.Errorf("%s and %s cannot be used together", EnvRecord, EnvReplay)
+	case record != "":
+		return NewRecorder(record), nil
+	case replay != "":
+		if _, err := os.Stat(replay); err != nil {
+			return nil, fmt.Errorf("replay directory: %w", err)
+		}
+		return NewReplayer(replay), nil
+	}
+	return nil, nil
+}
@@ -0,0 +1,92 @@
+package fixture
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRecordThenReplay(t *testing.T) {
+	dir := t.TempDir()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") != "" {
+			t.Errorf("recorder must strip conditional headers so goldens hold full bodies")
+		}
+		w.Header().Set("ETag", `"abc"`)
+		w.Write([]byte("<html>" + r.URL.Query().Get("q") + "</html>"))
+	}))
+
+	recording := &http.Client{Transport: NewRecorder(dir)}
+	for _, q := range []string{"one", "two"} {
+		req, _ := http.NewRequest("GET", server.URL+"/page.html?q="+q, nil)
+		req.Header.Set("If-None-Match", `"old"`)
+		resp, err := recording.Do(req)
+		if err != nil {
+			t.Fatalf("recording request failed: %v", err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if string(body) != "<html>"+q+"</html>" {
+			t.Fatalf("recorder must pass the body through, got %q", body)
+		}
+	}
+	server.Close()
+
+	bodies, _ := filepath.Glob(filepath.Join(dir, "*.body"))
+	if len(bodies) != 2 {
+		t.Fatalf("expected 2 golden bodies, got %v", bodies)
+	}
+
+	replaying := &http.Client{Transport: NewReplayer(dir)}
+	resp, err := replaying.Get(server.URL + "/page.html?q=two")
+	if err != nil {
+		t.Fatalf("replay failed: %v", err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK || string(body) != "<html>two</html>" {
+		t.Errorf("unexpected replayed response %d %q", resp.StatusCode, body)
+	}
+	if resp.Header.Get("ETag") != `"abc"` {
+		t.Errorf("expected recorded headers to be replayed, got %v", resp.Header)
+	}
+
+	_, err = replaying.Get(server.URL + "/never-recorded.html")
+	if err == nil || !strings.Contains(err.Error(), "no recorded response") {
+		t.Errorf("expected a descriptive miss error, got %v", err)
+	}
+}
+
+func TestTransportFromEnv(t *testing.T) {
+	dir := t.TempDir()
+
+	t.Setenv(EnvRecord, "")
+	t.Setenv(EnvReplay, "")
+	if rt, err := TransportFromEnv(); rt != nil || err != nil {
+		t.Errorf("expected no transport without env vars, got %v (%v)", rt, err)
+	}
+
+	t.Setenv(EnvReplay, dir)
+	if rt, _ := TransportFromEnv(); rt == nil {
+		t.Errorf("expected a replayer")
+	} else if _, ok := rt.(*Replayer); !ok {
+		t.Errorf("expected a *Replayer, got %T", rt)
+	}
+
+	t.Setenv(EnvRecord, dir)
+	if _, err := TransportFromEnv(); err == nil {
+		t.Errorf("expected an error when record and replay are both set")
+	}
+
+	t.Setenv(EnvRecord, "")
+	t.Setenv(EnvReplay, filepath.Join(dir, "missing"))
+	if _, err := TransportFromEnv(); err == nil || !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("expected a missing replay directory to be reported, got %v", err)
+	}
+}
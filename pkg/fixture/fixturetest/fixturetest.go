@@ -0,0 +1,42 @@
+// Package fixturetest wires the fixture recorder and replayer into integration tests. It is
+// only imported from _test.go files, so the testing package stays out of the CLI binary.
+package fixturetest
+
+import (
+	"net/http"
+	"os"
+	"testing"
+	"time"
+
+	"faliactl/pkg/fixture"
+)
+
+// EnvLive makes the integration tests talk to the real upstreams instead of replaying the
+// fixtures committed under testdata. "record" additionally rewrites those fixtures.
+const EnvLive = "FALIACTL_LIVE"
+
+// timeout bounds every request of an integration test, live or replayed
+const timeout = 30 * time.Second
+
+// Client returns the http.Client for an integration test. By default it replays the
+// fixtures in dir and never touches the network, so the suite is hermetic. FALIACTL_LIVE=1
+// goes live, FALIACTL_LIVE=record goes live and stores the responses in dir.
+// FALIACTL_RECORD and FALIACTL_REPLAY still take precedence, as they do for the CLI.
+func Client(t testing.TB, dir string) *http.Client {
+	t.Helper()
+	rt, err := fixture.TransportFromEnv()
+	if err != nil {
+		t.Fatalf("invalid fixture configuration: %v", err)
+	}
+	if rt == nil {
+		switch live := os.Getenv(EnvLive); live {
+		case "", "0":
+			rt = fixture.NewReplayer(dir)
+		case "record":
+			rt = fixture.NewRecorder(dir)
+		default:
+			rt = http.DefaultTransport
+		}
+	}
+	return &http.Client{Transport: rt, Timeout: timeout}
+}
@@ -0,0 +1,27 @@
+package fixturetest
+
+import (
+	"net/http"
+	"testing"
+
+	"faliactl/pkg/fixture"
+)
+
+func TestClient(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv(fixture.EnvRecord, "")
+	t.Setenv(fixture.EnvReplay, "")
+
+	t.Setenv(EnvLive, "")
+	if _, ok := Client(t, dir).Transport.(*fixture.Replayer); !ok {
+		t.Errorf("integration tests should replay by default")
+	}
+	t.Setenv(EnvLive, "record")
+	if rec, ok := Client(t, dir).Transport.(*fixture.Recorder); !ok || rec.Dir != dir {
+		t.Errorf("FALIACTL_LIVE=record should record into the fixture directory")
+	}
+	t.Setenv(EnvLive, "1")
+	if Client(t, dir).Transport != http.DefaultTransport {
+		t.Errorf("FALIACTL_LIVE=1 should go to the network")
+	}
+}
@@ -1,52 +1,107 @@
 package mensa
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strings"
 	"time"
 )
 
-var baseURL = "https://sls.api.stw-on.de/v1"
+const defaultBaseURL = "https://sls.api.stw-on.de/v1"
+
+const defaultUserAgent = "faliactl/1.0"
 
 // Client handles HTTP requests to the Mensa API
 type Client struct {
 	httpClient *http.Client
+	baseURL    string
+	userAgent  string
+	timeout    time.Duration
+}
+
+// Option customizes a Client created by NewClient
+type Option func(*Client)
+
+// WithBaseURL points the client at another API root, e.g. a local mirror or a test server
+func WithBaseURL(baseURL string) Option {
+	return func(c *Client) {
+		c.baseURL = strings.TrimRight(baseURL, "/")
+	}
+}
+
+// WithHTTPClient replaces the underlying http.Client, e.g. to install a custom transport
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// WithUserAgent overrides the User-Agent header sent with every request
+func WithUserAgent(userAgent string) Option {
+	return func(c *Client) {
+		c.userAgent = userAgent
+	}
+}
+
+// WithTimeout sets the per-request timeout. It applies on top of WithHTTPClient
+// without modifying the http.Client that was passed in.
+func WithTimeout(timeout time.Duration) Option {
+	return func(c *Client) {
+		c.timeout = timeout
+	}
 }
 
 // NewClient creates a new API client
-func NewClient() *Client {
-	return &Client{
+func NewClient(opts ...Option) *Client {
+	c := &Client{
 		httpClient: &http.Client{
 			Timeout: 10 * time.Second,
 		},
+		baseURL:   defaultBaseURL,
+		userAgent: defaultUserAgent,
+	}
+	for _, opt := range opts {
+		opt(c)
 	}
+	if c.timeout > 0 {
+		httpClient := *c.httpClient
+		httpClient.Timeout = c.timeout
+		c.httpClient = &httpClient
+	}
+	return c
 }
 
 // FetchLocations retrieves all available Mensa locations
 func (c *Client) FetchLocations() ([]Location, error) {
-	url := fmt.Sprintf("%s/location", baseURL)
+	return c.FetchLocationsContext(context.Background())
+}
+
+// FetchLocationsContext is FetchLocations with a context that cancels the request
+func (c *Client) FetchLocationsContext(ctx context.Context) ([]Location, error) {
+	url := fmt.Sprintf("%s/location", c.baseURL)
 
-	req, err := http.NewRequest("GET", url, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
 		return nil, err
 	}
 
-	req.Header.Set("User-Agent", "faliactl/1.0")
+	req.Header.Set("User-Agent", c.userAgent)
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch locations: %w", err)
+		return nil, &RequestError{URL: url, Err: err}
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+		return nil, &HTTPStatusError{Code: resp.StatusCode, URL: url}
 	}
 
 	var allLocations []Location
 	if err := json.NewDecoder(resp.Body).Decode(&allLocations); err != nil {
-		return nil, fmt.Errorf("failed to decode JSON response: %w", err)
+		return nil, &ParseError{URL: url, Err: err}
 	}
 
 	var validLocations []Location
@@ -61,30 +116,35 @@ func (c *Client) FetchLocations() ([]Location, error) {
 
 // FetchMenu retrieves the menu for a given location ID on a specific date (YYYY-MM-DD format)
 func (c *Client) FetchMenu(locationID int, date string) (*MenuResponse, error) {
-	url := fmt.Sprintf("%s/locations/%d/menu/%s", baseURL, locationID, date)
+	return c.FetchMenuContext(context.Background(), locationID, date)
+}
+
+// FetchMenuContext is FetchMenu with a context that cancels the request
+func (c *Client) FetchMenuContext(ctx context.Context, locationID int, date string) (*MenuResponse, error) {
+	url := fmt.Sprintf("%s/locations/%d/menu/%s", c.baseURL, locationID, date)
 
-	req, err := http.NewRequest("GET", url, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
 		return nil, err
 	}
 
-	req.Header.Set("User-Agent", "faliactl/1.0")
+	req.Header.Set("User-Agent", c.userAgent)
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch menu: %w", err)
+		return nil, &RequestError{URL: url, Err: err}
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode == 404 {
-		return nil, fmt.Errorf("no menu available for this date/location")
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, ErrNoMenu
 	} else if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+		return nil, &HTTPStatusError{Code: resp.StatusCode, URL: url}
 	}
 
 	var menuResp MenuResponse
 	if err := json.NewDecoder(resp.Body).Decode(&menuResp); err != nil {
-		return nil, fmt.Errorf("failed to decode JSON response: %w", err)
+		return nil, &ParseError{URL: url, Err: err}
 	}
 
 	return &menuResp, nil
@@ -1,10 +1,16 @@
 package mensa
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"path"
 	"reflect"
+	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
 )
 
 func TestClient_FetchLocations_Mock(t *testing.T) {
@@ -35,11 +41,7 @@ func TestClient_FetchLocations_Mock(t *testing.T) {
 	}))
 	defer server.Close()
 
-	originalBaseURL := baseURL
-	baseURL = server.URL
-	defer func() { baseURL = originalBaseURL }()
-
-	client := NewClient()
+	client := NewClient(WithBaseURL(server.URL))
 
 	locs, err := client.FetchLocations()
 	if err != nil {
@@ -86,11 +88,7 @@ func TestClient_FetchMenu_Mock(t *testing.T) {
 	}))
 	defer server.Close()
 
-	originalBaseURL := baseURL
-	baseURL = server.URL
-	defer func() { baseURL = originalBaseURL }()
-
-	client := NewClient()
+	client := NewClient(WithBaseURL(server.URL))
 
 	menu, err := client.FetchMenu(101, "2026-02-25")
 	if err != nil {
@@ -123,14 +121,132 @@ func TestClient_FetchMenu_404(t *testing.T) {
 	}))
 	defer server.Close()
 
-	originalBaseURL := baseURL
-	baseURL = server.URL
-	defer func() { baseURL = originalBaseURL }()
-
-	client := NewClient()
+	client := NewClient(WithBaseURL(server.URL))
 
 	_, err := client.FetchMenu(999, "2026-02-25")
 	if err == nil || err.Error() != "no menu available for this date/location" {
 		t.Fatalf("expected 404 message 'no menu available...', got error: %v", err)
 	}
+	if !errors.Is(err, ErrNoMenu) || !errors.Is(err, ErrNotFound) || errors.Is(err, ErrUpstreamUnavailable) {
+		t.Errorf("a missing menu should match ErrNoMenu and ErrNotFound only, got %v", err)
+	}
+}
+
+func TestClient_Errors(t *testing.T) {
+	status := http.StatusServiceUnavailable
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+		w.Write([]byte(`<html>maintenance</html>`))
+	}))
+	defer server.Close()
+	client := NewClient(WithBaseURL(server.URL))
+
+	_, err := client.FetchMenu(130, "2026-02-25")
+	var statusErr *HTTPStatusError
+	if !errors.Is(err, ErrUpstreamUnavailable) || !errors.As(err, &statusErr) || statusErr.Code != 503 {
+		t.Errorf("expected an unavailable 503, got %v", err)
+	}
+
+	status = http.StatusOK
+	_, err = client.FetchLocations()
+	var parseErr *ParseError
+	if !errors.As(err, &parseErr) {
+		t.Errorf("expected a ParseError for an HTML answer, got %v", err)
+	}
+
+	server.Close()
+	if _, err = client.FetchLocations(); !errors.Is(err, ErrUpstreamUnavailable) {
+		t.Errorf("expected an unreachable API to be unavailable, got %v", err)
+	}
+}
+
+func TestNewClient_Options(t *testing.T) {
+	var gotAgent string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAgent = r.Header.Get("User-Agent")
+		w.Write([]byte(`[]`))
+	}))
+	defer server.Close()
+
+	client := NewClient(WithBaseURL(server.URL+"/"), WithUserAgent("faliactl-test"), WithTimeout(time.Second))
+
+	if _, err := client.FetchLocations(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if gotAgent != "faliactl-test" {
+		t.Errorf("expected custom user agent, got %q", gotAgent)
+	}
+	if client.httpClient.Timeout != time.Second {
+		t.Errorf("expected 1s timeout, got %v", client.httpClient.Timeout)
+	}
+}
+
+func TestClient_FetchMenus(t *testing.T) {
+	var inFlight, maxInFlight atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			m := maxInFlight.Load()
+			if n <= m || maxInFlight.CompareAndSwap(m, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+
+		date := path.Base(r.URL.Path)
+		switch date {
+		case "2026-10-24", "2026-10-25":
+			w.WriteHeader(http.StatusNotFound)
+		case "2026-10-23":
+			w.Write([]byte(`{"announcements": [{"text": "Betriebsausflug", "closed": true}], "meals": []}`))
+		default:
+			fmt.Fprintf(w, `{"announcements": [], "meals": [{"name": "Eintopf %s", "lane": {"name": "Essen 1"}}]}`, date)
+		}
+	}))
+	defer server.Close()
+	client := NewClient(WithBaseURL(server.URL))
+
+	dates := []string{"2026-10-19", "2026-10-20", "2026-10-21", "2026-10-22", "2026-10-23", "2026-10-24", "2026-10-25"}
+	days, err := client.FetchMenus(130, dates)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(days) != len(dates) {
+		t.Fatalf("expected %d days, got %d", len(dates), len(days))
+	}
+	for i, day := range days {
+		if day.Date != dates[i] {
+			t.Errorf("day %d: expected %s, got %s", i, dates[i], day.Date)
+		}
+		if day.Meals == nil || day.Announcements == nil {
+			t.Errorf("%s: expected empty lists instead of nil", day.Date)
+		}
+	}
+	if days[0].Closed || len(days[0].Meals) != 1 || days[0].Meals[0].Name != "Eintopf 2026-10-19" {
+		t.Errorf("unexpected first day: %+v", days[0])
+	}
+	if !days[4].Closed || !days[5].Closed || !days[6].Closed {
+		t.Errorf("the announced closure and the days without a menu should be closed: %+v", days[4:])
+	}
+	if got := maxInFlight.Load(); got > maxParallelDays || got < 2 {
+		t.Errorf("expected between 2 and %d parallel requests, got %d", maxParallelDays, got)
+	}
+}
+
+func TestClient_FetchMenus_Error(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if path.Base(r.URL.Path) == "2026-10-20" {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.Write([]byte(`{"announcements": [], "meals": []}`))
+	}))
+	defer server.Close()
+	client := NewClient(WithBaseURL(server.URL))
+
+	_, err := client.FetchMenus(130, []string{"2026-10-19", "2026-10-20", "2026-10-21"})
+	if !errors.Is(err, ErrUpstreamUnavailable) || !strings.Contains(err.Error(), "2026-10-20") {
+		t.Errorf("expected an unavailable error naming the day, got %v", err)
+	}
 }
@@ -0,0 +1,73 @@
+package mensa
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"golang.org/x/sync/errgroup"
+)
+
+// maxParallelDays caps the menu requests FetchMenus has in flight at the same time
+const maxParallelDays = 4
+
+// DayMenu is the menu of one day of a multi-day view
+type DayMenu struct {
+	Date string `json:"date"` // YYYY-MM-DD
+	// Closed is set when the location publishes no menu for the day or an announcement closes it
+	Closed bool `json:"closed"`
+	MenuResponse
+}
+
+// FetchMenus retrieves the menus of several days (YYYY-MM-DD) in parallel, in the order of
+// dates. Days without a menu come back closed instead of failing; any other error fails the
+// whole call.
+func (c *Client) FetchMenus(locationID int, dates []string) ([]DayMenu, error) {
+	return c.FetchMenusContext(context.Background(), locationID, dates)
+}
+
+// FetchMenusContext is FetchMenus with a context that cancels the requests
+func (c *Client) FetchMenusContext(ctx context.Context, locationID int, dates []string) ([]DayMenu, error) {
+	days := make([]DayMenu, len(dates))
+
+	g, ctx := errgroup.WithContext(ctx)
+	g.SetLimit(maxParallelDays)
+	for i, date := range dates {
+		g.Go(func() error {
+			day := DayMenu{Date: date}
+			menu, err := c.FetchMenuContext(ctx, locationID, date)
+			switch {
+			case errors.Is(err, ErrNoMenu):
+				day.Closed = true
+			case err != nil:
+				return fmt.Errorf("%s: %w", date, err)
+			default:
+				day.MenuResponse = *menu
+				day.Closed = closed(menu)
+			}
+			// Empty lists instead of null keep the JSON of closed days in the same shape
+			if day.Announcements == nil {
+				day.Announcements = []Announcement{}
+			}
+			if day.Meals == nil {
+				day.Meals = []Meal{}
+			}
+			days[i] = day
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+	return days, nil
+}
+
+// closed reports whether one of the announcements closes the Mensa for the day
+func closed(menu *MenuResponse) bool {
+	for _, a := range menu.Announcements {
+		if a.Closed {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,25 @@
+package mensa
+
+import "faliactl/pkg/upstream"
+
+// The errors shared by every upstream client, so callers only need to import this package
+var (
+	ErrNotFound            = upstream.ErrNotFound
+	ErrUpstreamUnavailable = upstream.ErrUpstreamUnavailable
+)
+
+type (
+	HTTPStatusError = upstream.HTTPStatusError
+	RequestError    = upstream.RequestError
+	ParseError      = upstream.ParseError
+)
+
+// ErrNoMenu is returned by FetchMenu when the location publishes no menu for the date, e.g.
+// on weekends, holidays or dates too far ahead. It matches ErrNotFound.
+var ErrNoMenu error = noMenuError{}
+
+type noMenuError struct{}
+
+func (noMenuError) Error() string { return "no menu available for this date/location" }
+
+func (noMenuError) Is(target error) bool { return target == ErrNotFound }
@@ -0,0 +1,84 @@
+package mensa
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	ics "github.com/arran4/golang-ical"
+)
+
+// summaryMeals is how many meals the title of a day's event names; the description lists all
+const summaryMeals = 3
+
+// WriteICS writes one all-day "Mensa: ..." event per day, so lunches can be planned next to
+// the timetable. Closed days get a "Mensa: closed" event. The UIDs only depend on the
+// location and date, so importing a newer week updates the events instead of duplicating them.
+func WriteICS(w io.Writer, locationID int, days []DayMenu) error {
+	return writeICS(w, locationID, days, time.Now())
+}
+
+// writeICS is WriteICS at a fixed time. Without a state file the SEQUENCE is derived from the
+// export time, in minutes since the Unix epoch, so every later export outranks the earlier one
+// and clients replace the event together with the new LAST-MODIFIED.
+func writeICS(w io.Writer, locationID int, days []DayMenu, now time.Time) error {
+	cal := ics.NewCalendar()
+	cal.SetMethod(ics.MethodPublish)
+
+	sequence := int(now.Unix() / 60)
+	for _, day := range days {
+		date, err := time.Parse("2006-01-02", day.Date)
+		if err != nil {
+			return fmt.Errorf("invalid date %q: %w", day.Date, err)
+		}
+
+		event := cal.AddEvent(fmt.Sprintf("mensa-%d-%s@faliactl", locationID, day.Date))
+		event.SetDtStampTime(now)
+		event.SetModifiedAt(now)
+		event.SetSequence(sequence)
+		event.SetAllDayStartAt(date)
+		event.SetAllDayEndAt(date.AddDate(0, 0, 1))
+		event.SetSummary(daySummary(day))
+		event.SetDescription(dayDescription(day))
+	}
+
+	return cal.SerializeTo(w)
+}
+
+func daySummary(day DayMenu) string {
+	if day.Closed || len(day.Meals) == 0 {
+		return "Mensa: closed"
+	}
+	var names []string
+	for _, m := range day.Meals {
+		if len(names) == summaryMeals {
+			names = append(names, "…")
+			break
+		}
+		names = append(names, m.Name)
+	}
+	return "Mensa: " + strings.Join(names, ", ")
+}
+
+// dayDescription lists the announcements, then every meal with its lane and student price
+func dayDescription(day DayMenu) string {
+	var lines []string
+	for _, a := range day.Announcements {
+		lines = append(lines, "NOTICE: "+a.Text)
+	}
+	if len(lines) > 0 && len(day.Meals) > 0 {
+		lines = append(lines, "")
+	}
+	for _, m := range day.Meals {
+		line := fmt.Sprintf("%s: %s", m.Lane.Name, m.Name)
+		if m.Vegan() {
+			line += " (vegan)"
+		}
+		if m.Price.Student != "" {
+			line += fmt.Sprintf(" – %s €", m.Price.Student)
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,96 @@
+package mensa
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	ics "github.com/arran4/golang-ical"
+)
+
+func TestWriteICS(t *testing.T) {
+	days := []DayMenu{
+		{Date: "2026-10-22", MenuResponse: MenuResponse{Meals: []Meal{
+			{Name: "Linsen-Dal", Price: Price{Student: "2.80"}, Lane: Lane{Name: "Essen 1"},
+				Tags: Tags{Categories: []Category{{ID: "VEGA", Name: "Vegan"}}}},
+			{Name: "Schnitzel", Lane: Lane{Name: "Essen 2"}},
+			{Name: "Pommes", Lane: Lane{Name: "Beilagen"}},
+			{Name: "Pudding", Lane: Lane{Name: "Dessert"}},
+		}}},
+		{Date: "2026-10-23", Closed: true, MenuResponse: MenuResponse{
+			Announcements: []Announcement{{Text: "Betriebsausflug", Closed: true}},
+		}},
+	}
+
+	var buf strings.Builder
+	if err := WriteICS(&buf, 130, days); err != nil {
+		t.Fatal(err)
+	}
+	cal, err := ics.ParseCalendar(strings.NewReader(buf.String()))
+	if err != nil {
+		t.Fatalf("invalid calendar: %v", err)
+	}
+	events := cal.Events()
+	if len(events) != 2 {
+		t.Fatalf("expected 2 events, got %d", len(events))
+	}
+
+	open := events[0]
+	if got := open.GetProperty(ics.ComponentPropertySummary).Value; got != "Mensa: Linsen-Dal, Schnitzel, Pommes, …" {
+		t.Errorf("unexpected summary %q", got)
+	}
+	start := open.GetProperty(ics.ComponentPropertyDtStart)
+	if start.Value != "20261022" || start.ICalParameters["VALUE"][0] != "DATE" {
+		t.Errorf("expected an all-day event on 2026-10-22, got %+v", start)
+	}
+	if end := open.GetProperty(ics.ComponentPropertyDtEnd).Value; end != "20261023" {
+		t.Errorf("expected the event to end on the next day, got %s", end)
+	}
+	if open.Id() != "mensa-130-2026-10-22@faliactl" {
+		t.Errorf("unexpected UID %q", open.Id())
+	}
+	desc := open.GetProperty(ics.ComponentPropertyDescription).Value
+	if !strings.Contains(desc, "Linsen-Dal (vegan) – 2.80 €") || !strings.Contains(desc, "Pudding") {
+		t.Errorf("description should list every meal, got %q", desc)
+	}
+
+	if open.GetProperty(ics.ComponentPropertySequence) == nil || open.GetProperty(ics.ComponentPropertyLastModified) == nil {
+		t.Errorf("events need SEQUENCE and LAST-MODIFIED to replace an earlier import")
+	}
+	monday := time.Date(2026, 10, 19, 9, 0, 0, 0, time.UTC)
+	if first, second := sequenceAt(t, days, monday), sequenceAt(t, days, monday.Add(time.Hour)); second <= first {
+		t.Errorf("a later export must have a higher SEQUENCE, got %d after %d", second, first)
+	}
+
+	closed := events[1]
+	if got := closed.GetProperty(ics.ComponentPropertySummary).Value; got != "Mensa: closed" {
+		t.Errorf("unexpected summary for a closed day %q", got)
+	}
+	if desc := closed.GetProperty(ics.ComponentPropertyDescription).Value; !strings.Contains(desc, "Betriebsausflug") {
+		t.Errorf("closed day should carry the announcement, got %q", desc)
+	}
+}
+
+// sequenceAt exports the days at now and returns the SEQUENCE of the first event
+func sequenceAt(t *testing.T, days []DayMenu, now time.Time) int {
+	t.Helper()
+	var buf strings.Builder
+	if err := writeICS(&buf, 130, days, now); err != nil {
+		t.Fatal(err)
+	}
+	cal, err := ics.ParseCalendar(strings.NewReader(buf.String()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return atoi(t, cal.Events()[0].GetProperty(ics.ComponentPropertySequence).Value)
+}
+
+func atoi(t *testing.T, s string) int {
+	t.Helper()
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return n
+}
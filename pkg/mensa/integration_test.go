@@ -1,14 +1,18 @@
 package mensa
 
 import (
+	"errors"
+	"path/filepath"
 	"testing"
-	"time"
+
+	"faliactl/pkg/fixture/fixturetest"
 )
 
-// TestMensaIntegration actually connects to the api.stw-on.de backend
-// If this fails, the API might be down or changed its JSON structure.
+// TestMensaIntegration_FetchLocations reads the locations of the api.stw-on.de backend, replayed
+// from testdata/fixtures. With FALIACTL_LIVE=1 it connects for real; if it fails then, the API
+// might be down or changed its JSON structure.
 func TestMensaIntegration_FetchLocations(t *testing.T) {
-	client := NewClient()
+	client := newIntegrationClient(t)
 
 	locations, err := client.FetchLocations()
 	if err != nil {
@@ -36,19 +40,18 @@ func TestMensaIntegration_FetchLocations(t *testing.T) {
 	}
 }
 
-// TestMensaIntegration_FetchMenu connects to the API to pull a specific day's menu.
+// TestMensaIntegration_FetchMenu pulls a specific day's menu, replayed unless FALIACTL_LIVE is set.
 func TestMensaIntegration_FetchMenu(t *testing.T) {
-	client := NewClient()
+	client := newIntegrationClient(t)
 
-	// We'll test grabbing today's menu for Wolfenbüttel (ID 130)
-	// Some days (like Sunday) it might be empty, so we mostly check for no HTTP/JSON errors.
-	today := time.Now().Format("2006-01-02")
-	menu, err := client.FetchMenu(130, today)
+	// A fixed weekday, so the recorded menu of Wolfenbüttel (ID 130) can be replayed. Live, the
+	// date may be past its publication window, so we mostly check for no HTTP/JSON errors.
+	menu, err := client.FetchMenu(130, "2026-03-04")
 
 	if err != nil {
 		// A 404 is technically valid if there are legitimately no meals today (e.g. Sunday/Holiday)
 		// but the json parsing shouldn't crash if it returns a 200.
-		if err.Error() != "no menu available for this date/location" {
+		if !errors.Is(err, ErrNoMenu) {
 			t.Fatalf("Failed to fetch menu with unexpected error: %v", err)
 		}
 	} else {
@@ -58,3 +61,8 @@ func TestMensaIntegration_FetchMenu(t *testing.T) {
 		}
 	}
 }
+
+// newIntegrationClient replays testdata/fixtures unless FALIACTL_LIVE is set
+func newIntegrationClient(t *testing.T) *Client {
+	return NewClient(WithHTTPClient(fixturetest.Client(t, filepath.Join("testdata", "fixtures"))))
+}
@@ -47,6 +47,16 @@ type Meal struct {
 	Tags  Tags   `json:"tags"`
 }
 
+// Vegan reports whether the meal carries the vegan category
+func (m Meal) Vegan() bool {
+	for _, cat := range m.Tags.Categories {
+		if cat.ID == "VEGA" {
+			return true
+		}
+	}
+	return false
+}
+
 // Price holds the cost variants
 type Price struct {
 	Student  string `json:"student"`
@@ -0,0 +1,188 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os/exec"
+	"strings"
+	"time"
+
+	"faliactl/pkg/config"
+	"faliactl/pkg/scraper"
+)
+
+// Event kinds
+const (
+	KindScheduleChange  = "schedule_change"
+	KindCommuteReminder = "commute_reminder"
+)
+
+// Event is what every sink receives: either a batch of schedule changes or a commute reminder
+type Event struct {
+	Kind    string           `json:"kind"`
+	Group   string           `json:"group,omitempty"`
+	Time    time.Time        `json:"time"`
+	Changes []scraper.Change `json:"changes,omitempty"`
+	Commute *Commute         `json:"commute,omitempty"`
+}
+
+// Commute is the payload of a leave-now reminder
+type Commute struct {
+	Course  scraper.Course `json:"course"`
+	LeaveAt time.Time      `json:"leave_at"`
+	Arrival time.Time      `json:"arrival"`
+	Delay   int            `json:"delay_minutes"` // Realtime delay of the first leg
+	Steps   []string       `json:"steps"`         // Human-readable legs, e.g. "07:12 Bus 420 -> Hauptbahnhof"
+}
+
+// Summary is a one-line headline for the event, e.g. for desktop notifications and mail subjects
+func (e Event) Summary() string {
+	if e.Kind == KindCommuteReminder && e.Commute != nil {
+		return fmt.Sprintf("faliactl: leave at %s for %s", e.Commute.LeaveAt.In(scraper.Berlin).Format("15:04"), e.Commute.Course.Name)
+	}
+	return fmt.Sprintf("faliactl: %d change(s) in %s", len(e.Changes), e.Group)
+}
+
+// Text renders the body: every change or commute step on its own line
+func (e Event) Text() string {
+	var b strings.Builder
+	if e.Commute != nil {
+		c := e.Commute
+		fmt.Fprintf(&b, "%s at %s in %s\n", c.Course.Name, c.Course.StartTime, c.Course.Room)
+		if c.Delay > 0 {
+			fmt.Fprintf(&b, "Your first connection is running %d min late.\n", c.Delay)
+		}
+		for _, step := range c.Steps {
+			b.WriteString(step)
+			b.WriteString("\n")
+		}
+		fmt.Fprintf(&b, "Arrival: %s\n", c.Arrival.In(scraper.Berlin).Format("15:04"))
+	}
+	for _, c := range e.Changes {
+		b.WriteString(c.String())
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// Sink delivers change events somewhere
+type Sink interface {
+	Name() string
+	Notify(ctx context.Context, event Event) error
+}
+
+// HookSink runs a shell command and writes the event as JSON to its stdin
+type HookSink struct {
+	Command string
+}
+
+func (s *HookSink) Name() string { return "hook" }
+
+func (s *HookSink) Notify(ctx context.Context, event Event) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	cmd := exec.CommandContext(ctx, "sh", "-c", s.Command)
+	cmd.Stdin = bytes.NewReader(data)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("hook %q failed: %w: %s", s.Command, err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// WebhookSink POSTs the event as JSON to a URL
+type WebhookSink struct {
+	URL        string
+	HTTPClient *http.Client // Defaults to a client with a 10s timeout
+}
+
+func (s *WebhookSink) Name() string { return "webhook" }
+
+func (s *WebhookSink) Notify(ctx context.Context, event Event) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.URL, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	client := s.HTTPClient
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("webhook request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned status code %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// DesktopSink shows a notification through notify-send (libnotify) on Linux desktops
+type DesktopSink struct{}
+
+func (s *DesktopSink) Name() string { return "desktop" }
+
+func (s *DesktopSink) Notify(ctx context.Context, event Event) error {
+	path, err := exec.LookPath("notify-send")
+	if err != nil {
+		return fmt.Errorf("notify-send not found: %w", err)
+	}
+	if out, err := exec.CommandContext(ctx, path, "--app-name=faliactl", event.Summary(), event.Text()).CombinedOutput(); err != nil {
+		return fmt.Errorf("notify-send failed: %w: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// NotifyAll delivers the event to every sink and returns one error per failed sink
+func NotifyAll(ctx context.Context, sinks []Sink, event Event) []error {
+	var errs []error
+	for _, sink := range sinks {
+		if err := sink.Notify(ctx, event); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", sink.Name(), err))
+		}
+	}
+	return errs
+}
+
+// FromConfig builds the sinks enabled in the user configuration
+func FromConfig(cfg *config.NotifyConfig) []Sink {
+	if cfg == nil {
+		return nil
+	}
+
+	var sinks []Sink
+	if cfg.Hook != "" {
+		sinks = append(sinks, &HookSink{Command: cfg.Hook})
+	}
+	if cfg.Webhook != "" {
+		sinks = append(sinks, &WebhookSink{URL: cfg.Webhook})
+	}
+	if cfg.Desktop {
+		sinks = append(sinks, &DesktopSink{})
+	}
+	if cfg.SMTP != nil && cfg.SMTP.Host != "" {
+		sinks = append(sinks, &SMTPSink{
+			Host:     cfg.SMTP.Host,
+			Port:     cfg.SMTP.Port,
+			Username: cfg.SMTP.Username,
+			Password: cfg.SMTP.Password,
+			From:     cfg.SMTP.From,
+			To:       cfg.SMTP.To,
+		})
+	}
+	return sinks
+}
@@ -0,0 +1,186 @@
+package notify
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"faliactl/pkg/config"
+	"faliactl/pkg/scraper"
+)
+
+func testEvent() Event {
+	old := scraper.Course{Name: "Lineare Algebra", DateStr: "04.03.2026 (Mittwoch)", StartTime: "08:15", EndTime: "09:45", Room: "WF-EX-7/3"}
+	moved := old
+	moved.Room = "WF-C-015"
+	return Event{
+		Kind:    KindScheduleChange,
+		Group:   "161902.html",
+		Time:    time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC),
+		Changes: scraper.DiffSchedules([]scraper.Course{old}, []scraper.Course{moved}),
+	}
+}
+
+func TestHookSink(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "event.json")
+	sink := &HookSink{Command: "cat > " + out}
+
+	if err := sink.Notify(context.Background(), testEvent()); err != nil {
+		t.Fatalf("hook failed: %v", err)
+	}
+
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got Event
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("hook did not receive JSON: %v\n%s", err, data)
+	}
+	if got.Group != "161902.html" || len(got.Changes) != 1 || got.Changes[0].Kind != scraper.ChangeMoved {
+		t.Errorf("unexpected event on stdin: %+v", got)
+	}
+}
+
+func TestHookSink_Failure(t *testing.T) {
+	sink := &HookSink{Command: "echo broken >&2; exit 3"}
+	err := sink.Notify(context.Background(), testEvent())
+	if err == nil || !strings.Contains(err.Error(), "broken") {
+		t.Errorf("expected failing hook to report its output, got %v", err)
+	}
+}
+
+func TestWebhookSink(t *testing.T) {
+	var got Event
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("unexpected request %s %s", r.Method, r.Header.Get("Content-Type"))
+		}
+		json.NewDecoder(r.Body).Decode(&got)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	sink := &WebhookSink{URL: server.URL}
+	if err := sink.Notify(context.Background(), testEvent()); err != nil {
+		t.Fatalf("webhook failed: %v", err)
+	}
+	if got.Group != "161902.html" {
+		t.Errorf("webhook did not receive the event, got %+v", got)
+	}
+
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer failing.Close()
+
+	if err := (&WebhookSink{URL: failing.URL}).Notify(context.Background(), testEvent()); err == nil {
+		t.Error("expected an error for a 502 response")
+	}
+}
+
+// smtpStub accepts a single SMTP session and returns the DATA section it received
+func smtpStub(t *testing.T) (host string, port int, received <-chan string) {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	ch := make(chan string, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		r := bufio.NewReader(conn)
+		reply := func(s string) { io.WriteString(conn, s+"\r\n") }
+		reply("220 localhost ESMTP stub")
+
+		var data strings.Builder
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			cmd := strings.ToUpper(strings.TrimSpace(line))
+			switch {
+			case strings.HasPrefix(cmd, "EHLO"), strings.HasPrefix(cmd, "HELO"):
+				reply("250 localhost")
+			case strings.HasPrefix(cmd, "DATA"):
+				reply("354 go ahead")
+				for {
+					l, err := r.ReadString('\n')
+					if err != nil || l == ".\r\n" {
+						break
+					}
+					data.WriteString(l)
+				}
+				ch <- data.String()
+				reply("250 queued")
+			case strings.HasPrefix(cmd, "QUIT"):
+				reply("221 bye")
+				return
+			default:
+				reply("250 OK")
+			}
+		}
+	}()
+
+	addr := ln.Addr().(*net.TCPAddr)
+	return addr.IP.String(), addr.Port, ch
+}
+
+func TestSMTPSink(t *testing.T) {
+	host, port, received := smtpStub(t)
+
+	sink := &SMTPSink{Host: host, Port: port, From: "faliactl@example.org", To: []string{"student@example.org"}}
+	if err := sink.Notify(context.Background(), testEvent()); err != nil {
+		t.Fatalf("smtp failed: %v", err)
+	}
+
+	select {
+	case msg := <-received:
+		for _, want := range []string{
+			"To: student@example.org",
+			"Subject: faliactl: 1 change(s) in 161902.html",
+			"moved: Lineare Algebra",
+		} {
+			if !strings.Contains(msg, want) {
+				t.Errorf("mail is missing %q:\n%s", want, msg)
+			}
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("stub server received no mail")
+	}
+}
+
+func TestFromConfig(t *testing.T) {
+	if sinks := FromConfig(nil); len(sinks) != 0 {
+		t.Errorf("expected no sinks without config, got %d", len(sinks))
+	}
+
+	sinks := FromConfig(&config.NotifyConfig{
+		Hook: "true",
+		SMTP: &config.SMTPConfig{Host: "mail.example.org", From: "a@example.org", To: []string{"b@example.org"}},
+	})
+	var names []string
+	for _, s := range sinks {
+		names = append(names, s.Name())
+	}
+	if strings.Join(names, ",") != "hook,smtp" {
+		t.Errorf("expected hook and smtp sinks, got %v", names)
+	}
+}
@@ -0,0 +1,138 @@
+package rooms
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"faliactl/pkg/campus"
+	"faliactl/pkg/scraper"
+)
+
+// Booking is one course occupying a room
+type Booking struct {
+	Start, End time.Time
+	Course     scraper.Course
+}
+
+// Index is the room occupancy of a set of schedules: every room that appears in them, with
+// its bookings sorted by start
+type Index struct {
+	registry *campus.Registry
+	rooms    map[string]*indexedRoom // By normalized code
+}
+
+type indexedRoom struct {
+	room     campus.Room
+	campus   campus.Campus
+	bookings []Booking
+}
+
+// FreeRoom is a room without bookings in the requested window
+type FreeRoom struct {
+	Room   campus.Room
+	Campus campus.Campus
+	// Since is the end of the room's previous booking that day, zero if it is free all morning
+	Since time.Time
+	// Until is the start of the room's next booking that day, zero if it stays free
+	Until time.Time
+}
+
+// NewIndex collects the rooms of the courses. Rooms whose code has no campus prefix, like
+// "Online", are left out; lectures listed in several groups count once.
+func NewIndex(registry *campus.Registry, courses []scraper.Course) *Index {
+	ix := &Index{registry: registry, rooms: make(map[string]*indexedRoom)}
+	seen := make(map[string]bool)
+	for _, c := range courses {
+		start, end, err := c.Times()
+		if err != nil {
+			continue
+		}
+		for _, code := range splitRooms(c.Room) {
+			room := registry.ParseRoom(code)
+			if room.Campus == "" || room.Number == "" {
+				continue
+			}
+			key := normalizeCode(code)
+			id := key + "|" + c.Key()
+			if seen[id] {
+				continue
+			}
+			seen[id] = true
+			r, ok := ix.rooms[key]
+			if !ok {
+				r = &indexedRoom{room: room, campus: registry.ForRoom(code)}
+				ix.rooms[key] = r
+			}
+			r.bookings = append(r.bookings, Booking{Start: start, End: end, Course: c})
+		}
+	}
+	for _, r := range ix.rooms {
+		sort.Slice(r.bookings, func(i, j int) bool { return r.bookings[i].Start.Before(r.bookings[j].Start) })
+	}
+	return ix
+}
+
+// Len is the number of indexed rooms
+func (ix *Index) Len() int {
+	return len(ix.rooms)
+}
+
+// Free lists the rooms of a campus (an ID, alias or city; "" for all) that have no booking
+// overlapping [from, to), sorted by campus, building and room number
+func (ix *Index) Free(campusName string, from, to time.Time) []FreeRoom {
+	var free []FreeRoom
+	for _, r := range ix.rooms {
+		if campusName != "" && !r.campus.Is(campusName) {
+			continue
+		}
+		fr := FreeRoom{Room: r.room, Campus: r.campus}
+		busy := false
+		for _, b := range r.bookings {
+			if b.Start.Before(to) && b.End.After(from) {
+				busy = true
+				break
+			}
+			if !sameDay(b.Start, from) {
+				continue
+			}
+			if !b.End.After(from) && b.End.After(fr.Since) {
+				fr.Since = b.End
+			}
+			if !b.Start.Before(to) && (fr.Until.IsZero() || b.Start.Before(fr.Until)) {
+				fr.Until = b.Start
+			}
+		}
+		if !busy {
+			free = append(free, fr)
+		}
+	}
+
+	sort.Slice(free, func(i, j int) bool {
+		a, b := free[i], free[j]
+		if a.Campus.ID != b.Campus.ID {
+			return a.Campus.ID < b.Campus.ID
+		}
+		if !strings.EqualFold(a.Room.Building, b.Room.Building) {
+			return codeLess(a.Room.Building, b.Room.Building)
+		}
+		return codeLess(a.Room.Number, b.Room.Number)
+	})
+	return free
+}
+
+func sameDay(a, b time.Time) bool {
+	a, b = a.In(scraper.Berlin), b.In(scraper.Berlin)
+	return a.Year() == b.Year() && a.YearDay() == b.YearDay()
+}
+
+// splitRooms separates the rooms of a course listed in several, e.g. "WF-EX-7/3, WF-EX-7/4"
+func splitRooms(rooms string) []string {
+	var out []string
+	for _, code := range strings.Split(rooms, ",") {
+		if code = strings.TrimSpace(code); code != "" {
+			out = append(out, code)
+		}
+	}
+	return out
+}
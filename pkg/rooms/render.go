@@ -0,0 +1,155 @@
+package rooms
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// Metres per site map column and row; characters are roughly three times as tall as wide
+const (
+	columnMetres = 5
+	rowMetres    = 15
+)
+
+// SiteMap draws the campus with its buildings, marking the located one as [>7<]. It returns
+// "" when the campus has no site map.
+func SiteMap(db *Database, loc *Location) string {
+	site, ok := db.Site(loc.Campus.ID)
+	if !ok {
+		return ""
+	}
+
+	canvas := make([][]rune, site.Height)
+	for y := range canvas {
+		canvas[y] = []rune(strings.Repeat(" ", site.Width))
+	}
+	put := func(x, y int, text string) {
+		if y < 0 || y >= site.Height {
+			return
+		}
+		for i, r := range []rune(text) {
+			if x+i >= 0 && x+i < site.Width {
+				canvas[y][x+i] = r
+			}
+		}
+	}
+
+	for _, l := range site.Labels {
+		put(l.X, l.Y, l.Text)
+	}
+	for _, b := range db.BuildingsOf(loc.Campus.ID) {
+		if loc.Building != nil && strings.EqualFold(b.Code, loc.Building.Code) {
+			put(b.X, b.Y, "[>"+b.Code+"<]")
+		} else {
+			put(b.X, b.Y, "["+b.Code+"]")
+		}
+	}
+
+	var sb strings.Builder
+	border := "+" + strings.Repeat("-", site.Width) + "+\n"
+	sb.WriteString(border)
+	for _, line := range canvas {
+		sb.WriteString("|" + string(line) + "|\n")
+	}
+	sb.WriteString(border)
+	return sb.String()
+}
+
+// FloorSketch draws the floors of the located building from the top down and marks the floor
+// of the room. It returns "" without a building or floor count.
+func FloorSketch(loc *Location) string {
+	if loc.Building == nil {
+		return ""
+	}
+	floors := loc.Building.Floors
+	target := -1
+	if loc.Room.Floor != "" {
+		fmt.Sscanf(loc.Room.Floor, "%d", &target)
+		if target >= floors {
+			floors = target + 1
+		}
+	}
+	if floors <= 0 {
+		return ""
+	}
+
+	const inner = 18
+	var sb strings.Builder
+	sb.WriteString("      +" + strings.Repeat("-", inner) + "+\n")
+	for f := floors - 1; f >= 0; f-- {
+		content := ""
+		if f == target {
+			content = " > " + loc.Room.Number
+		}
+		fmt.Fprintf(&sb, " %4s |%-*s|\n", floorName(f), inner, content)
+		if f > 0 {
+			sb.WriteString("      |" + strings.Repeat(".", inner) + "|\n")
+		}
+	}
+	sb.WriteString("      +" + strings.Repeat("=", inner) + "+\n")
+	return sb.String()
+}
+
+// floorName is the German floor label: EG for the ground floor, 1.OG and so on above
+func floorName(floor int) string {
+	if floor == 0 {
+		return "EG"
+	}
+	return fmt.Sprintf("%d.OG", floor)
+}
+
+// Directions describes the way from the campus entrance to the room in a few steps
+func Directions(db *Database, loc *Location) []string {
+	var steps []string
+	if loc.Building == nil {
+		if loc.Room.Building != "" {
+			steps = append(steps, fmt.Sprintf("Building %s is not in the room database yet; ask at the campus entrance.", loc.Room.Building))
+		}
+	} else if site, ok := db.Site(loc.Campus.ID); ok {
+		if x, y, ok := entrance(site); ok {
+			dx := float64(loc.Building.X-x) * columnMetres
+			dy := float64(y-loc.Building.Y) * rowMetres // Rows grow southwards
+			metres := int(math.Round(math.Hypot(dx, dy)/10) * 10)
+			steps = append(steps, fmt.Sprintf("From the entrance (E) walk about %d m %s to %s.", metres, compass(dx, dy), loc.Building.Label()))
+		} else {
+			steps = append(steps, fmt.Sprintf("Head to %s.", loc.Building.Label()))
+		}
+	}
+
+	switch {
+	case loc.Room.Floor == "" && loc.Room.Number != "":
+		steps = append(steps, fmt.Sprintf("Look for room %s.", loc.Room.Number))
+	case loc.Room.Floor == "0":
+		steps = append(steps, fmt.Sprintf("Room %s is on the ground floor.", loc.Room.Number))
+	case loc.Room.Floor != "":
+		steps = append(steps, fmt.Sprintf("Take the stairs to floor %s; room %s.", loc.Room.Floor, loc.Room.Number))
+	}
+	if loc.Building != nil && loc.Building.Notes != "" {
+		steps = append(steps, loc.Building.Notes)
+	}
+	if loc.Info != nil && loc.Info.Notes != "" {
+		steps = append(steps, loc.Info.Notes)
+	}
+	return steps
+}
+
+// entrance finds the "E" label of a site map
+func entrance(site Site) (int, int, bool) {
+	for _, l := range site.Labels {
+		if l.Text == "E" {
+			return l.X, l.Y, true
+		}
+	}
+	return 0, 0, false
+}
+
+// compass names the direction of a vector with north pointing up
+func compass(dx, dy float64) string {
+	names := []string{"east", "north-east", "north", "north-west", "west", "south-west", "south", "south-east"}
+	angle := math.Atan2(dy, dx) * 180 / math.Pi
+	if angle < 0 {
+		angle += 360
+	}
+	return names[int(math.Round(angle/45))%8]
+}
@@ -0,0 +1,297 @@
+// Package rooms is the room database behind `faliactl locate`: which building a room code
+// belongs to, how many floors it has and where it sits on the campus site map
+package rooms
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"faliactl/pkg/campus"
+	"faliactl/pkg/scraper"
+)
+
+//go:embed rooms.json
+var bundled []byte
+
+// Database lists the known sites, buildings and rooms
+type Database struct {
+	Sites     []Site     `json:"sites,omitempty"`
+	Buildings []Building `json:"buildings,omitempty"`
+	Rooms     []Room     `json:"rooms,omitempty"`
+}
+
+// Site is the ASCII site map of one campus. One column is about 5 m, one row about 15 m.
+type Site struct {
+	Campus string  `json:"campus"` // Campus ID from the registry
+	Width  int     `json:"width"`
+	Height int     `json:"height"`
+	Labels []Label `json:"labels,omitempty"` // Streets, stops and the entrance ("E")
+}
+
+// Label is text drawn onto a site map
+type Label struct {
+	X    int    `json:"x"`
+	Y    int    `json:"y"`
+	Text string `json:"text"`
+}
+
+// Building is one building of a campus, positioned on its site map
+type Building struct {
+	Campus  string `json:"campus"`
+	Code    string `json:"code"` // As it appears in room codes, e.g. "2" in WF-EX-2/127
+	Name    string `json:"name,omitempty"`
+	Address string `json:"address,omitempty"` // Only when it differs from the campus address
+	Floors  int    `json:"floors,omitempty"`  // Above ground, including the ground floor
+	X       int    `json:"x"`
+	Y       int    `json:"y"`
+	Notes   string `json:"notes,omitempty"`
+}
+
+// Label returns the building's name, or "Building <code>"
+func (b Building) Label() string {
+	if b.Name != "" {
+		return b.Name
+	}
+	return "Building " + b.Code
+}
+
+// Room attaches a name and notes to a single room code
+type Room struct {
+	Code  string `json:"code"` // Full code, e.g. "WF-EX-2/127"
+	Name  string `json:"name,omitempty"`
+	Notes string `json:"notes,omitempty"`
+}
+
+// Bundled returns the room database shipped with faliactl
+func Bundled() *Database {
+	var db Database
+	if err := json.Unmarshal(bundled, &db); err != nil {
+		panic(fmt.Sprintf("rooms: bundled database is invalid: %v", err))
+	}
+	return &db
+}
+
+// UserPath is where additions to the bundled database live: ~/.faliactl_rooms.json
+func UserPath() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("could not find user home directory: %w", err)
+	}
+	return filepath.Join(homeDir, ".faliactl_rooms.json"), nil
+}
+
+// Load returns the bundled database merged with the file at path. A missing file is not an error.
+func Load(path string) (*Database, error) {
+	db := Bundled()
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return db, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var user Database
+	dec := json.NewDecoder(strings.NewReader(string(data)))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&user); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	db.Merge(&user)
+	return db, nil
+}
+
+// Merge adds the sites, buildings and rooms of other, replacing entries with the same key
+func (db *Database) Merge(other *Database) {
+	for _, s := range other.Sites {
+		if i := db.siteIndex(s.Campus); i >= 0 {
+			db.Sites[i] = s
+		} else {
+			db.Sites = append(db.Sites, s)
+		}
+	}
+	for _, b := range other.Buildings {
+		if i := db.buildingIndex(b.Campus, b.Code); i >= 0 {
+			db.Buildings[i] = b
+		} else {
+			db.Buildings = append(db.Buildings, b)
+		}
+	}
+	for _, r := range other.Rooms {
+		if i := db.roomIndex(r.Code); i >= 0 {
+			db.Rooms[i] = r
+		} else {
+			db.Rooms = append(db.Rooms, r)
+		}
+	}
+}
+
+func (db *Database) siteIndex(campusID string) int {
+	for i, s := range db.Sites {
+		if strings.EqualFold(s.Campus, campusID) {
+			return i
+		}
+	}
+	return -1
+}
+
+func (db *Database) buildingIndex(campusID, code string) int {
+	for i, b := range db.Buildings {
+		if strings.EqualFold(b.Campus, campusID) && strings.EqualFold(b.Code, code) {
+			return i
+		}
+	}
+	return -1
+}
+
+func (db *Database) roomIndex(code string) int {
+	for i, r := range db.Rooms {
+		if normalizeCode(r.Code) == normalizeCode(code) {
+			return i
+		}
+	}
+	return -1
+}
+
+// Site returns the site map of a campus
+func (db *Database) Site(campusID string) (Site, bool) {
+	if i := db.siteIndex(campusID); i >= 0 {
+		return db.Sites[i], true
+	}
+	return Site{}, false
+}
+
+// BuildingsOf returns the buildings of a campus, sorted by code
+func (db *Database) BuildingsOf(campusID string) []Building {
+	var out []Building
+	for _, b := range db.Buildings {
+		if strings.EqualFold(b.Campus, campusID) {
+			out = append(out, b)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return codeLess(out[i].Code, out[j].Code) })
+	return out
+}
+
+// codeLess sorts numeric building codes numerically and puts them before letters
+func codeLess(a, b string) bool {
+	if len(a) != len(b) && isNumber(a) && isNumber(b) {
+		return len(a) < len(b)
+	}
+	return a < b
+}
+
+func isNumber(s string) bool {
+	return s != "" && strings.Trim(s, "0123456789") == ""
+}
+
+// Location is a resolved room or building
+type Location struct {
+	Query    string
+	Room     campus.Room
+	Campus   campus.Campus
+	Building *Building // Nil when the building is not in the database
+	Info     *Room     // Nil when the room has no entry of its own
+}
+
+// Address is the building's address, falling back to the campus address
+func (l *Location) Address() string {
+	if l.Building != nil && l.Building.Address != "" {
+		return l.Building.Address
+	}
+	return l.Campus.Address
+}
+
+// Locate resolves a room code ("WF-EX-2/127") or a campus name followed by a building and
+// optional room ("Am Exer 11", "salzgitter A/101")
+func Locate(registry *campus.Registry, db *Database, query string) (*Location, error) {
+	room, ok := resolve(registry, query)
+	if !ok {
+		return nil, fmt.Errorf("%q is neither a room code like WF-EX-2/127 nor a campus and building like \"Am Exer 11\"", query)
+	}
+
+	loc := &Location{Query: query, Room: room, Campus: registry.ForRoom(room.Code)}
+	if i := db.buildingIndex(loc.Campus.ID, room.Building); i >= 0 && room.Building != "" {
+		loc.Building = &db.Buildings[i]
+	}
+	if i := db.roomIndex(room.Code); i >= 0 {
+		loc.Info = &db.Rooms[i]
+	}
+	return loc, nil
+}
+
+// resolve parses the query as a room code, or strips a leading campus name and parses the rest
+func resolve(registry *campus.Registry, query string) (campus.Room, bool) {
+	query = strings.TrimSpace(query)
+	if room := registry.ParseRoom(query); room.Campus != "" {
+		return room, true
+	}
+
+	words := strings.Fields(query)
+	for n := len(words) - 1; n >= 1; n-- {
+		c, ok := registry.Lookup(strings.Join(words[:n], "-"))
+		if !ok || len(c.RoomPrefixes) == 0 {
+			continue
+		}
+		code := c.RoomPrefixes[0] + "-" + strings.Join(words[n:], "")
+		room := registry.ParseRoom(code)
+		// "Am Exer 11" names a building, not room 11 of an unknown building
+		if room.Building == "" {
+			room.Building, room.Number, room.Floor = room.Number, "", ""
+		}
+		room.Code = code
+		return room, true
+	}
+	return campus.Room{}, false
+}
+
+// Occupancy returns the courses taking place in the located room (or anywhere in the building
+// when no room number was given) between from and to, sorted by start. Courses listed in
+// several groups are reported once.
+func Occupancy(registry *campus.Registry, loc *Location, courses []scraper.Course, from, to time.Time) []scraper.Course {
+	seen := make(map[string]bool)
+	var out []scraper.Course
+	for _, c := range courses {
+		start, end, err := c.Times()
+		if err != nil || start.Before(from) || !start.Before(to) {
+			continue
+		}
+		if !inLocation(registry, loc, c.Room) {
+			continue
+		}
+		if key := c.Key() + "|" + c.Room; !seen[key] {
+			seen[key] = true
+			c.Start, c.End = start, end
+			out = append(out, c)
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Start.Before(out[j].Start) })
+	return out
+}
+
+// inLocation reports whether one of the comma separated rooms of a course is the located room
+func inLocation(registry *campus.Registry, loc *Location, rooms string) bool {
+	for _, code := range splitRooms(rooms) {
+		room := registry.ParseRoom(code)
+		if room.Campus == "" || registry.ForRoom(room.Code).ID != loc.Campus.ID {
+			continue
+		}
+		if !strings.EqualFold(room.Building, loc.Room.Building) {
+			continue
+		}
+		if loc.Room.Number == "" || strings.EqualFold(room.Number, loc.Room.Number) {
+			return true
+		}
+	}
+	return false
+}
+
+func normalizeCode(code string) string {
+	return strings.ToUpper(strings.ReplaceAll(code, " ", ""))
+}
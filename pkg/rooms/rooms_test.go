@@ -0,0 +1,168 @@
+package rooms
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"faliactl/pkg/campus"
+	"faliactl/pkg/scraper"
+)
+
+func TestLocate(t *testing.T) {
+	registry := campus.NewRegistry(campus.Builtin)
+	db := Bundled()
+
+	cases := []struct {
+		query                        string
+		campus, building, floor, num string
+		known                        bool
+	}{
+		{"WF-EX-2/127", "wf-exer", "2", "1", "127", true},
+		{"Am Exer 11", "wf-exer", "11", "", "", true},
+		{"salzgitter A/101", "salzgitter", "A", "1", "101", true},
+		{"WF-C-015", "wf-haupt", "C", "0", "015", true},
+		{"SUD-X-12", "suderburg", "X", "", "12", false},
+	}
+	for _, tc := range cases {
+		loc, err := Locate(registry, db, tc.query)
+		if err != nil {
+			t.Errorf("Locate(%q): %v", tc.query, err)
+			continue
+		}
+		r := loc.Room
+		if loc.Campus.ID != tc.campus || r.Building != tc.building || r.Floor != tc.floor || r.Number != tc.num {
+			t.Errorf("Locate(%q) = %s %+v", tc.query, loc.Campus.ID, r)
+		}
+		if (loc.Building != nil) != tc.known {
+			t.Errorf("Locate(%q): building known = %v, want %v", tc.query, loc.Building != nil, tc.known)
+		}
+	}
+
+	if _, err := Locate(registry, db, "Mensa"); err == nil {
+		t.Error("expected an error for a query without campus")
+	}
+}
+
+func TestLoad_UserAdditions(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rooms.json")
+	user := `{
+		"buildings": [{"campus": "wf-exer", "code": "2", "name": "Informatik", "floors": 5, "x": 3, "y": 1}],
+		"rooms": [{"code": "wf-ex-2/127", "name": "PC-Pool", "notes": "Key at the porter's desk"}]
+	}`
+	if err := os.WriteFile(path, []byte(user), 0644); err != nil {
+		t.Fatal(err)
+	}
+	db, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(db.BuildingsOf("wf-exer")) != len(Bundled().BuildingsOf("wf-exer")) {
+		t.Error("a building with a bundled code must replace it, not be added")
+	}
+
+	loc, err := Locate(campus.NewRegistry(campus.Builtin), db, "WF-EX-2/127")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loc.Building.Label() != "Informatik" || loc.Info == nil || loc.Info.Name != "PC-Pool" {
+		t.Errorf("user additions not applied: %+v %+v", loc.Building, loc.Info)
+	}
+	if steps := Directions(db, loc); !strings.Contains(strings.Join(steps, "\n"), "porter") {
+		t.Errorf("room notes missing from the directions: %v", steps)
+	}
+
+	if err := os.WriteFile(path, []byte(`{"building": []}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(path); err == nil {
+		t.Error("expected an error for an unknown field")
+	}
+	if _, err := Load(filepath.Join(t.TempDir(), "missing.json")); err != nil {
+		t.Errorf("a missing file must fall back to the bundled database: %v", err)
+	}
+}
+
+func TestRender(t *testing.T) {
+	registry := campus.NewRegistry(campus.Builtin)
+	db := Bundled()
+	loc, _ := Locate(registry, db, "WF-EX-2/127")
+
+	site := SiteMap(db, loc)
+	if !strings.Contains(site, "[>2<]") || !strings.Contains(site, "[7]") {
+		t.Errorf("site map does not mark building 2:\n%s", site)
+	}
+	floors := FloorSketch(loc)
+	if !strings.Contains(floors, "1.OG | > 127") || strings.Count(floors, "OG") != 3 {
+		t.Errorf("unexpected floor sketch:\n%s", floors)
+	}
+	steps := Directions(db, loc)
+	if len(steps) != 2 || !strings.Contains(steps[0], "north to Building 2") || !strings.Contains(steps[1], "floor 1") {
+		t.Errorf("unexpected directions: %v", steps)
+	}
+}
+
+func TestOccupancy(t *testing.T) {
+	registry := campus.NewRegistry(campus.Builtin)
+	course := func(name, date, start, room string) scraper.Course {
+		return scraper.Course{Name: name, DateStr: date, StartTime: start, EndTime: "23:00", Room: room}
+	}
+	courses := []scraper.Course{
+		course("Programmieren", "05.03.2026", "10:00", "WF-EX-2/127"),
+		course("Lineare Algebra", "04.03.2026", "08:15", "WF-EX-7/3, WF-EX-2/127"),
+		course("Lineare Algebra", "04.03.2026", "08:15", "WF-EX-7/3, WF-EX-2/127"), // Same lecture, other group
+		course("Datenbanken", "04.03.2026", "12:00", "WF-EX-2/20"),
+		course("Chemie", "04.03.2026", "12:00", "SZ-2/127"),
+		course("Programmieren", "12.03.2026", "10:00", "WF-EX-2/127"), // Next week
+	}
+	monday := time.Date(2026, 3, 2, 0, 0, 0, 0, scraper.Berlin)
+
+	room, _ := Locate(registry, Bundled(), "WF-EX-2/127")
+	got := Occupancy(registry, room, courses, monday, monday.AddDate(0, 0, 7))
+	if len(got) != 2 || got[0].Name != "Lineare Algebra" || got[1].Name != "Programmieren" {
+		t.Errorf("room occupancy: %+v", got)
+	}
+
+	building, _ := Locate(registry, Bundled(), "Am Exer 2")
+	if got := Occupancy(registry, building, courses, monday, monday.AddDate(0, 0, 7)); len(got) != 3 {
+		t.Errorf("building occupancy: %+v", got)
+	}
+}
+
+func TestIndex_Free(t *testing.T) {
+	registry := campus.NewRegistry(campus.Builtin)
+	course := func(name, start, end, room string) scraper.Course {
+		return scraper.Course{Name: name, DateStr: "04.03.2026", StartTime: start, EndTime: end, Room: room}
+	}
+	ix := NewIndex(registry, []scraper.Course{
+		course("Mathe", "08:15", "09:45", "WF-EX-2/127"),
+		course("Mathe", "08:15", "09:45", "WF-EX-2/127"), // Same lecture from another group
+		course("Prog", "13:00", "14:30", "WF-EX-2/127"),
+		course("Labor", "09:00", "12:00", "WF-EX-7/3, WF-EX-7/4"),
+		course("SE", "10:00", "11:30", "WF-C-015"),
+		course("Chemie", "08:00", "09:00", "SZ-A-101"),
+		course("Webinar", "10:00", "11:00", "Online"),
+	})
+	if ix.Len() != 5 {
+		t.Fatalf("expected 5 indexed rooms, got %d", ix.Len())
+	}
+
+	from := time.Date(2026, 3, 4, 10, 0, 0, 0, scraper.Berlin)
+	free := ix.Free("wf-exer", from, from.Add(90*time.Minute))
+	if len(free) != 1 || free[0].Room.Code != "WF-EX-2/127" {
+		t.Fatalf("expected only WF-EX-2/127 to be free, got %+v", free)
+	}
+	if got := free[0].Since.Format("15:04") + "-" + free[0].Until.Format("15:04"); got != "09:45-13:00" {
+		t.Errorf("unexpected free span %s", got)
+	}
+
+	// A city covers both Wolfenbüttel sites, "" every campus
+	if got := ix.Free("wolfenbuettel", from.Add(5*time.Hour), from.Add(6*time.Hour)); len(got) != 4 {
+		t.Errorf("expected all 4 Wolfenbüttel rooms free in the afternoon, got %+v", got)
+	}
+	if got := ix.Free("", from, from.Add(time.Hour)); len(got) != 2 || got[1].Campus.ID != "wf-exer" {
+		t.Errorf("expected SZ-A-101 and WF-EX-2/127, sorted by campus, got %+v", got)
+	}
+}
@@ -0,0 +1,266 @@
+package scraper
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// Defaults for FetchAll, tuned to stay polite towards the intranet
+const (
+	DefaultBulkWorkers = 4
+	DefaultBulkRate    = 250 * time.Millisecond // At most four requests per second overall
+	DefaultBulkPerHost = 2
+	DefaultBulkRetries = 2
+	DefaultBulkBackoff = time.Second
+)
+
+// BulkOptions tunes FetchAll. Zero values pick the defaults above.
+type BulkOptions struct {
+	// Workers is the number of groups processed in parallel, including cache hits
+	Workers int
+	// Rate is the minimum interval between two requests to the intranet across all workers.
+	// Negative disables the limit.
+	Rate time.Duration
+	// PerHost caps the requests in flight to one host
+	PerHost int
+	// Retries is how often a group is retried while the intranet is unavailable (timeouts,
+	// 429 and 5xx); negative disables retries. Other errors fail the group right away.
+	Retries int
+	// Backoff is the wait before the first retry, doubled for every further attempt
+	Backoff time.Duration
+	// Refresh revalidates every group with the intranet, even when its cache entry is fresh.
+	// Unchanged pages still only cost a 304.
+	Refresh bool
+	// Progress is called after every group, from one goroutine at a time
+	Progress func(Progress)
+}
+
+// Progress reports a finished group of a FetchAll run
+type Progress struct {
+	Done, Total int
+	Failed      int
+	Result      GroupResult
+}
+
+// GroupResult is the outcome for one group. When the intranet failed but a cached copy of up
+// to a week ago exists, Courses holds that copy, Status is CacheStale and Err is still set.
+type GroupResult struct {
+	Group    Group
+	Courses  []Course
+	Status   CacheStatus
+	Attempts int // Requests sent to the intranet, 0 for fresh cache hits
+	Err      error
+}
+
+// BulkResult collects the results of FetchAll in the order of the requested groups
+type BulkResult struct {
+	Results []GroupResult
+}
+
+// Failed returns the groups whose schedule could not be fetched
+func (r *BulkResult) Failed() []GroupResult {
+	var failed []GroupResult
+	for _, res := range r.Results {
+		if res.Err != nil {
+			failed = append(failed, res)
+		}
+	}
+	return failed
+}
+
+// Courses returns the courses of every group that has any, including stale copies,
+// deduplicated across groups
+func (r *BulkResult) Courses() []Course {
+	var all []Course
+	for _, res := range r.Results {
+		all = append(all, res.Courses...)
+	}
+	return deduplicateCourses(all)
+}
+
+// FetchAll loads the schedules of many groups with a pool of workers, a global rate limit,
+// a cap on concurrent requests per host and retries with exponential backoff. Fresh cache
+// entries are served without a request unless opts.Refresh is set.
+//
+// Failures of single groups are reported in the result, not as an error. The error is only
+// set when ctx ends first; the groups that were not finished then carry ctx.Err().
+func (c *Client) FetchAll(ctx context.Context, groups []Group, opts BulkOptions) (*BulkResult, error) {
+	opts = opts.withDefaults()
+	result := &BulkResult{Results: make([]GroupResult, len(groups))}
+	for i, g := range groups {
+		result.Results[i].Group = g
+	}
+
+	b := &bulk{client: c, opts: opts, hosts: make(map[string]chan struct{})}
+	jobs := make(chan int)
+	finished := make([]bool, len(groups))
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	done, failed := 0, 0
+
+	for range min(opts.Workers, max(len(groups), 1)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				res := b.fetch(ctx, groups[i])
+
+				mu.Lock()
+				result.Results[i] = res
+				finished[i] = true
+				done++
+				if res.Err != nil {
+					failed++
+				}
+				if opts.Progress != nil {
+					opts.Progress(Progress{Done: done, Total: len(groups), Failed: failed, Result: res})
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+feed:
+	for i := range groups {
+		select {
+		case jobs <- i:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		for i := range result.Results {
+			if !finished[i] {
+				result.Results[i].Err = err
+			}
+		}
+		return result, err
+	}
+	return result, nil
+}
+
+func (o BulkOptions) withDefaults() BulkOptions {
+	if o.Workers <= 0 {
+		o.Workers = DefaultBulkWorkers
+	}
+	if o.Rate == 0 {
+		o.Rate = DefaultBulkRate
+	}
+	if o.PerHost <= 0 {
+		o.PerHost = DefaultBulkPerHost
+	}
+	if o.Retries == 0 {
+		o.Retries = DefaultBulkRetries
+	}
+	if o.Backoff <= 0 {
+		o.Backoff = DefaultBulkBackoff
+	}
+	return o
+}
+
+// bulk holds the limiter state shared by the workers of one FetchAll run
+type bulk struct {
+	client *Client
+	opts   BulkOptions
+
+	mu    sync.Mutex
+	next  time.Time                // Earliest start of the next request
+	hosts map[string]chan struct{} // Per-host semaphores
+}
+
+func (b *bulk) fetch(ctx context.Context, group Group) GroupResult {
+	res := GroupResult{Group: group}
+	entry, _ := LoadCachedSchedule(group.URL)
+	if entry != nil && entry.Fresh() && !b.opts.Refresh {
+		res.Courses, res.Status = entry.Courses, CacheHit
+		return res
+	}
+
+	backoff := b.opts.Backoff
+	for attempt := 0; ; attempt++ {
+		res.Attempts++
+		res.Courses, res.Status, res.Err = b.request(ctx, group.URL, entry)
+		// A missing group or a changed page layout will not fix itself within the backoff
+		if !errors.Is(res.Err, ErrUpstreamUnavailable) || attempt >= b.opts.Retries || ctx.Err() != nil {
+			break
+		}
+		if !sleep(ctx, backoff) {
+			break
+		}
+		backoff *= 2
+	}
+
+	if errors.Is(res.Err, ErrUpstreamUnavailable) && entry.Servable() {
+		res.Courses, res.Status = entry.Courses, CacheStale
+	}
+	return res
+}
+
+// request waits for a host slot and the rate limit, then revalidates the group page
+func (b *bulk) request(ctx context.Context, groupURL string, entry *CacheEntry) ([]Course, CacheStatus, error) {
+	slot := b.hostSlot(groupURL)
+	select {
+	case slot <- struct{}{}:
+	case <-ctx.Done():
+		return nil, CacheMiss, ctx.Err()
+	}
+	defer func() { <-slot }()
+
+	if !b.wait(ctx) {
+		return nil, CacheMiss, ctx.Err()
+	}
+	return b.client.revalidate(ctx, groupURL, entry)
+}
+
+func (b *bulk) hostSlot(groupURL string) chan struct{} {
+	host := b.client.baseURL
+	if u, err := url.Parse(b.client.baseURL + "/" + groupURL); err == nil {
+		host = u.Host
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	slot, ok := b.hosts[host]
+	if !ok {
+		slot = make(chan struct{}, b.opts.PerHost)
+		b.hosts[host] = slot
+	}
+	return slot
+}
+
+// wait reserves the next request slot of the global rate limit and sleeps until it starts
+func (b *bulk) wait(ctx context.Context) bool {
+	if b.opts.Rate < 0 {
+		return ctx.Err() == nil
+	}
+	b.mu.Lock()
+	now := time.Now()
+	start := b.next
+	if start.Before(now) {
+		start = now
+	}
+	b.next = start.Add(b.opts.Rate)
+	b.mu.Unlock()
+	return sleep(ctx, time.Until(start))
+}
+
+// sleep waits for d and reports false when ctx ended first
+func sleep(ctx context.Context, d time.Duration) bool {
+	if d <= 0 {
+		return ctx.Err() == nil
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
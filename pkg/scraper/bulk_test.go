@@ -0,0 +1,122 @@
+package scraper
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestFetchAll(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("USERPROFILE", os.Getenv("HOME"))
+
+	writeCacheEntry("cached.html", &CacheEntry{Timestamp: time.Now(), Courses: []Course{{Name: "From cache"}}})
+
+	var mu sync.Mutex
+	attempts := make(map[string]int)
+	var inFlight, peak atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		if n > peak.Load() {
+			peak.Store(n)
+		}
+		time.Sleep(5 * time.Millisecond)
+
+		mu.Lock()
+		attempts[r.URL.Path]++
+		count := attempts[r.URL.Path]
+		mu.Unlock()
+
+		switch r.URL.Path {
+		case "/flaky.html":
+			if count == 1 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+		case "/broken.html":
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		case "/missing.html":
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(cacheTestHTML))
+	}))
+	defer server.Close()
+
+	groups := []Group{{URL: "a.html"}, {URL: "b.html"}, {URL: "flaky.html"}, {URL: "broken.html"}, {URL: "cached.html"}, {URL: "c.html"}, {URL: "missing.html"}}
+	var progress []Progress
+	result, err := NewClient(WithBaseURL(server.URL)).FetchAll(context.Background(), groups, BulkOptions{
+		Workers:  4,
+		PerHost:  2,
+		Rate:     -1,
+		Retries:  1,
+		Backoff:  time.Millisecond,
+		Progress: func(p Progress) { progress = append(progress, p) },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if peak.Load() > 2 {
+		t.Errorf("expected at most 2 requests in flight, saw %d", peak.Load())
+	}
+	if len(progress) != len(groups) || progress[len(progress)-1].Done != len(groups) || progress[len(progress)-1].Failed != 2 {
+		t.Errorf("unexpected progress reports: %+v", progress)
+	}
+
+	failed := result.Failed()
+	if len(failed) != 2 || failed[0].Group.URL != "broken.html" || failed[0].Attempts != 2 {
+		t.Errorf("expected broken.html to fail after one retry, got %+v", failed)
+	}
+	if res := result.Results[6]; !errors.Is(res.Err, ErrNotFound) || res.Attempts != 1 {
+		t.Errorf("expected missing.html to fail without a retry, got %+v", res)
+	}
+	if res := result.Results[2]; res.Err != nil || res.Attempts != 2 {
+		t.Errorf("expected flaky.html to succeed on the retry, got %+v", res)
+	}
+	if res := result.Results[4]; res.Status != CacheHit || res.Attempts != 0 || attempts["/cached.html"] != 0 {
+		t.Errorf("expected cached.html to be served from the cache, got %+v", res)
+	}
+	// The four successful downloads share one course, plus the cached one
+	if courses := result.Courses(); len(courses) != 2 {
+		t.Errorf("expected 2 deduplicated courses, got %+v", courses)
+	}
+}
+
+func TestFetchAll_RateLimitAndCancel(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("USERPROFILE", os.Getenv("HOME"))
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(cacheTestHTML))
+	}))
+	defer server.Close()
+	client := NewClient(WithBaseURL(server.URL))
+
+	groups := []Group{{URL: "a.html"}, {URL: "b.html"}, {URL: "c.html"}}
+	start := time.Now()
+	if _, err := client.FetchAll(context.Background(), groups, BulkOptions{Workers: 3, Rate: 30 * time.Millisecond}); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed < 60*time.Millisecond {
+		t.Errorf("3 requests 30ms apart finished in %v", elapsed)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	result, err := client.FetchAll(ctx, []Group{{URL: "d.html"}, {URL: "e.html"}}, BulkOptions{Refresh: true})
+	if err != context.Canceled {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+	if len(result.Failed()) != 2 {
+		t.Errorf("unfinished groups must be reported as failed: %+v", result.Results)
+	}
+}
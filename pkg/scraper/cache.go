@@ -5,19 +5,64 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
 	"time"
 )
 
 // cacheDuration determines how long schedule data is kept before refreshing
 const cacheDuration = 12 * time.Hour
 
+// staleDuration bounds how old an expired entry may be and still be served while the intranet is down
+const staleDuration = 7 * 24 * time.Hour
+
 // CacheEntry represents the disk data format
 type CacheEntry struct {
-	Timestamp time.Time `json:"timestamp"`
-	Courses   []Course  `json:"courses"`
+	Timestamp    time.Time `json:"timestamp"`
+	GroupURL     string    `json:"group_url,omitempty"`
+	ETag         string    `json:"etag,omitempty"`
+	LastModified string    `json:"last_modified,omitempty"`
+	Courses      []Course  `json:"courses"`
 }
 
-func getCachePath(groupURL string) (string, error) {
+// Fresh reports whether the entry is young enough to be served without asking the intranet
+func (e *CacheEntry) Fresh() bool {
+	return time.Since(e.Timestamp) <= cacheDuration
+}
+
+// Servable reports whether the entry, fresh or expired, is recent enough to stand in for an
+// unavailable intranet
+func (e *CacheEntry) Servable() bool {
+	return e != nil && time.Since(e.Timestamp) <= staleDuration
+}
+
+// CacheInfo describes a single cached group on disk
+type CacheInfo struct {
+	GroupURL     string    `json:"group"`
+	Path         string    `json:"path"`
+	Size         int64     `json:"size_bytes"`
+	Timestamp    time.Time `json:"timestamp,omitzero"`
+	Courses      int       `json:"courses"` // -1 if the file is corrupt
+	Fresh        bool      `json:"fresh"`
+	ETag         string    `json:"etag,omitempty"`
+	LastModified string    `json:"last_modified,omitempty"`
+}
+
+// CacheStats summarizes the whole cache directory
+type CacheStats struct {
+	Dir        string    `json:"dir"`
+	Entries    int       `json:"entries"`
+	Fresh      int       `json:"fresh"`
+	Expired    int       `json:"expired"`
+	Corrupt    int       `json:"corrupt"`
+	TotalBytes int64     `json:"total_bytes"`
+	Courses    int       `json:"courses"`
+	Oldest     time.Time `json:"oldest,omitzero"`
+	Newest     time.Time `json:"newest,omitzero"`
+}
+
+// CacheDir returns the directory holding the cached schedules, creating it if needed
+func CacheDir() (string, error) {
 	homeDir, err := os.UserHomeDir()
 	if err != nil {
 		return "", fmt.Errorf("could not find user home directory: %w", err)
@@ -27,54 +72,219 @@ func getCachePath(groupURL string) (string, error) {
 	if err := os.MkdirAll(cacheDir, 0755); err != nil {
 		return "", fmt.Errorf("could not create cache directory: %w", err)
 	}
+	return cacheDir, nil
+}
+
+func getCachePath(groupURL string) (string, error) {
+	cacheDir, err := CacheDir()
+	if err != nil {
+		return "", err
+	}
 
-	// Create a safe filesystem name from the URL (e.g., "161902.html" -> "161902_html.json")
+	// Create a safe filesystem name from the URL (e.g., "161902.html" -> "161902.html.json")
 	// For simplicity, just use base name
 	base := filepath.Base(groupURL)
 	return filepath.Join(cacheDir, base+".json"), nil
 }
 
-// readCache checks if a valid, unexpired cache exists for this group
-func readCache(groupURL string) ([]Course, bool) {
+// LoadCachedSchedule returns the cache entry for a group regardless of its age.
+// It returns an error wrapping os.ErrNotExist when the group was never cached.
+func LoadCachedSchedule(groupURL string) (*CacheEntry, error) {
 	path, err := getCachePath(groupURL)
 	if err != nil {
-		return nil, false
+		return nil, err
 	}
+	return readCacheFile(path)
+}
 
+func readCacheFile(path string) (*CacheEntry, error) {
 	data, err := os.ReadFile(path)
 	if err != nil {
-		return nil, false // File doesn't exist or can't be read
+		return nil, err
 	}
 
 	var entry CacheEntry
 	if err := json.Unmarshal(data, &entry); err != nil {
-		return nil, false
+		return nil, fmt.Errorf("corrupt cache file %s: %w", path, err)
+	}
+
+	// Entries written before the group URL was recorded still carry it in the file name
+	if entry.GroupURL == "" {
+		entry.GroupURL = strings.TrimSuffix(filepath.Base(path), ".json")
+	}
+	// Entries written before Start/End existed only have the raw strings
+	normalizeTimes(entry.Courses)
+	return &entry, nil
+}
+
+// readCache checks if a valid, unexpired cache exists for this group. It is a thin wrapper
+// over LoadCachedSchedule and CacheEntry.Fresh.
+func readCache(groupURL string) ([]Course, bool) {
+	entry, err := LoadCachedSchedule(groupURL)
+	if err != nil {
+		return nil, false // File doesn't exist or can't be read
 	}
 
 	// Check expiration
-	if time.Since(entry.Timestamp) > cacheDuration {
+	if !entry.Fresh() {
 		return nil, false // Expired
 	}
 
 	return entry.Courses, true
 }
 
-// writeCache saves the schedule to disk
+// writeCache saves the schedule to disk as a fresh entry without validators
 func writeCache(groupURL string, courses []Course) {
+	_ = writeCacheEntry(groupURL, &CacheEntry{
+		Timestamp: time.Now(),
+		Courses:   courses,
+	})
+}
+
+// writeCacheEntry atomically replaces the cache file so concurrent readers never see a partial write
+func writeCacheEntry(groupURL string, entry *CacheEntry) error {
 	path, err := getCachePath(groupURL)
 	if err != nil {
-		return
+		return err
 	}
 
-	entry := CacheEntry{
-		Timestamp: time.Now(),
-		Courses:   courses,
+	entry.GroupURL = groupURL
+	data, err := json.MarshalIndent(entry, "", "  ")
+	if err != nil {
+		return err
 	}
 
-	data, err := json.MarshalIndent(entry, "", "  ")
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// ListCache returns information about every cached group, sorted by group URL
+func ListCache() ([]CacheInfo, error) {
+	cacheDir, err := CacheDir()
+	if err != nil {
+		return nil, err
+	}
+
+	paths, err := filepath.Glob(filepath.Join(cacheDir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	var infos []CacheInfo
+	for _, path := range paths {
+		stat, err := os.Stat(path)
+		if err != nil {
+			continue
+		}
+		entry, err := readCacheFile(path)
+		if err != nil {
+			infos = append(infos, CacheInfo{
+				GroupURL: strings.TrimSuffix(filepath.Base(path), ".json"),
+				Path:     path,
+				Size:     stat.Size(),
+				Courses:  -1,
+			})
+			continue
+		}
+		infos = append(infos, CacheInfo{
+			GroupURL:     entry.GroupURL,
+			Path:         path,
+			Size:         stat.Size(),
+			Timestamp:    entry.Timestamp,
+			Courses:      len(entry.Courses),
+			Fresh:        entry.Fresh(),
+			ETag:         entry.ETag,
+			LastModified: entry.LastModified,
+		})
+	}
+
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].GroupURL < infos[j].GroupURL
+	})
+	return infos, nil
+}
+
+// ClearCache removes the cache entries for the given groups, or every entry when none are given.
+// It returns the number of files deleted.
+func ClearCache(groupURLs ...string) (int, error) {
+	var paths []string
+	if len(groupURLs) == 0 {
+		infos, err := ListCache()
+		if err != nil {
+			return 0, err
+		}
+		for _, info := range infos {
+			paths = append(paths, info.Path)
+		}
+	} else {
+		for _, groupURL := range groupURLs {
+			path, err := getCachePath(groupURL)
+			if err != nil {
+				return 0, err
+			}
+			paths = append(paths, path)
+		}
+	}
+
+	removed := 0
+	for _, path := range paths {
+		err := os.Remove(path)
+		if err == nil {
+			removed++
+		} else if !os.IsNotExist(err) {
+			return removed, fmt.Errorf("could not remove %s: %w", path, err)
+		}
+	}
+	return removed, nil
+}
+
+// GetCacheStats aggregates the on-disk cache into a single summary
+func GetCacheStats() (*CacheStats, error) {
+	cacheDir, err := CacheDir()
 	if err != nil {
-		return
+		return nil, err
 	}
 
-	_ = os.WriteFile(path, data, 0644)
+	infos, err := ListCache()
+	if err != nil {
+		return nil, err
+	}
+
+	stats := &CacheStats{Dir: cacheDir, Entries: len(infos)}
+	for _, info := range infos {
+		stats.TotalBytes += info.Size
+		if info.Courses < 0 {
+			stats.Corrupt++
+			continue
+		}
+		stats.Courses += info.Courses
+		if info.Fresh {
+			stats.Fresh++
+		} else {
+			stats.Expired++
+		}
+		if stats.Oldest.IsZero() || info.Timestamp.Before(stats.Oldest) {
+			stats.Oldest = info.Timestamp
+		}
+		if info.Timestamp.After(stats.Newest) {
+			stats.Newest = info.Timestamp
+		}
+	}
+	return stats, nil
 }
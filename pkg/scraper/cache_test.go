@@ -1,7 +1,11 @@
 package scraper
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
 	"reflect"
@@ -34,6 +38,8 @@ func TestCacheReadWrite(t *testing.T) {
 			DateStr:   "24.02.2026",
 			StartTime: "10:00",
 			EndTime:   "11:30",
+			Start:     time.Date(2026, 2, 24, 10, 0, 0, 0, Berlin),
+			End:       time.Date(2026, 2, 24, 11, 30, 0, 0, Berlin),
 			Room:      "WF Exer",
 		},
 	}
@@ -95,3 +101,212 @@ func TestCacheExpiration(t *testing.T) {
 		t.Errorf("expected readCache to reject expired cache (24h old, limit is 12h), but it incorrectly succeeded")
 	}
 }
+
+const cacheTestHTML = `<html><body>
+<div class="event-popover">
+  <div class="header"><p class="title">Lineare Algebra</p><p class="description">Vorlesung</p></div>
+  <div class="content">
+    <div class="part"><img src="/img/clock.svg"><div class="item"><p class="title">04.03.2026 (Mittwoch)</p><p class="description">08:15 Uhr - 09:45 Uhr</p></div></div>
+    <div class="part"><img src="/img/map-marker.svg"><div class="item"><p class="title">WF-EX-7/3</p></div></div>
+  </div>
+</div>
+</body></html>`
+
+func TestFetchSchedule_WriteThrough(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("USERPROFILE", os.Getenv("HOME"))
+
+	hits := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		w.Header().Set("ETag", `"v1"`)
+		w.Write([]byte(cacheTestHTML))
+	}))
+	defer server.Close()
+
+	client := NewClient(WithBaseURL(server.URL))
+
+	courses, status, err := client.FetchScheduleWithStatus("161902.html")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if status != CacheMiss || len(courses) != 1 {
+		t.Fatalf("expected a miss with 1 course, got %v with %d courses", status, len(courses))
+	}
+
+	entry, err := LoadCachedSchedule("161902.html")
+	if err != nil {
+		t.Fatalf("expected the schedule to be written through to the cache: %v", err)
+	}
+	if entry.ETag != `"v1"` || entry.GroupURL != "161902.html" {
+		t.Errorf("cache entry metadata not recorded: %+v", entry)
+	}
+
+	_, status, err = client.FetchScheduleWithStatus("161902.html")
+	if err != nil || status != CacheHit {
+		t.Fatalf("expected a cache hit on the second fetch, got %v (%v)", status, err)
+	}
+	if hits != 1 {
+		t.Errorf("expected exactly 1 upstream request, got %d", hits)
+	}
+}
+
+func TestFetchSchedule_ConditionalRevalidation(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("USERPROFILE", os.Getenv("HOME"))
+
+	writeCacheEntry("161902.html", &CacheEntry{
+		Timestamp:    time.Now().Add(-24 * time.Hour),
+		ETag:         `"v1"`,
+		LastModified: "Mon, 02 Mar 2026 10:00:00 GMT",
+		Courses:      []Course{{Name: "Cached"}},
+	})
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") != `"v1"` {
+			t.Errorf("expected If-None-Match header, got %q", r.Header.Get("If-None-Match"))
+		}
+		if r.Header.Get("If-Modified-Since") == "" {
+			t.Errorf("expected If-Modified-Since header")
+		}
+		w.WriteHeader(http.StatusNotModified)
+	}))
+	defer server.Close()
+
+	client := NewClient(WithBaseURL(server.URL))
+
+	courses, status, err := client.FetchScheduleWithStatus("161902.html")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if status != CacheRevalidated || len(courses) != 1 || courses[0].Name != "Cached" {
+		t.Fatalf("expected the cached courses to be revalidated, got %v: %+v", status, courses)
+	}
+
+	entry, _ := LoadCachedSchedule("161902.html")
+	if !entry.Fresh() {
+		t.Errorf("expected a 304 to refresh the cache timestamp")
+	}
+}
+
+func TestFetchSchedule_StaleFallback(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("USERPROFILE", os.Getenv("HOME"))
+
+	writeCacheEntry("161902.html", &CacheEntry{
+		Timestamp: time.Now().Add(-48 * time.Hour),
+		Courses:   []Course{{Name: "Old but useful"}},
+	})
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	client := NewClient(WithBaseURL(server.URL))
+
+	courses, status, err := client.FetchScheduleWithStatus("161902.html")
+	if err != nil {
+		t.Fatalf("expected stale data instead of an error, got: %v", err)
+	}
+	if status != CacheStale || len(courses) != 1 {
+		t.Fatalf("expected stale courses, got %v: %+v", status, courses)
+	}
+
+	// Without any cached copy the upstream error must surface
+	if _, err := client.FetchSchedule("other.html"); err == nil {
+		t.Errorf("expected an error when nothing is cached and the intranet is down")
+	}
+}
+
+func TestFetchSchedule_NoStaleFallbackForPermanentErrors(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("USERPROFILE", os.Getenv("HOME"))
+
+	old := &CacheEntry{Timestamp: time.Now().Add(-48 * time.Hour), Courses: []Course{{Name: "Old"}}}
+	writeCacheEntry("deleted.html", old)
+	writeCacheEntry("changed.html", old)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/deleted.html" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		// Popovers that no longer parse: a layout change
+		w.Write([]byte(`<div class="event-popover"><div class="header"></div></div>`))
+	}))
+	defer server.Close()
+	client := NewClient(WithBaseURL(server.URL))
+
+	// A deleted group or a layout change must not hide behind a week of stale data
+	if _, _, err := client.FetchScheduleWithStatus("deleted.html"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound instead of stale data, got %v", err)
+	}
+	var parseErr *ParseError
+	if _, _, err := client.FetchScheduleWithStatus("changed.html"); !errors.As(err, &parseErr) {
+		t.Errorf("expected a ParseError instead of stale data, got %v", err)
+	}
+
+	result, err := client.FetchAll(context.Background(), []Group{{URL: "deleted.html"}}, BulkOptions{Rate: -1, Retries: -1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res := result.Results[0]; res.Status == CacheStale || len(res.Courses) != 0 {
+		t.Errorf("bulk fetches must not serve stale data for a deleted group, got %+v", res)
+	}
+}
+
+func TestClearCacheAndStats(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("USERPROFILE", os.Getenv("HOME"))
+
+	writeCache("a.html", []Course{{Name: "A"}, {Name: "B"}})
+	writeCacheEntry("b.html", &CacheEntry{Timestamp: time.Now().Add(-24 * time.Hour), Courses: []Course{{Name: "C"}}})
+
+	stats, err := GetCacheStats()
+	if err != nil {
+		t.Fatalf("GetCacheStats failed: %v", err)
+	}
+	if stats.Entries != 2 || stats.Fresh != 1 || stats.Expired != 1 || stats.Courses != 3 {
+		t.Errorf("unexpected stats: %+v", stats)
+	}
+
+	removed, err := ClearCache("a.html")
+	if err != nil || removed != 1 {
+		t.Fatalf("expected to remove 1 entry, got %d (%v)", removed, err)
+	}
+
+	infos, _ := ListCache()
+	if len(infos) != 1 || infos[0].GroupURL != "b.html" {
+		t.Errorf("expected only b.html to remain, got %+v", infos)
+	}
+
+	removed, _ = ClearCache()
+	if removed != 1 {
+		t.Errorf("expected clearing everything to remove 1 entry, got %d", removed)
+	}
+}
+
+func TestCacheBackwardCompatibleCourses(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("USERPROFILE", os.Getenv("HOME"))
+
+	// Cache files written before Course had typed times only carry the raw strings
+	legacy := `{"timestamp":"` + time.Now().Format(time.RFC3339) + `","courses":[
+		{"Name":"Lineare Algebra","Type":"","DateStr":"04.03.2026 (Mittwoch)","StartTime":"08:15","EndTime":"09:45","Room":"WF-EX-7/3","GroupStr":""}
+	]}`
+	path, _ := getCachePath("legacy.html")
+	if err := os.WriteFile(path, []byte(legacy), 0644); err != nil {
+		t.Fatalf("failed to write legacy cache: %v", err)
+	}
+
+	courses, ok := readCache("legacy.html")
+	if !ok || len(courses) != 1 {
+		t.Fatalf("expected the legacy entry to load, got %v %+v", ok, courses)
+	}
+
+	want := time.Date(2026, 3, 4, 8, 15, 0, 0, Berlin)
+	if !courses[0].Start.Equal(want) || courses[0].End.Sub(courses[0].Start) != 90*time.Minute {
+		t.Errorf("expected Start/End to be backfilled, got %v - %v", courses[0].Start, courses[0].End)
+	}
+}
@@ -1,47 +1,127 @@
 package scraper
 
 import (
+	"context"
 	"fmt"
 	"net/http"
+	"strings"
 	"time"
 )
 
-const baseURL = "https://intranet-i.ostfalia.de/fips/stundenplan"
+const defaultBaseURL = "https://intranet-i.ostfalia.de/fips/stundenplan"
+
+const defaultUserAgent = "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/91.0.4472.124 Safari/537.36"
 
 // Client handles HTTP requests to the Ostfalia schedule website
 type Client struct {
 	httpClient *http.Client
+	baseURL    string
+	userAgent  string
+	timeout    time.Duration
+}
+
+// Option customizes a Client created by NewClient
+type Option func(*Client)
+
+// WithBaseURL points the client at another schedule root, e.g. a local mirror or a test server
+func WithBaseURL(baseURL string) Option {
+	return func(c *Client) {
+		c.baseURL = strings.TrimRight(baseURL, "/")
+	}
+}
+
+// WithHTTPClient replaces the underlying http.Client, e.g. to install a custom transport
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// WithUserAgent overrides the User-Agent header sent with every request
+func WithUserAgent(userAgent string) Option {
+	return func(c *Client) {
+		c.userAgent = userAgent
+	}
+}
+
+// WithTimeout sets the per-request timeout. It applies on top of WithHTTPClient
+// without modifying the http.Client that was passed in.
+func WithTimeout(timeout time.Duration) Option {
+	return func(c *Client) {
+		c.timeout = timeout
+	}
 }
 
 // NewClient creates a new scraper client
-func NewClient() *Client {
-	return &Client{
+func NewClient(opts ...Option) *Client {
+	c := &Client{
 		httpClient: &http.Client{
 			Timeout: 10 * time.Second,
 		},
+		baseURL:   defaultBaseURL,
+		userAgent: defaultUserAgent,
+	}
+	for _, opt := range opts {
+		opt(c)
 	}
+	if c.timeout > 0 {
+		httpClient := *c.httpClient
+		httpClient.Timeout = c.timeout
+		c.httpClient = &httpClient
+	}
+	return c
 }
 
 // Get fetches the given URL and returns the HTTP response
 func (c *Client) Get(path string) (*http.Response, error) {
-	url := fmt.Sprintf("%s/%s", baseURL, path)
-	req, err := http.NewRequest("GET", url, nil)
+	return c.GetContext(context.Background(), path)
+}
+
+// GetContext is Get with a context that cancels the request
+func (c *Client) GetContext(ctx context.Context, path string) (*http.Response, error) {
+	return c.get(ctx, path, nil)
+}
+
+// get performs the request with optional extra headers. A 304 Not Modified is only
+// treated as success when the caller sent conditional headers.
+func (c *Client) get(ctx context.Context, path string, header http.Header) (*http.Response, error) {
+	url := c.url(path)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
 		return nil, err
 	}
 
 	// Add expected headers
-	req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/91.0.4472.124 Safari/537.36")
+	req.Header.Set("User-Agent", c.userAgent)
+	for key, values := range header {
+		for _, v := range values {
+			req.Header.Add(key, v)
+		}
+	}
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch %s: %w", url, err)
+		return nil, &RequestError{URL: url, Err: err}
 	}
 
-	if resp.StatusCode != http.StatusOK {
+	conditional := len(header) > 0 && resp.StatusCode == http.StatusNotModified
+	if resp.StatusCode != http.StatusOK && !conditional {
 		resp.Body.Close()
-		return nil, fmt.Errorf("unexpected status code %d when fetching %s", resp.StatusCode, url)
+		return nil, &HTTPStatusError{Code: resp.StatusCode, URL: url}
 	}
 
 	return resp, nil
 }
+
+// url resolves a page path against the base URL
+func (c *Client) url(path string) string {
+	return fmt.Sprintf("%s/%s", c.baseURL, path)
+}
+
+// GroupPath normalizes a group identifier such as "161902" into the page path "161902.html"
+func GroupPath(group string) string {
+	if strings.HasSuffix(group, ".html") {
+		return group
+	}
+	return group + ".html"
+}
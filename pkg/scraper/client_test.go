@@ -0,0 +1,103 @@
+package scraper
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestNewClient_Options(t *testing.T) {
+	var gotAgent string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAgent = r.Header.Get("User-Agent")
+		if r.URL.Path != "/mirror/schedule.html" {
+			t.Errorf("expected request against the mirror root, got %s", r.URL.Path)
+		}
+		w.Write([]byte(`<select id="group"><option value="161902.html">DT</option></select>`))
+	}))
+	defer server.Close()
+
+	shared := &http.Client{Timeout: time.Minute}
+	client := NewClient(
+		WithTimeout(5*time.Second),
+		WithHTTPClient(shared),
+		WithBaseURL(server.URL+"/mirror/"),
+		WithUserAgent("faliactl-test"),
+	)
+
+	if _, err := client.FetchGroups(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if gotAgent != "faliactl-test" {
+		t.Errorf("expected custom user agent, got %q", gotAgent)
+	}
+	if client.httpClient.Timeout != 5*time.Second {
+		t.Errorf("expected timeout to apply regardless of option order, got %v", client.httpClient.Timeout)
+	}
+	if shared.Timeout != time.Minute {
+		t.Errorf("WithTimeout must not modify the http.Client passed to WithHTTPClient")
+	}
+}
+
+func TestClient_GetContext_Cancel(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer server.Close()
+	defer close(release)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err := NewClient(WithBaseURL(server.URL+"/")).GetContext(ctx, "schedule.html")
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected the deadline to abort the request, got %v", err)
+	}
+}
+
+func TestClient_Errors(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("USERPROFILE", os.Getenv("HOME"))
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/missing.html":
+			http.NotFound(w, r)
+		case "/down.html":
+			w.WriteHeader(http.StatusBadGateway)
+		case "/schedule.html":
+			w.Write([]byte(`<html><body>new layout</body></html>`))
+		default:
+			// A popover the parser no longer understands
+			w.Write([]byte(`<div class="event-popover"><div class="header"><p class="title">Mathe</p></div></div>`))
+		}
+	}))
+	defer server.Close()
+	client := NewClient(WithBaseURL(server.URL))
+
+	_, err := client.RefreshSchedule("missing.html")
+	var statusErr *HTTPStatusError
+	if !errors.Is(err, ErrNotFound) || !errors.As(err, &statusErr) || statusErr.Code != http.StatusNotFound {
+		t.Errorf("expected a 404 to match ErrNotFound, got %v", err)
+	}
+	if _, err := client.RefreshSchedule("down.html"); !errors.Is(err, ErrUpstreamUnavailable) {
+		t.Errorf("expected a 502 to match ErrUpstreamUnavailable, got %v", err)
+	}
+
+	var parseErr *ParseError
+	if _, err := client.RefreshSchedule("changed.html"); !errors.As(err, &parseErr) {
+		t.Errorf("expected unparseable popovers to be a ParseError, got %v", err)
+	}
+	if _, err := client.FetchGroups(); !errors.As(err, &parseErr) {
+		t.Errorf("expected a page without the group list to be a ParseError, got %v", err)
+	}
+
+	server.Close()
+	if _, err := client.RefreshSchedule("any.html"); !errors.Is(err, ErrUpstreamUnavailable) {
+		t.Errorf("expected an unreachable intranet to match ErrUpstreamUnavailable, got %v", err)
+	}
+}
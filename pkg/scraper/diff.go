@@ -0,0 +1,181 @@
+package scraper
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ChangeKind classifies a single difference between two schedule snapshots
+type ChangeKind string
+
+const (
+	// ChangeAdded is a lecture that only exists in the new snapshot
+	ChangeAdded ChangeKind = "added"
+	// ChangeRemoved is a lecture that only exists in the old snapshot
+	ChangeRemoved ChangeKind = "removed"
+	// ChangeMoved is the same lecture on the same day at another time and/or in another room
+	ChangeMoved ChangeKind = "moved"
+	// ChangeRenamed is a lecture in the same slot and room whose title changed
+	ChangeRenamed ChangeKind = "renamed"
+)
+
+// Change describes one difference. Old is nil for additions, New is nil for removals.
+type Change struct {
+	Kind   ChangeKind `json:"kind"`
+	Old    *Course    `json:"old,omitempty"`
+	New    *Course    `json:"new,omitempty"`
+	Fields []string   `json:"fields,omitempty"` // What changed for moved/renamed: "time", "room", "name", "type"
+}
+
+// Course returns the most relevant side of the change: the new course, or the old one for removals
+func (c Change) Course() Course {
+	if c.New != nil {
+		return *c.New
+	}
+	return *c.Old
+}
+
+// String renders the change as a single human-readable line
+func (c Change) String() string {
+	switch c.Kind {
+	case ChangeAdded:
+		return fmt.Sprintf("added: %s", describeCourse(*c.New))
+	case ChangeRemoved:
+		return fmt.Sprintf("removed: %s", describeCourse(*c.Old))
+	case ChangeRenamed:
+		return fmt.Sprintf("renamed: %q -> %q (%s %s-%s)", c.Old.Name, c.New.Name, c.New.DateStr, c.New.StartTime, c.New.EndTime)
+	default:
+		var parts []string
+		for _, f := range c.Fields {
+			switch f {
+			case "time":
+				parts = append(parts, fmt.Sprintf("%s-%s -> %s-%s", c.Old.StartTime, c.Old.EndTime, c.New.StartTime, c.New.EndTime))
+			case "room":
+				parts = append(parts, fmt.Sprintf("%s -> %s", orDash(c.Old.Room), orDash(c.New.Room)))
+			}
+		}
+		return fmt.Sprintf("moved: %s on %s (%s)", c.New.Name, c.New.DateStr, strings.Join(parts, ", "))
+	}
+}
+
+func describeCourse(c Course) string {
+	s := fmt.Sprintf("%s %s-%s %s", c.DateStr, c.StartTime, c.EndTime, c.Name)
+	if c.Type != "" {
+		s += " [" + c.Type + "]"
+	}
+	if c.Room != "" {
+		s += " in " + c.Room
+	}
+	return s
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+// DiffSchedules compares two snapshots of a schedule and reports what happened to every lecture.
+// Courses are first paired by their Key (same lecture, same slot), then by name and type on the
+// same day (moved), then by slot and room (renamed). Whatever is left was added or removed.
+// Changes are sorted chronologically.
+func DiffSchedules(old, new []Course) []Change {
+	var changes []Change
+
+	oldLeft := make([]*Course, 0, len(old))
+	for i := range old {
+		oldLeft = append(oldLeft, &old[i])
+	}
+	newLeft := make([]*Course, 0, len(new))
+	for i := range new {
+		newLeft = append(newLeft, &new[i])
+	}
+
+	pair := func(match func(o, n *Course) bool, emit func(o, n *Course)) {
+		var remaining []*Course
+		for _, n := range newLeft {
+			matched := false
+			for i, o := range oldLeft {
+				if o != nil && match(o, n) {
+					emit(o, n)
+					oldLeft[i] = nil
+					matched = true
+					break
+				}
+			}
+			if !matched {
+				remaining = append(remaining, n)
+			}
+		}
+		newLeft = remaining
+	}
+
+	// Same lecture in the same slot: at most the room changed
+	pair(func(o, n *Course) bool { return o.Key() == n.Key() }, func(o, n *Course) {
+		if o.Room != n.Room {
+			changes = append(changes, Change{Kind: ChangeMoved, Old: o, New: n, Fields: []string{"room"}})
+		}
+	})
+
+	// Same lecture on the same day at another time
+	pair(func(o, n *Course) bool {
+		return o.Name == n.Name && o.Type == n.Type && sameDay(*o, *n)
+	}, func(o, n *Course) {
+		fields := []string{"time"}
+		if o.Room != n.Room {
+			fields = append(fields, "room")
+		}
+		changes = append(changes, Change{Kind: ChangeMoved, Old: o, New: n, Fields: fields})
+	})
+
+	// Another title in exactly the same slot and room
+	pair(func(o, n *Course) bool {
+		return o.DateStr == n.DateStr && o.StartTime == n.StartTime && o.EndTime == n.EndTime && o.Room == n.Room
+	}, func(o, n *Course) {
+		fields := []string{"name"}
+		if o.Type != n.Type {
+			fields = append(fields, "type")
+		}
+		changes = append(changes, Change{Kind: ChangeRenamed, Old: o, New: n, Fields: fields})
+	})
+
+	for _, o := range oldLeft {
+		if o != nil {
+			changes = append(changes, Change{Kind: ChangeRemoved, Old: o})
+		}
+	}
+	for _, n := range newLeft {
+		changes = append(changes, Change{Kind: ChangeAdded, New: n})
+	}
+
+	sort.SliceStable(changes, func(i, j int) bool {
+		return changeTime(changes[i]).Before(changeTime(changes[j]))
+	})
+	return changes
+}
+
+func sameDay(a, b Course) bool {
+	as, _, errA := a.Times()
+	bs, _, errB := b.Times()
+	if errA != nil || errB != nil {
+		return a.DateStr == b.DateStr
+	}
+	return as.Format("2006-01-02") == bs.Format("2006-01-02")
+}
+
+// changeTime orders changes by the earliest of the old and new start
+func changeTime(c Change) time.Time {
+	var t time.Time
+	for _, side := range []*Course{c.Old, c.New} {
+		if side == nil {
+			continue
+		}
+		if start, _, err := side.Times(); err == nil && (t.IsZero() || start.Before(t)) {
+			t = start
+		}
+	}
+	return t
+}
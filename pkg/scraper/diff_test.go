@@ -0,0 +1,71 @@
+package scraper
+
+import (
+	"reflect"
+	"testing"
+)
+
+func diffFixture() []Course {
+	return []Course{
+		{Name: "Lineare Algebra", Type: "Vorlesung", DateStr: "04.03.2026 (Mittwoch)", StartTime: "08:15", EndTime: "09:45", Room: "WF-EX-7/3"},
+		{Name: "Programmieren 2", Type: "Übung", DateStr: "04.03.2026 (Mittwoch)", StartTime: "10:00", EndTime: "11:30", Room: "WF-EX-2/127"},
+		{Name: "Software Engineering", Type: "Vorlesung", DateStr: "06.03.2026 (Freitag)", StartTime: "14:00", EndTime: "15:30", Room: "WF-C-015"},
+		{Name: "Mathe 2", Type: "Vorlesung", DateStr: "05.03.2026 (Donnerstag)", StartTime: "08:15", EndTime: "09:45", Room: "WF-EX-7/3"},
+	}
+}
+
+func TestDiffSchedules_Identical(t *testing.T) {
+	if changes := DiffSchedules(diffFixture(), diffFixture()); len(changes) != 0 {
+		t.Errorf("expected no changes, got %v", changes)
+	}
+}
+
+func TestDiffSchedules(t *testing.T) {
+	old := diffFixture()
+	updated := diffFixture()
+
+	updated[0].Room = "WF-C-015"                                // room change only
+	updated[1].StartTime, updated[1].EndTime = "12:00", "13:30" // time change
+	updated[2].Name = "Software Engineering 2"                  // renamed in place
+	updated = updated[:3]                                       // Mathe 2 removed
+	updated = append(updated, Course{Name: "Datenbanken", Type: "Vorlesung", DateStr: "07.03.2026 (Samstag)", StartTime: "08:15", EndTime: "09:45"})
+
+	changes := DiffSchedules(old, updated)
+
+	var got []ChangeKind
+	for _, c := range changes {
+		got = append(got, c.Kind)
+	}
+	want := []ChangeKind{ChangeMoved, ChangeMoved, ChangeRemoved, ChangeRenamed, ChangeAdded}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected kinds %v, got %v", want, got)
+	}
+
+	if !reflect.DeepEqual(changes[0].Fields, []string{"room"}) {
+		t.Errorf("expected room change, got %v", changes[0].Fields)
+	}
+	if !reflect.DeepEqual(changes[1].Fields, []string{"time"}) {
+		t.Errorf("expected time change, got %v", changes[1].Fields)
+	}
+	if changes[3].Old.Name != "Software Engineering" || changes[3].New.Name != "Software Engineering 2" {
+		t.Errorf("unexpected rename pairing: %+v", changes[3])
+	}
+	if changes[2].New != nil || changes[2].Old.Name != "Mathe 2" {
+		t.Errorf("unexpected removal: %+v", changes[2])
+	}
+	if changes[4].Old != nil || changes[4].Course().Name != "Datenbanken" {
+		t.Errorf("unexpected addition: %+v", changes[4])
+	}
+}
+
+func TestChangeString(t *testing.T) {
+	old := diffFixture()[0]
+	moved := old
+	moved.StartTime, moved.EndTime, moved.Room = "14:00", "15:30", "WF-C-015"
+
+	got := DiffSchedules([]Course{old}, []Course{moved})[0].String()
+	want := "moved: Lineare Algebra on 04.03.2026 (Mittwoch) (08:15-09:45 -> 14:00-15:30, WF-EX-7/3 -> WF-C-015)"
+	if got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+}
@@ -0,0 +1,179 @@
+package scraper
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// SelectorCheck reports in how many popovers one structural assumption of ParseSchedule held
+type SelectorCheck struct {
+	Name     string `json:"name"`
+	Selector string `json:"selector"`
+	Matches  int    `json:"matches"`
+	Total    int    `json:"total"`
+	Required bool   `json:"required"` // ParseSchedule cannot produce a course without it
+}
+
+// OK reports whether the selector matched in every popover
+func (s SelectorCheck) OK() bool {
+	return s.Total > 0 && s.Matches == s.Total
+}
+
+// SchemaReport describes how well a fetched page matches the structure the parsers expect
+type SchemaReport struct {
+	Checks      []SelectorCheck   `json:"checks"`
+	Popovers    int               `json:"popovers"`
+	Courses     int               `json:"courses"`
+	Diagnostics []ParseDiagnostic `json:"diagnostics,omitempty"`
+	Errors      []string          `json:"errors,omitempty"`   // Problems that make the parser return nothing useful
+	Warnings    []string          `json:"warnings,omitempty"` // Partial breakage, e.g. some popovers lost their room
+}
+
+// Healthy reports whether the page can be parsed. With strict set, warnings count as failures too.
+func (r *SchemaReport) Healthy(strict bool) bool {
+	if len(r.Errors) > 0 {
+		return false
+	}
+	return !strict || len(r.Warnings) == 0
+}
+
+// popoverChecks lists the per-popover selectors ParseSchedule relies on
+var popoverChecks = []struct {
+	name     string
+	selector string
+	required bool
+	match    func(*goquery.Selection) bool
+}{
+	{"course title", ".header p.title", true, func(s *goquery.Selection) bool {
+		return strings.TrimSpace(s.Find(".header p.title").Text()) != ""
+	}},
+	{"course type", ".header p.description", false, func(s *goquery.Selection) bool {
+		return s.Find(".header p.description").Length() > 0
+	}},
+	{"content parts", ".content .part", true, func(s *goquery.Selection) bool {
+		return s.Find(".content .part").Length() > 0
+	}},
+	{"date/time part", ".content .part img[src*=clock]", true, func(s *goquery.Selection) bool {
+		return partWithIcon(s, "clock") != nil
+	}},
+	{"room part", ".content .part img[src*=map-marker]", false, func(s *goquery.Selection) bool {
+		return partWithIcon(s, "map-marker") != nil
+	}},
+	{"group part", ".content .part img[src*=group|info-circle]", false, func(s *goquery.Selection) bool {
+		return partWithIcon(s, "group") != nil || partWithIcon(s, "info-circle") != nil
+	}},
+}
+
+func partWithIcon(popover *goquery.Selection, icon string) *goquery.Selection {
+	var found *goquery.Selection
+	popover.Find(".content .part").EachWithBreak(func(i int, part *goquery.Selection) bool {
+		src, _ := part.Find("img").Attr("src")
+		if strings.Contains(src, icon) {
+			found = part
+			return false
+		}
+		return true
+	})
+	return found
+}
+
+// CheckSchedulePage inspects a group schedule page and reports which of the selectors
+// ParseSchedule depends on matched, and how many popovers had missing fields
+func CheckSchedulePage(r io.Reader) (*SchemaReport, error) {
+	// The page is parsed twice: once structurally and once through the real parser
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	doc, err := goquery.NewDocumentFromReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+
+	report := &SchemaReport{}
+	popovers := doc.Find("div.event-popover")
+	report.Popovers = popovers.Length()
+
+	for _, pc := range popoverChecks {
+		check := SelectorCheck{
+			Name:     pc.name,
+			Selector: "div.event-popover " + pc.selector,
+			Total:    report.Popovers,
+			Required: pc.required,
+		}
+		popovers.Each(func(i int, s *goquery.Selection) {
+			if pc.match(s) {
+				check.Matches++
+			}
+		})
+		report.Checks = append(report.Checks, check)
+	}
+
+	parsed, err := ParseScheduleDetailed(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	report.Courses = len(parsed.Courses)
+	report.Diagnostics = parsed.Diagnostics
+
+	if report.Popovers == 0 {
+		report.Errors = append(report.Errors, "no div.event-popover elements found: either the layout changed or the group has no published events")
+		return report, nil
+	}
+
+	for _, check := range report.Checks {
+		missing := check.Total - check.Matches
+		if missing == 0 {
+			continue
+		}
+		msg := fmt.Sprintf("%d of %d popovers have no %s (%s)", missing, check.Total, check.Name, check.Selector)
+		if check.Required && check.Matches == 0 {
+			report.Errors = append(report.Errors, msg)
+		} else {
+			report.Warnings = append(report.Warnings, msg)
+		}
+	}
+
+	if report.Courses == 0 {
+		report.Errors = append(report.Errors, fmt.Sprintf("%d popovers found but none could be parsed into a course", report.Popovers))
+	} else if len(report.Diagnostics) > 0 {
+		report.Warnings = append(report.Warnings, fmt.Sprintf("%d popovers were dropped by the parser", len(report.Diagnostics)))
+	}
+
+	return report, nil
+}
+
+// CheckGroupsPage inspects schedule.html and verifies that FetchGroups can find the group selector
+func CheckGroupsPage(r io.Reader) (*SchemaReport, error) {
+	doc, err := goquery.NewDocumentFromReader(r)
+	if err != nil {
+		return nil, err
+	}
+
+	selects := doc.Find("select#group").Length()
+	options := 0
+	doc.Find("select#group option").Each(func(i int, sel *goquery.Selection) {
+		if val, ok := sel.Attr("value"); ok && val != "" {
+			options++
+		}
+	})
+
+	report := &SchemaReport{
+		Checks: []SelectorCheck{
+			{Name: "group selector", Selector: "select#group", Matches: selects, Total: 1, Required: true},
+			{Name: "group options", Selector: "select#group option[value]", Matches: options, Total: options, Required: true},
+		},
+	}
+
+	if selects == 0 {
+		report.Errors = append(report.Errors, "no select#group element found: the group list moved")
+	} else if options == 0 {
+		report.Errors = append(report.Errors, "select#group has no options with a value: no groups can be listed")
+	}
+	return report, nil
+}
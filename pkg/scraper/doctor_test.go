@@ -0,0 +1,85 @@
+package scraper
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestCheckSchedulePage_Fixture(t *testing.T) {
+	data, err := os.ReadFile("testdata/161902.html")
+	if err != nil {
+		t.Fatalf("failed to read fixture: %v", err)
+	}
+
+	report, err := CheckSchedulePage(strings.NewReader(string(data)))
+	if err != nil {
+		t.Fatalf("CheckSchedulePage failed: %v", err)
+	}
+
+	if report.Popovers != 4 || report.Courses != 3 {
+		t.Errorf("expected 4 popovers and 3 courses, got %d and %d", report.Popovers, report.Courses)
+	}
+	if len(report.Errors) != 0 {
+		t.Errorf("expected no errors for the fixture, got %v", report.Errors)
+	}
+	if !report.Healthy(false) {
+		t.Errorf("expected the fixture to be healthy")
+	}
+
+	// Two popovers in the fixture have no group part, which only strict mode rejects
+	if report.Healthy(true) {
+		t.Errorf("expected strict mode to fail on the missing group parts")
+	}
+	for _, check := range report.Checks {
+		if check.Name == "group part" && check.Matches != 2 {
+			t.Errorf("expected the group part in 2 of 4 popovers, got %d", check.Matches)
+		}
+	}
+}
+
+func TestCheckSchedulePage_RenamedIcon(t *testing.T) {
+	data, _ := os.ReadFile("testdata/161902.html")
+	drifted := strings.ReplaceAll(string(data), "clock.svg", "time.svg")
+
+	report, err := CheckSchedulePage(strings.NewReader(drifted))
+	if err != nil {
+		t.Fatalf("CheckSchedulePage failed: %v", err)
+	}
+
+	if report.Healthy(false) {
+		t.Fatalf("expected a renamed clock icon to be fatal")
+	}
+
+	joined := strings.Join(report.Errors, "\n")
+	if !strings.Contains(joined, "4 of 4 popovers have no date/time part") {
+		t.Errorf("expected a precise diagnosis of the missing clock part, got:\n%s", joined)
+	}
+	if !strings.Contains(joined, "none could be parsed") {
+		t.Errorf("expected the zero-course result to be reported, got:\n%s", joined)
+	}
+}
+
+func TestCheckSchedulePage_NoPopovers(t *testing.T) {
+	report, err := CheckSchedulePage(strings.NewReader(`<html><body><div class="calendar"></div></body></html>`))
+	if err != nil {
+		t.Fatalf("CheckSchedulePage failed: %v", err)
+	}
+	if report.Healthy(false) || len(report.Errors) != 1 {
+		t.Errorf("expected exactly one error for a page without popovers, got %v", report.Errors)
+	}
+}
+
+func TestCheckGroupsPage(t *testing.T) {
+	good := `<select id="group"><option value="">Bitte wählen</option><option value="161902.html">DT</option></select>`
+	report, err := CheckGroupsPage(strings.NewReader(good))
+	if err != nil || !report.Healthy(true) {
+		t.Errorf("expected the group list to be healthy, got %+v (%v)", report, err)
+	}
+
+	moved := `<select id="studiengang"><option value="161902.html">DT</option></select>`
+	report, err = CheckGroupsPage(strings.NewReader(moved))
+	if err != nil || report.Healthy(false) {
+		t.Errorf("expected a renamed select to be fatal, got %+v (%v)", report, err)
+	}
+}
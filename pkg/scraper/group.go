@@ -1,6 +1,8 @@
 package scraper
 
 import (
+	"context"
+	"errors"
 	"strings"
 
 	"github.com/PuerkitoBio/goquery"
@@ -8,7 +10,12 @@ import (
 
 // FetchGroups retrieves all the available groups from the main schedule.html page
 func (c *Client) FetchGroups() ([]Group, error) {
-	resp, err := c.Get("schedule.html")
+	return c.FetchGroupsContext(context.Background())
+}
+
+// FetchGroupsContext is FetchGroups with a context that cancels the request
+func (c *Client) FetchGroupsContext(ctx context.Context) ([]Group, error) {
+	resp, err := c.GetContext(ctx, "schedule.html")
 	if err != nil {
 		return nil, err
 	}
@@ -19,10 +26,14 @@ func (c *Client) FetchGroups() ([]Group, error) {
 		return nil, err
 	}
 
-	var groups []Group
-
 	// The groups are stored as <option> tags inside a <select id="group">
-	doc.Find("select#group option").Each(func(i int, sel *goquery.Selection) {
+	sel := doc.Find("select#group")
+	if sel.Length() == 0 {
+		return nil, &ParseError{URL: c.url("schedule.html"), Err: errors.New("no select#group element found: the group list moved")}
+	}
+
+	var groups []Group
+	sel.Find("option").Each(func(i int, sel *goquery.Selection) {
 		val, exists := sel.Attr("value")
 		if exists && val != "" {
 			name := strings.TrimSpace(sel.Text())
@@ -1,14 +1,19 @@
 package scraper
 
 import (
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
+
+	"faliactl/pkg/fixture/fixturetest"
 )
 
-// TestScraperIntegration actually connects to the Ostfalia web server.
-// If this test fails, it means the University changed their HTML structure or the server is down.
+// TestScraperIntegration_FetchGroups parses the group list of the Ostfalia web server, replayed
+// from testdata/fixtures. With FALIACTL_LIVE=1 it connects for real; if it fails then, the
+// University changed their HTML structure or the server is down.
 func TestScraperIntegration_FetchGroups(t *testing.T) {
-	client := NewClient()
+	client := newIntegrationClient(t)
 
 	groups, err := client.FetchGroups()
 	if err != nil {
@@ -36,9 +41,14 @@ func TestScraperIntegration_FetchGroups(t *testing.T) {
 	}
 }
 
-// TestScraperIntegration_FetchSchedule actually connects to a specific Ostfalia schedule page.
+// TestScraperIntegration_FetchSchedule parses a specific Ostfalia schedule page, replayed from
+// testdata/fixtures unless FALIACTL_LIVE is set.
 func TestScraperIntegration_FetchSchedule(t *testing.T) {
-	client := NewClient()
+	// Use an empty cache so the page really goes through the client
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("USERPROFILE", os.Getenv("HOME"))
+
+	client := newIntegrationClient(t)
 
 	// 161902.html is historically the "Digital Technologies" schedule endpoint we've used for testing
 	// We just want to make sure the endpoint parses *something* without crashing and returning 0 courses.
@@ -52,8 +62,13 @@ func TestScraperIntegration_FetchSchedule(t *testing.T) {
 	if len(courses) > 0 {
 		// Just verify the first course has basic required fields populated
 		c := courses[0]
-		if c.Name == "" || c.StartTime == "" || c.EndTime == "" {
+		if c.Name == "" || c.StartTime == "" || c.EndTime == "" || c.Start.IsZero() || c.End.IsZero() {
 			t.Errorf("Parsed course is missing critical fields: %+v", c)
 		}
 	}
 }
+
+// newIntegrationClient replays testdata/fixtures unless FALIACTL_LIVE is set
+func newIntegrationClient(t *testing.T) *Client {
+	return NewClient(WithHTTPClient(fixturetest.Client(t, filepath.Join("testdata", "fixtures"))))
+}
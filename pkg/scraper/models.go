@@ -1,5 +1,10 @@
 package scraper
 
+import (
+	"fmt"
+	"time"
+)
+
 // Group represents a study group or program (e.g., "Bachelor of Science Digital Technologies")
 type Group struct {
 	Name string
@@ -10,9 +15,17 @@ type Group struct {
 type Course struct {
 	Name      string
 	Type      string
-	DateStr   string // Raw string e.g. "04.03.2026 (Mittwoch)"
-	StartTime string // "08:15"
-	EndTime   string // "09:45"
-	Room      string // "WF-EX-7/3"
-	GroupStr  string // Which groups this course belongs to
+	DateStr   string    // Raw string e.g. "04.03.2026 (Mittwoch)"
+	StartTime string    // "08:15"
+	EndTime   string    // "09:45"
+	Start     time.Time // DateStr + StartTime in Europe/Berlin
+	End       time.Time // DateStr + EndTime in Europe/Berlin
+	Room      string    // "WF-EX-7/3"
+	GroupStr  string    // Which groups this course belongs to
+}
+
+// Key identifies a lecture slot: the same course at the same date and time. It is used to
+// deduplicate popovers and to pair courses across schedule snapshots.
+func (c Course) Key() string {
+	return fmt.Sprintf("%s|%s|%s|%s", c.Name, c.DateStr, c.StartTime, c.EndTime)
 }
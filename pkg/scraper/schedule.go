@@ -1,30 +1,69 @@
 package scraper
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"io"
+	"net/http"
 	"strings"
+	"time"
 
 	"github.com/PuerkitoBio/goquery"
 )
 
+// ParseDiagnostic explains why a single popover could not be turned into a Course
+type ParseDiagnostic struct {
+	Index  int    `json:"index"`          // Position of the popover on the page, starting at 0
+	Name   string `json:"name,omitempty"` // Course title, if one was found
+	Reason string `json:"reason"`
+}
+
+func (d ParseDiagnostic) String() string {
+	name := d.Name
+	if name == "" {
+		name = "<untitled>"
+	}
+	return fmt.Sprintf("popover #%d (%s): %s", d.Index, name, d.Reason)
+}
+
+// ParseResult holds the courses of a schedule page together with everything that was skipped
+type ParseResult struct {
+	Courses     []Course
+	Popovers    int
+	Diagnostics []ParseDiagnostic
+}
+
 // ParseSchedule parses the individual schedule HTML content to extract the courses.
 func ParseSchedule(r io.Reader) ([]Course, error) {
+	result, err := ParseScheduleDetailed(r)
+	if err != nil {
+		return nil, err
+	}
+	return result.Courses, nil
+}
+
+// ParseScheduleDetailed parses a schedule page like ParseSchedule, but also reports
+// every popover that was dropped and why.
+func ParseScheduleDetailed(r io.Reader) (*ParseResult, error) {
 	doc, err := goquery.NewDocumentFromReader(r)
 	if err != nil {
 		return nil, err
 	}
 
+	result := &ParseResult{}
 	var courses []Course
 
 	// The detailed course info is located within div.event-popover elements
 	doc.Find("div.event-popover").Each(func(i int, sel *goquery.Selection) {
+		result.Popovers++
+
 		// Header info
 		header := sel.Find(".header")
 		name := strings.TrimSpace(header.Find("p.title").Text())
 		courseType := strings.TrimSpace(header.Find("p.description").Text())
 
-		var dateStr, startTime, endTime, room, groupStr string
+		var dateStr, timeStr, startTime, endTime, room, groupStr string
 
 		// Content parts
 		sel.Find(".content .part").Each(func(j int, part *goquery.Selection) {
@@ -32,7 +71,7 @@ func ParseSchedule(r io.Reader) ([]Course, error) {
 
 			if strings.Contains(icon, "clock") {
 				dateStr = strings.TrimSpace(part.Find(".item p.title").Text())
-				timeStr := strings.TrimSpace(part.Find(".item p.description").Text())
+				timeStr = strings.TrimSpace(part.Find(".item p.description").Text())
 
 				// e.g. "08:15 Uhr - 09:45 Uhr"
 				timeParts := strings.Split(timeStr, "-")
@@ -52,40 +91,177 @@ func ParseSchedule(r io.Reader) ([]Course, error) {
 			}
 		})
 
-		// Append the course if it has valid time info
-		if dateStr != "" && startTime != "" && endTime != "" {
-			courses = append(courses, Course{
-				Name:      name,
-				Type:      courseType,
-				DateStr:   dateStr,
-				StartTime: startTime,
-				EndTime:   endTime,
-				Room:      room,
-				GroupStr:  groupStr,
-			})
+		skip := func(reason string) {
+			result.Diagnostics = append(result.Diagnostics, ParseDiagnostic{Index: i, Name: name, Reason: reason})
+		}
+
+		// Append the course only if it has valid time info
+		switch {
+		case dateStr == "":
+			skip("missing date (no clock part)")
+			return
+		case startTime == "" || endTime == "":
+			skip(fmt.Sprintf("unrecognized time range %q", timeStr))
+			return
 		}
+
+		start, end, err := ParseCourseTimes(dateStr, startTime, endTime)
+		if err != nil {
+			skip(err.Error())
+			return
+		}
+
+		courses = append(courses, Course{
+			Name:      name,
+			Type:      courseType,
+			DateStr:   dateStr,
+			StartTime: startTime,
+			EndTime:   endTime,
+			Start:     start,
+			End:       end,
+			Room:      room,
+			GroupStr:  groupStr,
+		})
 	})
 
-	return deduplicateCourses(courses), nil
+	result.Courses = deduplicateCourses(courses)
+	return result, nil
 }
 
-// FetchSchedule downloads and parses the schedule for a given group URL
+// CacheStatus describes where the courses returned by FetchScheduleWithStatus came from
+type CacheStatus int
+
+const (
+	// CacheMiss means the page was downloaded and parsed from scratch
+	CacheMiss CacheStatus = iota
+	// CacheHit means an unexpired cache entry was served without contacting the intranet
+	CacheHit
+	// CacheRevalidated means the intranet answered 304 Not Modified to a conditional request
+	CacheRevalidated
+	// CacheStale means the intranet was unreachable and an expired entry was served instead
+	CacheStale
+)
+
+func (s CacheStatus) String() string {
+	switch s {
+	case CacheHit:
+		return "hit"
+	case CacheRevalidated:
+		return "revalidated"
+	case CacheStale:
+		return "stale"
+	default:
+		return "miss"
+	}
+}
+
+// FetchSchedule downloads and parses the schedule for a given group URL, serving from the local cache when possible
 func (c *Client) FetchSchedule(groupURL string) ([]Course, error) {
-	if cachedCourses, ok := readCache(groupURL); ok {
-		return cachedCourses, nil
+	return c.FetchScheduleContext(context.Background(), groupURL)
+}
+
+// FetchScheduleContext is FetchSchedule with a context that cancels the request
+func (c *Client) FetchScheduleContext(ctx context.Context, groupURL string) ([]Course, error) {
+	courses, _, err := c.FetchScheduleWithStatusContext(ctx, groupURL)
+	return courses, err
+}
+
+// FetchScheduleWithStatus behaves like FetchSchedule and additionally reports how the cache was used.
+// Expired entries are revalidated with If-None-Match/If-Modified-Since, and if the intranet
+// cannot be reached they are served as-is for up to a week.
+func (c *Client) FetchScheduleWithStatus(groupURL string) ([]Course, CacheStatus, error) {
+	return c.FetchScheduleWithStatusContext(context.Background(), groupURL)
+}
+
+// FetchScheduleWithStatusContext is FetchScheduleWithStatus with a context that cancels the
+// request. Only an unavailable intranet falls back to stale data; a cancelled request, a
+// deleted group or a page that no longer parses is returned as an error.
+func (c *Client) FetchScheduleWithStatusContext(ctx context.Context, groupURL string) ([]Course, CacheStatus, error) {
+	entry, _ := LoadCachedSchedule(groupURL)
+	if entry != nil && entry.Fresh() {
+		return entry.Courses, CacheHit, nil
 	}
 
-	resp, err := c.Get(groupURL)
+	courses, status, err := c.revalidate(ctx, groupURL, entry)
 	if err != nil {
-		return nil, err
+		if ctx.Err() == nil && errors.Is(err, ErrUpstreamUnavailable) && entry.Servable() {
+			return entry.Courses, CacheStale, nil
+		}
+		return nil, CacheMiss, err
+	}
+	return courses, status, nil
+}
+
+// RefreshSchedule ignores any cached copy, downloads the page unconditionally and rewrites the cache entry
+func (c *Client) RefreshSchedule(groupURL string) ([]Course, error) {
+	return c.RefreshScheduleContext(context.Background(), groupURL)
+}
+
+// RefreshScheduleContext is RefreshSchedule with a context that cancels the request
+func (c *Client) RefreshScheduleContext(ctx context.Context, groupURL string) ([]Course, error) {
+	courses, _, err := c.revalidate(ctx, groupURL, nil)
+	return courses, err
+}
+
+// RevalidateSchedule always asks the intranet, even when the cache entry is still fresh, but sends
+// the cached validators so an unchanged page only costs a 304. Used by long-running watchers.
+func (c *Client) RevalidateSchedule(groupURL string) ([]Course, CacheStatus, error) {
+	return c.RevalidateScheduleContext(context.Background(), groupURL)
+}
+
+// RevalidateScheduleContext is RevalidateSchedule with a context that cancels the request
+func (c *Client) RevalidateScheduleContext(ctx context.Context, groupURL string) ([]Course, CacheStatus, error) {
+	entry, _ := LoadCachedSchedule(groupURL)
+	return c.revalidate(ctx, groupURL, entry)
+}
+
+// revalidate fetches the group page, sending conditional headers when a previous entry is known,
+// and writes successful results through to the cache
+func (c *Client) revalidate(ctx context.Context, groupURL string, previous *CacheEntry) ([]Course, CacheStatus, error) {
+	header := http.Header{}
+	if previous != nil {
+		if previous.ETag != "" {
+			header.Set("If-None-Match", previous.ETag)
+		}
+		if previous.LastModified != "" {
+			header.Set("If-Modified-Since", previous.LastModified)
+		}
+	}
+
+	resp, err := c.get(ctx, groupURL, header)
+	if err != nil {
+		return nil, CacheMiss, err
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != 200 {
-		return nil, fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	if resp.StatusCode == http.StatusNotModified {
+		previous.Timestamp = time.Now()
+		_ = writeCacheEntry(groupURL, previous)
+		return previous.Courses, CacheRevalidated, nil
+	}
+
+	result, err := ParseScheduleDetailed(resp.Body)
+	if err != nil {
+		return nil, CacheMiss, err
+	}
+	// Popovers without a single parseable course mean the layout changed, not an empty week
+	if result.Popovers > 0 && len(result.Courses) == 0 {
+		return nil, CacheMiss, &ParseError{URL: c.url(groupURL),
+			Err: fmt.Errorf("%d popovers found but none could be parsed into a course", result.Popovers)}
+	}
+	courses := result.Courses
+
+	// An empty parse is not cached so a transient layout problem can't wipe out a good snapshot
+	if len(courses) > 0 {
+		_ = writeCacheEntry(groupURL, &CacheEntry{
+			Timestamp:    time.Now(),
+			ETag:         resp.Header.Get("ETag"),
+			LastModified: resp.Header.Get("Last-Modified"),
+			Courses:      courses,
+		})
 	}
 
-	return ParseSchedule(resp.Body)
+	return courses, CacheMiss, nil
 }
 
 // deduplicateCourses removes duplicate course entries since the same popover might be listed multiple times if it spans multiple weeks, although usually they have distinct IDs. Adding just in case.
@@ -94,7 +270,7 @@ func deduplicateCourses(courses []Course) []Course {
 	var unique []Course
 
 	for _, c := range courses {
-		key := fmt.Sprintf("%s|%s|%s|%s", c.Name, c.DateStr, c.StartTime, c.EndTime)
+		key := c.Key()
 		if !seen[key] {
 			seen[key] = true
 			unique = append(unique, c)
@@ -103,18 +279,3 @@ func deduplicateCourses(courses []Course) []Course {
 
 	return unique
 }
-
-// GetCampusAddress returns the physical street address of an Ostfalia campus based on the room name prefix.
-func GetCampusAddress(room string) string {
-	// Rooms are sometimes prefixed with cities (e.g. WF-EX-2/127 for Wolfenbüttel Am Exer)
-	roomUpper := strings.ToUpper(room)
-	if strings.Contains(roomUpper, "SZ") {
-		return "Karl-Scharfenberg-Straße 55-57, 38229 Salzgitter"
-	} else if strings.Contains(roomUpper, "SUD") {
-		return "Herbert-Meyer-Straße 7, 29556 Suderburg"
-	} else if strings.Contains(roomUpper, "-EX-") || strings.HasPrefix(roomUpper, "EX") {
-		return "Am Exer 2, 38302 Wolfenbüttel"
-	}
-	// Default to Wolfenbüttel Hauptcampus
-	return "Salzdahlumer Str. 46/48, 38302 Wolfenbüttel"
-}
@@ -2,13 +2,14 @@ package scraper
 
 import (
 	"os"
+	"strings"
 	"testing"
 )
 
 func TestParseSchedule(t *testing.T) {
-	file, err := os.Open("161902_test.html")
+	file, err := os.Open("testdata/161902.html")
 	if err != nil {
-		t.Skip("161902_test.html not found, skipping test")
+		t.Fatalf("failed to open fixture: %v", err)
 	}
 	defer file.Close()
 
@@ -39,4 +40,63 @@ func TestParseSchedule(t *testing.T) {
 	if !foundLinearAlg {
 		t.Errorf("Failed to find 'Lineare Algebra' at 08:15")
 	}
+
+	// The duplicated Lineare Algebra popover must be collapsed
+	if len(courses) != 3 {
+		t.Errorf("Expected 3 unique courses, got %d", len(courses))
+	}
+}
+
+func TestParseScheduleDetailed_Diagnostics(t *testing.T) {
+	html := `<html><body>
+<div class="event-popover">
+  <div class="header"><p class="title">Good</p></div>
+  <div class="content">
+    <div class="part"><img src="clock.svg"><div class="item"><p class="title">04.03.2026 (Mittwoch)</p><p class="description">08:15 Uhr - 09:45 Uhr</p></div></div>
+  </div>
+</div>
+<div class="event-popover">
+  <div class="header"><p class="title">No Clock</p></div>
+  <div class="content">
+    <div class="part"><img src="map-marker.svg"><div class="item"><p class="title">WF-EX-7/3</p></div></div>
+  </div>
+</div>
+<div class="event-popover">
+  <div class="header"><p class="title">Broken Time</p></div>
+  <div class="content">
+    <div class="part"><img src="clock.svg"><div class="item"><p class="title">04.03.2026 (Mittwoch)</p><p class="description">ganztägig</p></div></div>
+  </div>
+</div>
+<div class="event-popover">
+  <div class="header"><p class="title">Bad Date</p></div>
+  <div class="content">
+    <div class="part"><img src="clock.svg"><div class="item"><p class="title">31.02.2026 (Dienstag)</p><p class="description">08:15 Uhr - 09:45 Uhr</p></div></div>
+  </div>
+</div>
+</body></html>`
+
+	result, err := ParseScheduleDetailed(strings.NewReader(html))
+	if err != nil {
+		t.Fatalf("ParseScheduleDetailed failed: %v", err)
+	}
+
+	if result.Popovers != 4 {
+		t.Errorf("expected 4 popovers, got %d", result.Popovers)
+	}
+	if len(result.Courses) != 1 || result.Courses[0].Name != "Good" {
+		t.Fatalf("expected only the valid course, got %+v", result.Courses)
+	}
+	if result.Courses[0].Start.IsZero() || result.Courses[0].Start.Location() != Berlin {
+		t.Errorf("expected a typed start time in Europe/Berlin, got %v", result.Courses[0].Start)
+	}
+
+	if len(result.Diagnostics) != 3 {
+		t.Fatalf("expected 3 diagnostics, got %+v", result.Diagnostics)
+	}
+	for i, name := range []string{"No Clock", "Broken Time", "Bad Date"} {
+		d := result.Diagnostics[i]
+		if d.Name != name || d.Index != i+1 || d.Reason == "" {
+			t.Errorf("unexpected diagnostic %d: %+v", i, d)
+		}
+	}
 }
@@ -0,0 +1,72 @@
+package scraper
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	_ "time/tzdata" // the intranet publishes German wall-clock times; don't depend on the host's zoneinfo
+)
+
+// Berlin is the timezone every course time is expressed in
+var Berlin = loadBerlin()
+
+const (
+	dateLayout  = "02.01.2006"
+	clockLayout = "15:04"
+)
+
+func loadBerlin() *time.Location {
+	loc, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		// Unreachable with the embedded tzdata
+		panic(fmt.Sprintf("could not load Europe/Berlin timezone: %v", err))
+	}
+	return loc
+}
+
+// ParseCourseTimes turns the raw date ("04.03.2026 (Mittwoch)") and clock ("08:15") strings
+// of a popover into absolute start and end times in Europe/Berlin
+func ParseCourseTimes(dateStr, startTime, endTime string) (time.Time, time.Time, error) {
+	fields := strings.Fields(dateStr)
+	if len(fields) == 0 {
+		return time.Time{}, time.Time{}, fmt.Errorf("missing date")
+	}
+	day := fields[0]
+
+	start, err := time.ParseInLocation(dateLayout+" "+clockLayout, day+" "+startTime, Berlin)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid start %q %q: %w", day, startTime, err)
+	}
+
+	end, err := time.ParseInLocation(dateLayout+" "+clockLayout, day+" "+endTime, Berlin)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid end %q %q: %w", day, endTime, err)
+	}
+
+	if end.Before(start) {
+		return time.Time{}, time.Time{}, fmt.Errorf("ends at %s before it starts at %s", endTime, startTime)
+	}
+
+	return start, end, nil
+}
+
+// Times returns the course's start and end, parsing the raw strings when the typed fields are unset
+// (for example on hand-built courses or cache files written by older versions)
+func (c Course) Times() (time.Time, time.Time, error) {
+	if !c.Start.IsZero() && !c.End.IsZero() {
+		return c.Start.In(Berlin), c.End.In(Berlin), nil
+	}
+	return ParseCourseTimes(c.DateStr, c.StartTime, c.EndTime)
+}
+
+// normalizeTimes fills in Start/End for courses decoded from old cache files and pins them to Berlin
+func normalizeTimes(courses []Course) {
+	for i := range courses {
+		start, end, err := courses[i].Times()
+		if err != nil {
+			continue
+		}
+		courses[i].Start = start
+		courses[i].End = end
+	}
+}
@@ -0,0 +1,48 @@
+package scraper
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseCourseTimes(t *testing.T) {
+	start, end, err := ParseCourseTimes("04.03.2026 (Mittwoch)", "08:15", "09:45")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// 04-Mar-2026 08:15 Berlin time is 07:15 UTC (CET, +1)
+	if got := start.UTC().Format("2006-01-02 15:04"); got != "2026-03-04 07:15" {
+		t.Errorf("expected start 2026-03-04 07:15 UTC, got %s", got)
+	}
+	if end.Sub(start) != 90*time.Minute {
+		t.Errorf("expected a 90 minute course, got %v", end.Sub(start))
+	}
+
+	// After the switch to summer time Berlin is UTC+2
+	summer, _, err := ParseCourseTimes("15.04.2026 (Mittwoch)", "08:15", "09:45")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := summer.UTC().Format("15:04"); got != "06:15" {
+		t.Errorf("expected 06:15 UTC during CEST, got %s", got)
+	}
+}
+
+func TestParseCourseTimes_Invalid(t *testing.T) {
+	cases := []struct {
+		date, start, end string
+	}{
+		{"", "08:15", "09:45"},
+		{"04.03.2026", "8 Uhr", "09:45"},
+		{"04.03.2026", "08:15", ""},
+		{"2026-03-04", "08:15", "09:45"},
+		{"04.03.2026", "10:00", "09:45"},
+	}
+
+	for _, tc := range cases {
+		if _, _, err := ParseCourseTimes(tc.date, tc.start, tc.end); err == nil {
+			t.Errorf("expected an error for %q %q-%q", tc.date, tc.start, tc.end)
+		}
+	}
+}
@@ -0,0 +1,397 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"faliactl/pkg/exporter"
+	"faliactl/pkg/scraper"
+	"faliactl/pkg/sets"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// DefaultRefreshInterval is how often compiled calendars are rebuilt in the background
+const DefaultRefreshInterval = time.Hour
+
+// retryAfter is suggested to clients when the intranet is unavailable
+const retryAfter = 5 * time.Minute
+
+// idleEviction drops calendars nobody asked for in this long from the in-memory cache
+const idleEviction = 7 * 24 * time.Hour
+
+// errNoCourses is returned when a calendar compiles to zero events
+var errNoCourses = errors.New("no courses found")
+
+// Server serves compiled calendars for groups and sets in every registered export format. Calendars are compiled once,
+// kept in memory and rebuilt by a background refresher, so subscribers polling the server
+// never reach the intranet directly.
+type Server struct {
+	Client          *scraper.Client
+	Sets            *sets.Store // May be nil when no sets are configured
+	CancelGrace     time.Duration
+	RefreshInterval time.Duration
+	// Logf receives request and refresh logs (defaults to discarding them)
+	Logf func(format string, args ...any)
+
+	mu        sync.Mutex
+	calendars map[string]*calendar
+	flight    singleflight.Group // Per identifier and format: one rendering at a time
+	fetches   singleflight.Group // Per group page: one intranet request for every format and set
+}
+
+// calendar is one compiled document of a group or set in one export format
+type calendar struct {
+	identifier string
+	format     exporter.Exporter
+	body       []byte
+	etag       string
+	modified   time.Time // When the body last changed, for Last-Modified
+	compiled   time.Time
+	lastAccess time.Time
+}
+
+// ServeHTTP serves /<group_or_set>.<format> and /health. The format is any registered exporter
+// name, e.g. ics, json or html; paths without a known extension are served as ICS.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path == "/health" {
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("ok"))
+		return
+	}
+
+	// Extract identifier from path
+	path := strings.TrimPrefix(r.URL.Path, "/")
+	if path == "" || path == "favicon.ico" || strings.Contains(path, "/") {
+		http.NotFound(w, r)
+		return
+	}
+	identifier, format := splitFormat(path)
+	s.logf("Received request for identifier %s (%s) from %s", identifier, format.Name(), r.RemoteAddr)
+
+	cal, err := s.calendar(r.Context(), identifier, format)
+	if err != nil {
+		if r.Context().Err() != nil {
+			return // The client is gone, nobody reads the answer
+		}
+		if errors.Is(err, errNoCourses) || errors.Is(err, scraper.ErrNotFound) {
+			http.NotFound(w, r)
+			return
+		}
+		s.logf("Error compiling calendar %s: %v", identifier, err)
+		var parseErr *scraper.ParseError
+		switch {
+		case errors.Is(err, scraper.ErrUpstreamUnavailable):
+			// Calendar apps retry on their own; tell them when it is worth it
+			w.Header().Set("Retry-After", fmt.Sprintf("%d", int(retryAfter.Seconds())))
+			http.Error(w, "The intranet is unavailable, try again later", http.StatusServiceUnavailable)
+		case errors.As(err, &parseErr):
+			http.Error(w, "The intranet answered in an unexpected format", http.StatusBadGateway)
+		default:
+			http.Error(w, "Failed to fetch schedule", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("ETag", cal.etag)
+	w.Header().Set("Last-Modified", cal.modified.UTC().Format(http.TimeFormat))
+	// Encourage caching clients (like Google Calendar) not to over-poll
+	w.Header().Set("Cache-Control", fmt.Sprintf("public, max-age=%d", int(s.refreshInterval().Seconds())))
+
+	if notModified(r, cal) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	// Calendars and spreadsheets are downloads, the HTML week view is meant for the browser
+	w.Header().Set("Content-Type", format.ContentType())
+	disposition := "attachment"
+	if format.Name() == "html" {
+		disposition = "inline"
+	}
+	w.Header().Set("Content-Disposition", fmt.Sprintf("%s; filename=\"%s.%s\"", disposition, identifier, format.Name()))
+	if r.Method == http.MethodHead {
+		return
+	}
+	w.Write(cal.body)
+}
+
+// notModified evaluates If-None-Match, falling back to If-Modified-Since as RFC 9110 requires
+func notModified(r *http.Request, cal *calendar) bool {
+	if inm := r.Header.Get("If-None-Match"); inm != "" {
+		for _, tag := range strings.Split(inm, ",") {
+			tag = strings.TrimSpace(tag)
+			if tag == "*" || strings.TrimPrefix(tag, "W/") == cal.etag {
+				return true
+			}
+		}
+		return false
+	}
+	if ims := r.Header.Get("If-Modified-Since"); ims != "" {
+		if t, err := http.ParseTime(ims); err == nil {
+			return !cal.modified.Truncate(time.Second).After(t)
+		}
+	}
+	return false
+}
+
+// splitFormat separates a registered format extension from the requested path
+func splitFormat(path string) (string, exporter.Exporter) {
+	if i := strings.LastIndex(path, "."); i > 0 {
+		if format, ok := exporter.Lookup(path[i+1:]); ok {
+			return path[:i], format
+		}
+	}
+	return path, exporter.MustLookup("ics")
+}
+
+func cacheKey(identifier string, format exporter.Exporter) string {
+	return identifier + "." + format.Name()
+}
+
+// calendar returns the cached calendar, compiling it on first use. Concurrent first requests
+// for the same identifier and format share a single compilation.
+func (s *Server) calendar(ctx context.Context, identifier string, format exporter.Exporter) (*calendar, error) {
+	s.mu.Lock()
+	cal, ok := s.calendars[cacheKey(identifier, format)]
+	if ok {
+		cal.lastAccess = time.Now()
+	}
+	s.mu.Unlock()
+	if ok {
+		return cal, nil
+	}
+
+	s.logf("Compiling calendar %s as %s", identifier, format.Name())
+	return s.compile(ctx, identifier, format)
+}
+
+// compile rebuilds a calendar and stores it in the cache, deduplicating concurrent calls.
+// A cancelled ctx stops the wait, but not a compilation other callers may be waiting for:
+// that one keeps the values of ctx without its cancellation and still fills the cache.
+func (s *Server) compile(ctx context.Context, identifier string, format exporter.Exporter) (*calendar, error) {
+	key := cacheKey(identifier, format)
+	ch := s.flight.DoChan(key, func() (any, error) {
+		courses, partial, err := s.courses(context.WithoutCancel(ctx), identifier)
+		if err != nil {
+			return nil, err
+		}
+		if len(courses) == 0 {
+			return nil, errNoCourses
+		}
+
+		// All formats of an identifier share one state, so ICS and jCal publish the same SEQUENCE
+		var buf bytes.Buffer
+		err = exporter.ExportTracked("serve-"+identifier, format, courses, &buf, exporter.Options{
+			CancelGrace: s.CancelGrace,
+			Partial:     partial,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		sum := sha256.Sum256(buf.Bytes())
+		now := time.Now()
+		cal := &calendar{
+			identifier: identifier,
+			format:     format,
+			body:       buf.Bytes(),
+			etag:       `"` + hex.EncodeToString(sum[:16]) + `"`,
+			modified:   now,
+			compiled:   now,
+			lastAccess: now,
+		}
+
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if s.calendars == nil {
+			s.calendars = make(map[string]*calendar)
+		}
+		if prev, ok := s.calendars[key]; ok {
+			cal.lastAccess = prev.lastAccess
+			if prev.etag == cal.etag {
+				cal.modified = prev.modified
+			}
+		}
+		s.calendars[key] = cal
+		return cal, nil
+	})
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case res := <-ch:
+		if res.Err != nil {
+			return nil, res.Err
+		}
+		return res.Val.(*calendar), nil
+	}
+}
+
+// courses resolves an identifier (set name or group) into its deduplicated courses.
+// partial reports that some group of a set failed to load.
+func (s *Server) courses(ctx context.Context, identifier string) ([]scraper.Course, bool, error) {
+	set, ok := s.Sets.Lookup(identifier)
+	if !ok {
+		// Fallback: Treat as a single group URL
+		courses, err := s.fetch(ctx, scraper.GroupPath(identifier))
+		return courses, false, err
+	}
+
+	results := make([][]scraper.Course, len(set.Groups))
+	errs := make([]error, len(set.Groups))
+	var wg sync.WaitGroup
+	for i, group := range set.Groups {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i], errs[i] = s.fetch(ctx, scraper.GroupPath(group))
+		}()
+	}
+	wg.Wait()
+
+	courseMap := make(map[string]bool)
+	for _, name := range set.Courses {
+		courseMap[name] = true
+	}
+	courseFilter, err := set.Filter.Compile()
+	if err != nil {
+		return nil, false, fmt.Errorf("set %s: %w", identifier, err)
+	}
+
+	partial := false
+	seenEvent := make(map[string]bool)
+	var all []scraper.Course
+	for i, groupCourses := range results {
+		if errs[i] != nil {
+			s.logf("Error fetching schedule for group %s in set %s: %v", set.Groups[i], identifier, errs[i])
+			partial = true // Don't cancel this group's lectures just because it failed to load
+			continue
+		}
+		for _, c := range groupCourses {
+			// Filter courses if specific ones are defined
+			if len(courseMap) > 0 && !courseMap[c.Name] || !courseFilter.Keep(c) {
+				continue
+			}
+			c.Name = courseFilter.DisplayName(c.Name)
+			if key := c.Key(); !seenEvent[key] {
+				seenEvent[key] = true
+				all = append(all, c)
+			}
+		}
+	}
+
+	if partial && len(all) == 0 {
+		// A missing group is a mistake in the set rather than a missing calendar, so only an
+		// outage is passed on
+		for _, err := range errs {
+			if errors.Is(err, scraper.ErrUpstreamUnavailable) {
+				return nil, true, fmt.Errorf("no group of set %s could be fetched: %w", identifier, err)
+			}
+		}
+		return nil, true, fmt.Errorf("no group of set %s could be fetched", identifier)
+	}
+	return all, partial, nil
+}
+
+// fetch revalidates the group page with the intranet (usually a cheap 304) and falls back
+// to the disk cache, including stale entries, when the intranet is unreachable. The fallback
+// only reads the cache file, so an outage costs a single request per group. Concurrent
+// compilations that need the same group, in other formats or sets, share one fetch.
+func (s *Server) fetch(ctx context.Context, groupPath string) ([]scraper.Course, error) {
+	v, err, _ := s.fetches.Do(groupPath, func() (any, error) {
+		courses, _, err := s.Client.RevalidateScheduleContext(ctx, groupPath)
+		if err == nil {
+			return courses, nil
+		}
+		if errors.Is(err, scraper.ErrUpstreamUnavailable) {
+			if entry, cacheErr := scraper.LoadCachedSchedule(groupPath); cacheErr == nil && entry.Servable() {
+				s.logf("Serving cached %s: %v", groupPath, err)
+				return entry.Courses, nil
+			}
+		}
+		return nil, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return v.([]scraper.Course), nil
+}
+
+// Refresh recompiles every cached calendar once and evicts the ones nobody requested lately.
+// Calendars that fail to compile keep their previous version. Cancelling ctx stops the
+// refresh after the calendar being compiled.
+func (s *Server) Refresh(ctx context.Context) {
+	s.mu.Lock()
+	var stale []*calendar
+	for key, cal := range s.calendars {
+		if time.Since(cal.lastAccess) > idleEviction {
+			delete(s.calendars, key)
+			continue
+		}
+		stale = append(stale, cal)
+	}
+	s.mu.Unlock()
+
+	for _, cal := range stale {
+		if ctx.Err() != nil {
+			return
+		}
+		if _, err := s.compile(ctx, cal.identifier, cal.format); err != nil {
+			s.logf("Background refresh of %s failed, keeping the previous version: %v", cacheKey(cal.identifier, cal.format), err)
+		}
+	}
+}
+
+// Invalidate drops the compiled calendars of the identifiers in every format, e.g. after the
+// definition of a set changed. They are recompiled on their next request.
+func (s *Server) Invalidate(identifiers ...string) {
+	drop := make(map[string]bool, len(identifiers))
+	for _, id := range identifiers {
+		drop[id] = true
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for key, cal := range s.calendars {
+		if drop[cal.identifier] {
+			delete(s.calendars, key)
+		}
+	}
+}
+
+// RunRefresher calls Refresh every RefreshInterval until ctx is cancelled
+func (s *Server) RunRefresher(ctx context.Context) {
+	ticker := time.NewTicker(s.refreshInterval())
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.Refresh(ctx)
+		}
+	}
+}
+
+func (s *Server) refreshInterval() time.Duration {
+	if s.RefreshInterval <= 0 {
+		return DefaultRefreshInterval
+	}
+	return s.RefreshInterval
+}
+
+func (s *Server) logf(format string, args ...any) {
+	if s.Logf != nil {
+		s.Logf(format, args...)
+	}
+}
@@ -0,0 +1,314 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"faliactl/pkg/filter"
+	"faliactl/pkg/scraper"
+	"faliactl/pkg/sets"
+)
+
+// intranetStub serves the scraper test page for every group and counts the requests
+type intranetStub struct {
+	mu    sync.Mutex
+	page  string
+	hits  atomic.Int32
+	delay time.Duration
+	down  atomic.Bool // Answer every request with 503
+}
+
+func newIntranetStub(t *testing.T) (*intranetStub, *httptest.Server) {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+
+	page, err := os.ReadFile(filepath.Join("..", "scraper", "testdata", "161902.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	stub := &intranetStub{page: string(page)}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		stub.hits.Add(1)
+		time.Sleep(stub.delay)
+		if stub.down.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		if !strings.HasPrefix(r.URL.Path, "/161902") && !strings.HasPrefix(r.URL.Path, "/161903") {
+			http.NotFound(w, r)
+			return
+		}
+		stub.mu.Lock()
+		defer stub.mu.Unlock()
+		w.Write([]byte(stub.page))
+	}))
+	t.Cleanup(srv.Close)
+	return stub, srv
+}
+
+func get(t *testing.T, s *Server, path string, header map[string]string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	for k, v := range header {
+		req.Header.Set(k, v)
+	}
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestServer_ConditionalRequests(t *testing.T) {
+	stub, upstream := newIntranetStub(t)
+	s := &Server{Client: scraper.NewClient(scraper.WithBaseURL(upstream.URL))}
+
+	first := get(t, s, "/161902.ics", nil)
+	if first.Code != http.StatusOK || !strings.Contains(first.Body.String(), "BEGIN:VCALENDAR") {
+		t.Fatalf("expected a calendar, got %d: %s", first.Code, first.Body.String())
+	}
+	etag := first.Header().Get("ETag")
+	lastModified := first.Header().Get("Last-Modified")
+	if etag == "" || lastModified == "" {
+		t.Fatalf("missing validators: ETag=%q Last-Modified=%q", etag, lastModified)
+	}
+
+	if rec := get(t, s, "/161902.ics", map[string]string{"If-None-Match": etag}); rec.Code != http.StatusNotModified || rec.Body.Len() != 0 {
+		t.Errorf("expected 304 for matching ETag, got %d", rec.Code)
+	}
+	if rec := get(t, s, "/161902.ics", map[string]string{"If-Modified-Since": lastModified}); rec.Code != http.StatusNotModified {
+		t.Errorf("expected 304 for If-Modified-Since, got %d", rec.Code)
+	}
+	if rec := get(t, s, "/161902.ics", map[string]string{"If-None-Match": `"other"`}); rec.Code != http.StatusOK {
+		t.Errorf("expected 200 for a stale ETag, got %d", rec.Code)
+	}
+
+	// Only the first request may have reached the intranet
+	if hits := stub.hits.Load(); hits != 1 {
+		t.Errorf("expected 1 upstream request, got %d", hits)
+	}
+}
+
+func TestServer_Formats(t *testing.T) {
+	_, upstream := newIntranetStub(t)
+	s := &Server{Client: scraper.NewClient(scraper.WithBaseURL(upstream.URL))}
+
+	cases := map[string]string{
+		"/161902.json": "application/json",
+		"/161902.html": "text/html; charset=utf-8",
+		"/161902.jcal": "application/calendar+json",
+		"/161902.csv":  "text/csv; charset=utf-8",
+		"/161902.md":   "text/markdown; charset=utf-8",
+		"/161902":      "text/calendar; charset=utf-8",
+	}
+	for path, contentType := range cases {
+		rec := get(t, s, path, nil)
+		if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != contentType {
+			t.Errorf("%s: got %d %q, want 200 %q", path, rec.Code, rec.Header().Get("Content-Type"), contentType)
+		}
+	}
+
+	// Invalidating an identifier drops it in every format
+	s.Invalidate("161902")
+	if n := len(s.calendars); n != 0 {
+		t.Errorf("expected every format to be invalidated, %d calendars left", n)
+	}
+}
+
+func TestServer_SingleFlight(t *testing.T) {
+	stub, upstream := newIntranetStub(t)
+	stub.delay = 100 * time.Millisecond
+	s := &Server{Client: scraper.NewClient(scraper.WithBaseURL(upstream.URL))}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if rec := get(t, s, "/161902.ics", nil); rec.Code != http.StatusOK {
+				t.Errorf("expected 200, got %d", rec.Code)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if hits := stub.hits.Load(); hits != 1 {
+		t.Errorf("concurrent first requests should share one fetch, got %d upstream requests", hits)
+	}
+}
+
+func TestServer_SingleFlightAcrossFormatsAndSets(t *testing.T) {
+	stub, upstream := newIntranetStub(t)
+	stub.delay = 100 * time.Millisecond
+	s := &Server{
+		Client: scraper.NewClient(scraper.WithBaseURL(upstream.URL)),
+		Sets:   sets.StaticStore(&sets.File{Sets: map[string]sets.Set{"both": {Groups: []string{"161902"}}}}),
+	}
+
+	var wg sync.WaitGroup
+	for _, path := range []string{"/161902.ics", "/161902.json", "/161902.jcal", "/both.ics"} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if rec := get(t, s, path, nil); rec.Code != http.StatusOK {
+				t.Errorf("%s: expected 200, got %d", path, rec.Code)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if hits := stub.hits.Load(); hits != 1 {
+		t.Errorf("formats and sets of the same group should share one fetch, got %d upstream requests", hits)
+	}
+}
+
+func TestServer_RefreshPicksUpChanges(t *testing.T) {
+	stub, upstream := newIntranetStub(t)
+	s := &Server{Client: scraper.NewClient(scraper.WithBaseURL(upstream.URL))}
+
+	first := get(t, s, "/161902.ics", nil)
+	etag := first.Header().Get("ETag")
+
+	// Unchanged upstream: the ETag and Last-Modified stay the same
+	s.Refresh(context.Background())
+	again := get(t, s, "/161902.ics", nil)
+	if again.Header().Get("ETag") != etag || again.Header().Get("Last-Modified") != first.Header().Get("Last-Modified") {
+		t.Errorf("refresh without changes must keep the validators")
+	}
+
+	stub.mu.Lock()
+	stub.page = strings.Replace(stub.page, "WF-C-015", "WF-C-016", 1)
+	stub.mu.Unlock()
+
+	s.Refresh(context.Background())
+	changed := get(t, s, "/161902.ics", map[string]string{"If-None-Match": etag})
+	if changed.Code != http.StatusOK || !strings.Contains(changed.Body.String(), "WF-C-016") {
+		t.Errorf("expected the refreshed calendar, got %d", changed.Code)
+	}
+}
+
+func TestServer_Sets(t *testing.T) {
+	_, upstream := newIntranetStub(t)
+	s := &Server{
+		Client: scraper.NewClient(scraper.WithBaseURL(upstream.URL)),
+		Sets: sets.StaticStore(&sets.File{Sets: map[string]sets.Set{
+			"mine":   {Groups: []string{"161902", "161903"}, Courses: []string{"Lineare Algebra"}},
+			"broken": {Groups: []string{"999999"}},
+			"no-friday": {Groups: []string{"161902"}, Filter: &filter.Filter{
+				Exclude: []filter.Match{{Weekdays: []string{"fri"}}},
+				Aliases: map[string]string{"Lineare Algebra": "LinA"},
+			}},
+		}}),
+	}
+
+	rec := get(t, s, "/mine.ics", nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	body := rec.Body.String()
+	if strings.Count(body, "BEGIN:VEVENT") != 1 || !strings.Contains(body, "SUMMARY:Lineare Algebra") {
+		t.Errorf("expected the deduplicated, filtered set, got:\n%s", body)
+	}
+
+	body = get(t, s, "/no-friday.ics", nil).Body.String()
+	if strings.Count(body, "BEGIN:VEVENT") != 2 || !strings.Contains(body, "SUMMARY:LinA") {
+		t.Errorf("expected the set filter to drop the Friday lecture and apply the alias, got:\n%s", body)
+	}
+
+	if rec := get(t, s, "/broken.ics", nil); rec.Code != http.StatusInternalServerError {
+		t.Errorf("expected 500 when no group of a set loads, got %d", rec.Code)
+	}
+	if rec := get(t, s, "/health", nil); rec.Code != http.StatusOK {
+		t.Errorf("expected health check to pass, got %d", rec.Code)
+	}
+}
+
+func TestServer_ClientCancel(t *testing.T) {
+	stub, upstream := newIntranetStub(t)
+	stub.delay = 300 * time.Millisecond
+	s := &Server{Client: scraper.NewClient(scraper.WithBaseURL(upstream.URL))}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	req := httptest.NewRequest(http.MethodGet, "/161902.ics", nil).WithContext(ctx)
+	rec := httptest.NewRecorder()
+	start := time.Now()
+	s.ServeHTTP(rec, req)
+	if elapsed := time.Since(start); elapsed > 200*time.Millisecond {
+		t.Errorf("the handler should return once the client is gone, took %v", elapsed)
+	}
+	if rec.Body.Len() != 0 {
+		t.Errorf("expected no body for a cancelled request, got %q", rec.Body.String())
+	}
+
+	// The compilation itself finishes and serves the next subscriber from the cache
+	time.Sleep(500 * time.Millisecond)
+	if rec := get(t, s, "/161902.ics", nil); rec.Code != http.StatusOK {
+		t.Fatalf("expected the calendar, got %d", rec.Code)
+	}
+	if hits := stub.hits.Load(); hits != 1 {
+		t.Errorf("expected the abandoned compilation to be reused, got %d upstream requests", hits)
+	}
+}
+
+func TestServer_OutageServesCacheWithOneRequest(t *testing.T) {
+	stub, upstream := newIntranetStub(t)
+	s := &Server{Client: scraper.NewClient(scraper.WithBaseURL(upstream.URL))}
+	if rec := get(t, s, "/161902.ics", nil); rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+
+	// Age the cache entry past its expiry, then take the intranet down
+	dir, err := scraper.CacheDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "161902.html.json")
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var entry scraper.CacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		t.Fatal(err)
+	}
+	entry.Timestamp = time.Now().Add(-48 * time.Hour)
+	if data, err = json.Marshal(entry); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	stub.down.Store(true)
+	stub.hits.Store(0)
+
+	s.Invalidate("161902")
+	if rec := get(t, s, "/161902.ics", nil); rec.Code != http.StatusOK {
+		t.Fatalf("expected the cached calendar during the outage, got %d", rec.Code)
+	}
+	if hits := stub.hits.Load(); hits != 1 {
+		t.Errorf("a failed revalidation must not be followed by another fetch, got %d upstream requests", hits)
+	}
+}
+
+func TestServer_UpstreamErrors(t *testing.T) {
+	_, upstream := newIntranetStub(t)
+	s := &Server{Client: scraper.NewClient(scraper.WithBaseURL(upstream.URL))}
+
+	if rec := get(t, s, "/999999.ics", nil); rec.Code != http.StatusNotFound {
+		t.Errorf("expected 404 for a group the intranet doesn't know, got %d", rec.Code)
+	}
+
+	upstream.Close()
+	rec := get(t, s, "/161902.ics", nil)
+	if rec.Code != http.StatusServiceUnavailable || rec.Header().Get("Retry-After") == "" {
+		t.Errorf("expected 503 with Retry-After while the intranet is down, got %d %v", rec.Code, rec.Header())
+	}
+}
@@ -0,0 +1,160 @@
+package sets
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"faliactl/pkg/scraper"
+)
+
+// Issue is a problem found by Check
+type Issue struct {
+	Set         string
+	Message     string
+	Suggestions []string // "Did you mean" candidates, best first
+}
+
+func (i Issue) String() string {
+	s := fmt.Sprintf("set %q: %s", i.Set, i.Message)
+	if len(i.Suggestions) > 0 {
+		s += fmt.Sprintf(" (did you mean %s?)", quoteJoin(i.Suggestions))
+	}
+	return s
+}
+
+func quoteJoin(values []string) string {
+	quoted := make([]string, len(values))
+	for i, v := range values {
+		quoted[i] = fmt.Sprintf("%q", v)
+	}
+	return strings.Join(quoted, " or ")
+}
+
+// Check verifies the sets against the intranet: every group must be listed by FetchGroups and
+// every course name must match at least one course scraped from the set's groups.
+// fetchCourses is called once per group and may serve from the cache.
+func Check(f *File, groups []scraper.Group, fetchCourses func(groupPath string) ([]scraper.Course, error)) []Issue {
+	known := make(map[string]scraper.Group, len(groups))
+	var groupIDs []string
+	for _, g := range groups {
+		id := strings.TrimSuffix(g.URL, ".html")
+		known[id] = g
+		groupIDs = append(groupIDs, id)
+	}
+
+	var issues []Issue
+	courseCache := make(map[string][]scraper.Course)
+
+	for _, name := range f.Names() {
+		set := f.Sets[name]
+
+		courseNames := make(map[string]bool)
+		for _, group := range set.Groups {
+			id := strings.TrimSuffix(group, ".html")
+			if _, ok := known[id]; !ok {
+				issues = append(issues, Issue{
+					Set:         name,
+					Message:     fmt.Sprintf("group %q does not exist", group),
+					Suggestions: Suggest(id, groupIDs, 3),
+				})
+				continue
+			}
+
+			path := scraper.GroupPath(id)
+			courses, ok := courseCache[path]
+			if !ok {
+				var err error
+				courses, err = fetchCourses(path)
+				if err != nil {
+					issues = append(issues, Issue{Set: name, Message: fmt.Sprintf("could not fetch group %q: %v", group, err)})
+				}
+				courseCache[path] = courses
+			}
+			for _, c := range courses {
+				courseNames[c.Name] = true
+			}
+		}
+
+		available := make([]string, 0, len(courseNames))
+		for n := range courseNames {
+			available = append(available, n)
+		}
+		sort.Strings(available)
+
+		for _, course := range set.Courses {
+			if courseNames[course] {
+				continue
+			}
+			issues = append(issues, Issue{
+				Set:         name,
+				Message:     fmt.Sprintf("course %q does not occur in any of its groups", course),
+				Suggestions: Suggest(course, available, 3),
+			})
+		}
+	}
+	return issues
+}
+
+// Suggest returns up to limit candidates that look like a typo of value, closest first.
+// Substring matches count as close; otherwise the edit distance must stay within a third
+// of the length.
+func Suggest(value string, candidates []string, limit int) []string {
+	type scored struct {
+		candidate string
+		score     int
+	}
+
+	needle := strings.ToLower(value)
+	var matches []scored
+	for _, c := range candidates {
+		hay := strings.ToLower(c)
+		if hay == needle {
+			matches = append(matches, scored{c, 0})
+			continue
+		}
+		if needle != "" && (strings.Contains(hay, needle) || strings.Contains(needle, hay)) {
+			matches = append(matches, scored{c, 1})
+			continue
+		}
+		d := levenshtein(needle, hay)
+		if d <= max(2, len([]rune(needle))/3) {
+			matches = append(matches, scored{c, d + 1})
+		}
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].score != matches[j].score {
+			return matches[i].score < matches[j].score
+		}
+		return matches[i].candidate < matches[j].candidate
+	})
+
+	var out []string
+	for i := 0; i < len(matches) && i < limit; i++ {
+		out = append(out, matches[i].candidate)
+	}
+	return out
+}
+
+// levenshtein computes the edit distance between a and b on runes
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
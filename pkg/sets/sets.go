@@ -0,0 +1,188 @@
+package sets
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"faliactl/pkg/filter"
+)
+
+// Schema is the JSON Schema describing sets.json. Editors pick it up through the "$schema" key.
+//
+//go:embed sets.schema.json
+var Schema []byte
+
+// SchemaKey is the top-level key editors use to locate the JSON Schema; it is not a set
+const SchemaKey = "$schema"
+
+// Set combines several study groups into one calendar, optionally restricted to some course names
+// and narrowed further by a filter (course type, room, weekday, date window, renames)
+type Set struct {
+	Groups  []string       `json:"groups"`
+	Courses []string       `json:"courses,omitempty"`
+	Filter  *filter.Filter `json:"filter,omitempty"`
+}
+
+// File is the parsed content of sets.json
+type File struct {
+	Schema string // Value of the "$schema" key, preserved when saving
+	Sets   map[string]Set
+}
+
+// Names returns the set names in alphabetical order
+func (f *File) Names() []string {
+	names := make([]string, 0, len(f.Sets))
+	for name := range f.Sets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Lookup returns the named set
+func (f *File) Lookup(name string) (Set, bool) {
+	if f == nil {
+		return Set{}, false
+	}
+	set, ok := f.Sets[name]
+	return set, ok
+}
+
+// UnmarshalJSON parses the top-level map of set names, rejecting unknown fields inside sets
+func (f *File) UnmarshalJSON(data []byte) error {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	f.Sets = make(map[string]Set, len(raw))
+	for name, value := range raw {
+		if name == SchemaKey {
+			if err := json.Unmarshal(value, &f.Schema); err != nil {
+				return fmt.Errorf("%s must be a string", SchemaKey)
+			}
+			continue
+		}
+
+		dec := json.NewDecoder(bytes.NewReader(value))
+		dec.DisallowUnknownFields()
+		var set Set
+		if err := dec.Decode(&set); err != nil {
+			return fmt.Errorf("set %q: %w", name, err)
+		}
+		f.Sets[name] = set
+	}
+	return nil
+}
+
+// MarshalJSON writes "$schema" (if any) followed by the sets
+func (f *File) MarshalJSON() ([]byte, error) {
+	out := make(map[string]any, len(f.Sets)+1)
+	if f.Schema != "" {
+		out[SchemaKey] = f.Schema
+	}
+	for name, set := range f.Sets {
+		if set.Groups == nil {
+			set.Groups = []string{}
+		}
+		out[name] = set
+	}
+	return json.Marshal(out)
+}
+
+// Load reads and validates a sets file. A missing file yields an empty File.
+func Load(path string) (*File, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &File{Sets: make(map[string]Set)}, nil
+		}
+		return nil, err
+	}
+	return Parse(data)
+}
+
+// Parse decodes and validates the content of a sets file
+func Parse(data []byte) (*File, error) {
+	var f File
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("invalid sets file: %w", err)
+	}
+	if problems := f.Validate(); len(problems) > 0 {
+		return nil, fmt.Errorf("invalid sets file: %s", strings.Join(problems, "; "))
+	}
+	return &f, nil
+}
+
+// Save atomically writes the file as indented JSON
+func (f *File) Save(path string) error {
+	data, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".sets-*.json")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(append(data, '\n')); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// setNamePattern matches what the schema allows as a set name; it ends up in the calendar URL
+var setNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// groupPattern matches a group page ID such as "161902" or "161902.html"
+var groupPattern = regexp.MustCompile(`^[A-Za-z0-9_-]+(\.html)?$`)
+
+// Validate performs the structural checks of the JSON Schema and returns one message per problem
+func (f *File) Validate() []string {
+	var problems []string
+	for _, name := range f.Names() {
+		set := f.Sets[name]
+		if !setNamePattern.MatchString(name) {
+			problems = append(problems, fmt.Sprintf("set %q: name may only contain letters, digits, '.', '_' and '-'", name))
+		}
+		if len(set.Groups) == 0 {
+			problems = append(problems, fmt.Sprintf("set %q: needs at least one group", name))
+		}
+		seen := make(map[string]bool)
+		for _, group := range set.Groups {
+			if !groupPattern.MatchString(group) {
+				problems = append(problems, fmt.Sprintf("set %q: %q is not a group ID like 161902 or 161902.html", name, group))
+			}
+			if seen[group] {
+				problems = append(problems, fmt.Sprintf("set %q: group %q is listed twice", name, group))
+			}
+			seen[group] = true
+		}
+		for _, course := range set.Courses {
+			if strings.TrimSpace(course) == "" {
+				problems = append(problems, fmt.Sprintf("set %q: course names must not be empty", name))
+			}
+		}
+		if _, err := set.Filter.Compile(); err != nil {
+			problems = append(problems, fmt.Sprintf("set %q: filter: %v", name, err))
+		}
+	}
+	return problems
+}
@@ -0,0 +1,161 @@
+package sets
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"faliactl/pkg/scraper"
+)
+
+func TestParse_Example(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("..", "..", "sets.json.example"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err := Parse(data)
+	if err != nil {
+		t.Fatalf("example sets file is invalid: %v", err)
+	}
+	if f.Schema == "" {
+		t.Error("expected the $schema key to be preserved")
+	}
+	if !reflect.DeepEqual(f.Names(), []string{"multiple-groups-example", "my-classes"}) {
+		t.Errorf("unexpected sets: %v", f.Names())
+	}
+}
+
+func TestParse_Invalid(t *testing.T) {
+	cases := map[string]string{
+		"unknown field": `{"a": {"groups": ["161902"], "course": ["typo"]}}`,
+		"no groups":     `{"a": {"groups": []}}`,
+		"bad group":     `{"a": {"groups": ["../etc/passwd"]}}`,
+		"bad name":      `{"my set": {"groups": ["161902"]}}`,
+		"not an object": `["161902"]`,
+		"bad filter":    `{"a": {"groups": ["161902"], "filter": {"include": [{"name": "("}]}}}`,
+		"filter typo":   `{"a": {"groups": ["161902"], "filter": {"exclude": [{"day": ["fri"]}]}}}`,
+	}
+	for name, input := range cases {
+		if _, err := Parse([]byte(input)); err == nil {
+			t.Errorf("%s: expected an error for %s", name, input)
+		}
+	}
+}
+
+func TestSchema_IsValidJSON(t *testing.T) {
+	var schema map[string]any
+	if err := json.Unmarshal(Schema, &schema); err != nil {
+		t.Fatalf("embedded schema is not valid JSON: %v", err)
+	}
+}
+
+func TestSave_RoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sets.json")
+	f := &File{Schema: "./schema.json", Sets: map[string]Set{
+		"mine": {Groups: []string{"161902"}, Courses: []string{"Lineare Algebra"}},
+	}}
+	if err := f.Save(path); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(f, loaded) {
+		t.Errorf("round trip mismatch:\n%+v\n%+v", f, loaded)
+	}
+}
+
+func TestSuggest(t *testing.T) {
+	candidates := []string{"Lineare Algebra", "Programmieren 2", "Software Engineering", "Datenbanksysteme"}
+
+	cases := map[string]string{
+		"Lineare Algebr":       "Lineare Algebra",
+		"software engineering": "Software Engineering",
+		"Datenbank":            "Datenbanksysteme",
+		"Programieren 2":       "Programmieren 2",
+	}
+	for input, want := range cases {
+		got := Suggest(input, candidates, 3)
+		if len(got) == 0 || got[0] != want {
+			t.Errorf("Suggest(%q) = %v, want %q first", input, got, want)
+		}
+	}
+
+	if got := Suggest("Quantenphysik", candidates, 3); len(got) != 0 {
+		t.Errorf("expected no suggestions for an unrelated name, got %v", got)
+	}
+}
+
+func TestCheck(t *testing.T) {
+	f := &File{Sets: map[string]Set{
+		"mine": {Groups: []string{"161902.html", "161092"}, Courses: []string{"Lineare Algebra", "Lineare Algebr"}},
+	}}
+	groups := []scraper.Group{{Name: "WI 2. Sem.", URL: "161902.html"}, {Name: "DT 2. Sem.", URL: "161903.html"}}
+	fetch := func(path string) ([]scraper.Course, error) {
+		if path != "161902.html" {
+			return nil, fmt.Errorf("unexpected fetch of %s", path)
+		}
+		return []scraper.Course{{Name: "Lineare Algebra"}, {Name: "Programmieren 2"}}, nil
+	}
+
+	issues := Check(f, groups, fetch)
+	if len(issues) != 2 {
+		t.Fatalf("expected 2 issues, got %v", issues)
+	}
+	if !strings.Contains(issues[0].Message, `group "161092"`) || !reflect.DeepEqual(issues[0].Suggestions, []string{"161902"}) {
+		t.Errorf("unexpected group issue: %+v", issues[0])
+	}
+	if !strings.Contains(issues[1].String(), `did you mean "Lineare Algebra"?`) {
+		t.Errorf("unexpected course issue: %s", issues[1])
+	}
+}
+
+func TestStore_Reload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sets.json")
+	write := func(content string) {
+		t.Helper()
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		// Make sure the modification time moves even on coarse filesystems
+		future := time.Now().Add(time.Duration(len(content)) * time.Second)
+		os.Chtimes(path, future, future)
+	}
+
+	write(`{"a": {"groups": ["161902"]}}`)
+	store, err := NewStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var changed []string
+	store.OnChange = func(names []string) { changed = names }
+
+	if reloaded, err := store.Reload(); reloaded || err != nil {
+		t.Errorf("unchanged file should not reload: %v %v", reloaded, err)
+	}
+
+	write(`{"a": {"groups": ["161902"]}, "b": {"groups": ["161903"]}}`)
+	if reloaded, err := store.Reload(); !reloaded || err != nil {
+		t.Fatalf("expected a reload: %v %v", reloaded, err)
+	}
+	if !reflect.DeepEqual(changed, []string{"b"}) {
+		t.Errorf("expected only set b to have changed, got %v", changed)
+	}
+
+	// A broken edit keeps the last good version
+	write(`{"a": {"groups": ["161902"]}, "b": `)
+	if _, err := store.Reload(); err == nil {
+		t.Fatal("expected an error for invalid JSON")
+	}
+	if _, ok := store.Lookup("b"); !ok {
+		t.Error("the last good sets must stay available after a failed reload")
+	}
+}
@@ -0,0 +1,140 @@
+package sets
+
+import (
+	"context"
+	"os"
+	"reflect"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultReloadInterval is how often a Store checks the sets file for changes
+const DefaultReloadInterval = 2 * time.Second
+
+// Store holds the current sets and reloads them when the file changes. Readers always see
+// a complete, valid File: a reload that fails keeps the last good version.
+type Store struct {
+	path    string
+	current atomic.Pointer[File]
+
+	modTime time.Time
+	size    int64
+
+	// OnChange is called after a successful reload with the names of the added, removed
+	// or modified sets
+	OnChange func(changed []string)
+	// Logf receives reload errors (defaults to discarding them)
+	Logf func(format string, args ...any)
+}
+
+// NewStore loads the sets file once. It fails if the initial file is invalid.
+func NewStore(path string) (*Store, error) {
+	s := &Store{path: path}
+	f, err := Load(path)
+	if err != nil {
+		return nil, err
+	}
+	s.current.Store(f)
+	s.modTime, s.size = stat(path)
+	return s, nil
+}
+
+// StaticStore wraps an already loaded File, e.g. for tests
+func StaticStore(f *File) *Store {
+	s := &Store{}
+	s.current.Store(f)
+	return s
+}
+
+// Current returns the last good File
+func (s *Store) Current() *File {
+	if s == nil {
+		return nil
+	}
+	return s.current.Load()
+}
+
+// Lookup returns the named set from the current File
+func (s *Store) Lookup(name string) (Set, bool) {
+	return s.Current().Lookup(name)
+}
+
+// Reload re-reads the file if its modification time or size changed. It reports whether a
+// new version was swapped in.
+func (s *Store) Reload() (bool, error) {
+	modTime, size := stat(s.path)
+	if modTime.Equal(s.modTime) && size == s.size {
+		return false, nil
+	}
+
+	f, err := Load(s.path)
+	if err != nil {
+		return false, err
+	}
+	s.modTime, s.size = modTime, size
+
+	old := s.current.Swap(f)
+	if changed := diffNames(old, f); len(changed) > 0 && s.OnChange != nil {
+		s.OnChange(changed)
+	}
+	return true, nil
+}
+
+// Watch polls the file every interval until ctx is cancelled
+func (s *Store) Watch(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = DefaultReloadInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	var lastErr string
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		reloaded, err := s.Reload()
+		switch {
+		case err != nil:
+			// Only log each distinct error once instead of every tick
+			if err.Error() != lastErr && s.Logf != nil {
+				s.Logf("Failed to reload %s, keeping the previous sets: %v", s.path, err)
+			}
+			lastErr = err.Error()
+		case reloaded:
+			lastErr = ""
+			if s.Logf != nil {
+				s.Logf("Reloaded %s (%d sets)", s.path, len(s.Current().Sets))
+			}
+		}
+	}
+}
+
+func stat(path string) (time.Time, int64) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return time.Time{}, -1
+	}
+	return info.ModTime(), info.Size()
+}
+
+// diffNames lists the sets that differ between two versions
+func diffNames(old, new *File) []string {
+	var changed []string
+	for name, set := range new.Sets {
+		if prev, ok := old.Lookup(name); !ok || !reflect.DeepEqual(prev, set) {
+			changed = append(changed, name)
+		}
+	}
+	if old != nil {
+		for name := range old.Sets {
+			if _, ok := new.Sets[name]; !ok {
+				changed = append(changed, name)
+			}
+		}
+	}
+	return changed
+}
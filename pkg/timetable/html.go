@@ -0,0 +1,128 @@
+package timetable
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"time"
+)
+
+// WriteHTML writes the grids as one standalone HTML page without external assets. Each week
+// fits a landscape A4 page when printed.
+func WriteHTML(w io.Writer, title string, grids []*Grid) error {
+	type htmlSlot struct {
+		Slot
+		Category string
+		Style    template.CSS
+	}
+	type htmlDay struct {
+		Date  time.Time
+		Slots []htmlSlot
+	}
+	type htmlWeek struct {
+		Monday time.Time
+		Number int
+		Hours  []int
+		Height int // Minutes covered by the grid
+		Days   []htmlDay
+	}
+
+	var weeks []htmlWeek
+	for _, g := range grids {
+		_, number := g.Monday.ISOWeek()
+		hw := htmlWeek{Monday: g.Monday, Number: number, Height: (g.LastHour - g.FirstHour) * 60}
+		for h := g.FirstHour; h < g.LastHour; h++ {
+			hw.Hours = append(hw.Hours, h)
+		}
+		for _, d := range g.Days {
+			hd := htmlDay{Date: d.Date}
+			for _, s := range d.Slots {
+				// Positions use the exact minutes, not the rounded terminal rows
+				top := s.Start.Hour()*60 + s.Start.Minute() - g.FirstHour*60
+				height := int(s.End.Sub(s.Start).Minutes())
+				style := fmt.Sprintf("top:%.3f%%;height:%.3f%%;left:%.3f%%;width:%.3f%%",
+					100*float64(top)/float64(hw.Height), 100*float64(height)/float64(hw.Height),
+					100*float64(s.Lane)/float64(s.Lanes), 100/float64(s.Lanes))
+				hd.Slots = append(hd.Slots, htmlSlot{Slot: s, Category: Category(s.Course.Type), Style: template.CSS(style)})
+			}
+			hw.Days = append(hw.Days, hd)
+		}
+		weeks = append(weeks, hw)
+	}
+
+	return htmlTemplate.Execute(w, struct {
+		Title string
+		Weeks []htmlWeek
+	}{title, weeks})
+}
+
+var htmlTemplate = template.Must(template.New("timetable").Funcs(template.FuncMap{
+	"percent": func(minutes, total int) string { return fmt.Sprintf("%.3f%%", 100*float64(minutes)/float64(total)) },
+	"mul":     func(a, b int) int { return a * b },
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Title}}</title>
+<style>
+@page { size: A4 landscape; margin: 10mm; }
+* { box-sizing: border-box; }
+body { font-family: system-ui, -apple-system, "Segoe UI", sans-serif; margin: 1.5rem; color: #1d1d1f; }
+section { break-after: page; margin-bottom: 2rem; }
+section:last-of-type { break-after: auto; }
+h1 { font-size: 1.3rem; margin: 0 0 .75rem; }
+.grid { display: flex; border: 1px solid #ccc; height: 170mm; }
+.hours { width: 3.5rem; flex: none; border-right: 1px solid #ccc; display: flex; flex-direction: column; }
+.hours span { position: absolute; right: .3rem; font-size: .7rem; color: #666; transform: translateY(-50%); }
+.day { position: relative; flex: 1; border-right: 1px solid #eee; display: flex; flex-direction: column; }
+.day:last-child { border-right: none; }
+.day h2 { font-size: .85rem; text-align: center; margin: 0; padding: .3rem 0; border-bottom: 1px solid #ccc; background: #f5f5f7; }
+.hours .head { height: 1.6rem; border-bottom: 1px solid #ccc; }
+.body { position: relative; flex: 1; }
+.line { position: absolute; left: 0; right: 0; border-top: 1px dashed #eee; }
+.slot { position: absolute; padding: .2rem .3rem; border-radius: 3px; overflow: hidden; font-size: .72rem; line-height: 1.2;
+        border-left: 4px solid; -webkit-print-color-adjust: exact; print-color-adjust: exact; }
+.slot .time { color: #444; }
+.slot .name { font-weight: 600; }
+.lecture  { background: #dcefff; border-color: #0087ff; }
+.exercise { background: #dcf7e3; border-color: #00af5f; }
+.lab      { background: #ffecd1; border-color: #ff8700; }
+.seminar  { background: #ece3ff; border-color: #875fff; }
+.exam     { background: #ffdcdc; border-color: #d70000; }
+.other    { background: #eee;    border-color: #888; }
+</style>
+</head>
+<body>
+{{- range .Weeks}}
+{{- $week := .}}
+<section>
+<h1>{{$.Title}} · Week {{.Number}} ({{.Monday.Format "02.01.2006"}})</h1>
+<div class="grid">
+<div class="hours"><div class="head"></div><div class="body">
+{{- range $i, $h := .Hours}}<span style="top:{{percent (mul $i 60) $week.Height}}">{{printf "%02d:00" $h}}</span>{{end -}}
+</div></div>
+{{- range .Days}}
+<div class="day">
+<h2>{{.Date.Format "Mon 02.01."}}</h2>
+<div class="body">
+{{- range $i, $h := $week.Hours}}<div class="line" style="top:{{percent (mul $i 60) $week.Height}}"></div>{{end}}
+{{- range .Slots}}
+<div class="slot {{.Category}}" style="{{.Style}}">
+<div class="time">{{.Start.Format "15:04"}}–{{.End.Format "15:04"}}</div>
+<div class="name">{{.Course.Name}}</div>
+<div>{{.Course.Room}}</div>
+<div>{{.Course.Type}}</div>
+</div>
+{{- end}}
+</div>
+</div>
+{{- end}}
+</div>
+</section>
+{{- else}}
+<p>No lectures.</p>
+{{- end}}
+</body>
+</html>
+`))
@@ -0,0 +1,148 @@
+package timetable
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// categoryColors are the ANSI 256 colors used for the course categories in the terminal
+var categoryColors = map[string]lipgloss.Color{
+	"lecture":  lipgloss.Color("39"),
+	"exercise": lipgloss.Color("42"),
+	"lab":      lipgloss.Color("214"),
+	"seminar":  lipgloss.Color("141"),
+	"exam":     lipgloss.Color("196"),
+	"other":    lipgloss.Color("250"),
+}
+
+const timeColumnWidth = 6 // "08:00 "
+
+// Render draws the grid for a terminal of the given width. Every slot shows its start time
+// and name, followed by room and type when it spans enough rows.
+func (g *Grid) Render(width int) string {
+	days := len(g.Days)
+	if days == 0 {
+		return ""
+	}
+	dayWidth := (width - timeColumnWidth - days) / days
+	if dayWidth < 12 {
+		dayWidth = 12
+	}
+
+	headerStyle := lipgloss.NewStyle().Bold(true).Width(dayWidth).Align(lipgloss.Center)
+	timeStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("245"))
+	ruleStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("238"))
+	sep := ruleStyle.Render("│")
+
+	var b strings.Builder
+	b.WriteString(strings.Repeat(" ", timeColumnWidth))
+	for _, d := range g.Days {
+		b.WriteString(sep)
+		b.WriteString(headerStyle.Render(d.Date.Format("Mon 02.01.")))
+	}
+	b.WriteString("\n")
+	b.WriteString(ruleStyle.Render(strings.Repeat("─", timeColumnWidth+days*(dayWidth+1))))
+	b.WriteString("\n")
+
+	for row := 0; row < g.Rows(); row++ {
+		label := ""
+		if (row*RowMinutes)%60 == 0 {
+			label = g.RowTime(g.Monday, row).Format("15:04")
+		}
+		b.WriteString(timeStyle.Render(pad(label, timeColumnWidth)))
+		for _, d := range g.Days {
+			b.WriteString(sep)
+			b.WriteString(g.renderCell(d, row, dayWidth))
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// renderCell draws one row of one day column, splitting it into the lanes of any overlap
+func (g *Grid) renderCell(d Day, row, width int) string {
+	var covering []Slot
+	lanes := 1
+	for _, s := range d.Slots {
+		if first, last := g.RowSpan(s); row >= first && row < last {
+			covering = append(covering, s)
+			lanes = s.Lanes
+		}
+	}
+	if len(covering) == 0 {
+		return strings.Repeat(" ", width)
+	}
+
+	laneWidth := width / lanes
+	var b strings.Builder
+	for lane := 0; lane < lanes; lane++ {
+		w := laneWidth
+		if lane == lanes-1 {
+			w = width - laneWidth*(lanes-1) // The last lane takes the remainder
+		}
+
+		var slot *Slot
+		for i := range covering {
+			if covering[i].Lane == lane {
+				slot = &covering[i]
+			}
+		}
+		if slot == nil {
+			b.WriteString(strings.Repeat(" ", w))
+			continue
+		}
+
+		first, _ := g.RowSpan(*slot)
+		style := lipgloss.NewStyle().
+			Background(categoryColors[Category(slot.Course.Type)]).
+			Foreground(lipgloss.Color("16"))
+		line := slotLine(*slot, row-first)
+		if lane < lanes-1 {
+			// Leave a gap between lanes so neighbouring slots stay distinguishable
+			b.WriteString(style.Render(pad(line, w-1)) + " ")
+		} else {
+			b.WriteString(style.Render(pad(line, w)))
+		}
+	}
+	return b.String()
+}
+
+// slotLine is the text of the n-th row of a slot
+func slotLine(s Slot, n int) string {
+	switch n {
+	case 0:
+		return s.Start.Format("15:04") + " " + s.Course.Name
+	case 1:
+		return s.Course.Room
+	case 2:
+		return s.Course.Type
+	default:
+		return ""
+	}
+}
+
+// pad truncates or right-pads s to exactly width terminal cells
+func pad(s string, width int) string {
+	if width <= 0 {
+		return ""
+	}
+	runes := []rune(s)
+	if len(runes) > width {
+		if width == 1 {
+			return "…"
+		}
+		return string(runes[:width-1]) + "…"
+	}
+	return s + strings.Repeat(" ", width-len(runes))
+}
+
+// Legend lists the category colors
+func Legend() string {
+	var parts []string
+	for _, c := range []string{"lecture", "exercise", "lab", "seminar", "exam", "other"} {
+		swatch := lipgloss.NewStyle().Background(categoryColors[c]).Render("  ")
+		parts = append(parts, swatch+" "+c)
+	}
+	return strings.Join(parts, "  ")
+}
@@ -0,0 +1,233 @@
+// Package timetable lays out courses of one week as a Mon–Fri time grid
+package timetable
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"faliactl/pkg/scraper"
+)
+
+// RowMinutes is the height of one grid row. Slots are widened to whole rows when laid out.
+const RowMinutes = 30
+
+// Default visible hours when the week is empty or only has lectures inside them
+const (
+	DefaultFirstHour = 8
+	DefaultLastHour  = 18
+)
+
+// Grid is one week of courses, laid out in rows of RowMinutes
+type Grid struct {
+	Monday    time.Time // Midnight in Europe/Berlin
+	FirstHour int       // First visible hour
+	LastHour  int       // Hour at which the last row ends
+	Days      []Day     // Monday to Friday, plus Saturday/Sunday when something happens on them
+}
+
+// Day is one column of the grid
+type Day struct {
+	Date  time.Time
+	Slots []Slot
+}
+
+// Slot is one course placed in the grid. Overlapping courses share the day column:
+// Lanes is the number of side-by-side lanes of the overlap cluster and Lane the position.
+type Slot struct {
+	Course     scraper.Course
+	Start, End time.Time
+	Lane       int
+	Lanes      int
+}
+
+// Rows returns the number of rows between FirstHour and LastHour
+func (g *Grid) Rows() int {
+	return (g.LastHour - g.FirstHour) * 60 / RowMinutes
+}
+
+// RowTime returns the time at which the row starts on the given day
+func (g *Grid) RowTime(day time.Time, row int) time.Time {
+	return time.Date(day.Year(), day.Month(), day.Day(), g.FirstHour, row*RowMinutes, 0, 0, scraper.Berlin)
+}
+
+// RowSpan returns the first row of the slot and the row after its last one
+func (g *Grid) RowSpan(s Slot) (int, int) {
+	startMin := s.Start.Hour()*60 + s.Start.Minute() - g.FirstHour*60
+	endMin := s.End.Hour()*60 + s.End.Minute() - g.FirstHour*60
+	if s.End.Day() != s.Start.Day() {
+		endMin = (g.LastHour - g.FirstHour) * 60
+	}
+	first := startMin / RowMinutes
+	last := (endMin + RowMinutes - 1) / RowMinutes
+	if last <= first {
+		last = first + 1
+	}
+	return first, last
+}
+
+// Build lays out the courses of the week starting at monday. Courses outside that week are ignored.
+func Build(courses []scraper.Course, monday time.Time) *Grid {
+	monday = time.Date(monday.Year(), monday.Month(), monday.Day(), 0, 0, 0, 0, scraper.Berlin)
+	g := &Grid{Monday: monday, FirstHour: DefaultFirstHour, LastHour: DefaultLastHour}
+
+	var days [7][]Slot
+	for _, c := range courses {
+		start, end, err := c.Times()
+		if err != nil {
+			continue
+		}
+		offset := daysBetween(monday, start)
+		if offset < 0 || offset > 6 {
+			continue
+		}
+		days[offset] = append(days[offset], Slot{Course: c, Start: start, End: end})
+
+		if start.Hour() < g.FirstHour {
+			g.FirstHour = start.Hour()
+		}
+		endHour := end.Hour()
+		if end.Minute() > 0 {
+			endHour++
+		}
+		if end.Day() != start.Day() {
+			endHour = 24
+		}
+		if endHour > g.LastHour {
+			g.LastHour = endHour
+		}
+	}
+
+	for i, slots := range days {
+		if i >= 5 && len(slots) == 0 {
+			continue
+		}
+		g.Days = append(g.Days, Day{Date: monday.AddDate(0, 0, i), Slots: g.assignLanes(slots)})
+	}
+	return g
+}
+
+// assignLanes sorts the slots of a day and places overlapping ones side by side. Overlaps are
+// computed on whole rows so that the terminal rendering never draws two slots on top of each other.
+func (g *Grid) assignLanes(slots []Slot) []Slot {
+	sort.SliceStable(slots, func(i, j int) bool {
+		if !slots[i].Start.Equal(slots[j].Start) {
+			return slots[i].Start.Before(slots[j].Start)
+		}
+		return slots[i].End.After(slots[j].End)
+	})
+
+	clusterStart, clusterEnd := 0, -1
+	var laneEnds []int // Row after the last slot in each lane of the current cluster
+	closeCluster := func(upTo int) {
+		for k := clusterStart; k < upTo; k++ {
+			slots[k].Lanes = len(laneEnds)
+		}
+	}
+
+	for i := range slots {
+		first, last := g.RowSpan(slots[i])
+		if first >= clusterEnd {
+			closeCluster(i)
+			clusterStart, laneEnds = i, nil
+		}
+
+		lane := -1
+		for l, end := range laneEnds {
+			if end <= first {
+				lane = l
+				break
+			}
+		}
+		if lane < 0 {
+			lane = len(laneEnds)
+			laneEnds = append(laneEnds, 0)
+		}
+		laneEnds[lane] = last
+		slots[i].Lane = lane
+		if last > clusterEnd {
+			clusterEnd = last
+		}
+	}
+	closeCluster(len(slots))
+	return slots
+}
+
+// daysBetween counts calendar days, unaffected by daylight saving time changes in between
+func daysBetween(from, to time.Time) int {
+	to = to.In(scraper.Berlin)
+	a := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, time.UTC)
+	b := time.Date(to.Year(), to.Month(), to.Day(), 0, 0, 0, 0, time.UTC)
+	return int(b.Sub(a).Hours() / 24)
+}
+
+// Courses returns the number of courses placed in the grid
+func (g *Grid) Courses() int {
+	n := 0
+	for _, d := range g.Days {
+		n += len(d.Slots)
+	}
+	return n
+}
+
+// Category groups the free-text course types of the intranet for coloring
+func Category(courseType string) string {
+	t := strings.ToLower(courseType)
+	switch {
+	case strings.Contains(t, "prüfung") || strings.Contains(t, "klausur") || strings.Contains(t, "exam"):
+		return "exam"
+	case strings.Contains(t, "labor") || strings.Contains(t, "praktikum"):
+		return "lab"
+	case strings.Contains(t, "übung") || strings.Contains(t, "uebung") || strings.Contains(t, "tutorium"):
+		return "exercise"
+	case strings.Contains(t, "seminar") || strings.Contains(t, "projekt"):
+		return "seminar"
+	case strings.Contains(t, "vorlesung"):
+		return "lecture"
+	default:
+		return "other"
+	}
+}
+
+// WeekStart returns Monday 00:00 of the week containing t, in Europe/Berlin
+func WeekStart(t time.Time) time.Time {
+	t = t.In(scraper.Berlin)
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, scraper.Berlin)
+	return day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+}
+
+// ParseWeek resolves a week specification to its Monday. It accepts an ISO week ("2026-W42"),
+// any date inside the week ("2026-10-16"), "next", "last", or "" for the current week.
+func ParseWeek(spec string, now time.Time) (time.Time, error) {
+	spec = strings.TrimSpace(strings.ToLower(spec))
+	switch spec {
+	case "", "this", "current":
+		return WeekStart(now), nil
+	case "next":
+		return WeekStart(now).AddDate(0, 0, 7), nil
+	case "last", "previous":
+		return WeekStart(now).AddDate(0, 0, -7), nil
+	}
+
+	if year, week, ok := strings.Cut(spec, "-w"); ok {
+		y, errY := strconv.Atoi(year)
+		w, errW := strconv.Atoi(week)
+		if errY != nil || errW != nil || w < 1 || w > 53 {
+			return time.Time{}, fmt.Errorf("invalid ISO week %q, expected e.g. 2026-W42", spec)
+		}
+		// January 4th is always in ISO week 1
+		monday := WeekStart(time.Date(y, time.January, 4, 12, 0, 0, 0, scraper.Berlin)).AddDate(0, 0, 7*(w-1))
+		if _, got := monday.ISOWeek(); got != w {
+			return time.Time{}, fmt.Errorf("%d has no ISO week %d", y, w)
+		}
+		return monday, nil
+	}
+
+	day, err := time.ParseInLocation("2006-01-02", spec, scraper.Berlin)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid week %q, expected e.g. 2026-W42, 2026-10-16 or next", spec)
+	}
+	return WeekStart(day), nil
+}
@@ -0,0 +1,128 @@
+package timetable
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"faliactl/pkg/scraper"
+)
+
+func slot(name, typ string, day, startH, startM, endH, endM int) scraper.Course {
+	start := time.Date(2026, 10, day, startH, startM, 0, 0, scraper.Berlin)
+	end := time.Date(2026, 10, day, endH, endM, 0, 0, scraper.Berlin)
+	return scraper.Course{Name: name, Type: typ, Room: "WF-EX-2/127", Start: start, End: end,
+		DateStr: start.Format("02.01.2006"), StartTime: start.Format("15:04"), EndTime: end.Format("15:04")}
+}
+
+// The week of 2026-10-12 (Monday) is ISO week 42
+var monday = time.Date(2026, 10, 12, 0, 0, 0, 0, scraper.Berlin)
+
+func TestParseWeek(t *testing.T) {
+	now := time.Date(2026, 10, 16, 9, 0, 0, 0, scraper.Berlin)
+	cases := map[string]time.Time{
+		"":           monday,
+		"2026-W42":   monday,
+		"2026-w42":   monday,
+		"2026-10-18": monday,
+		"next":       monday.AddDate(0, 0, 7),
+		"2026-W01":   time.Date(2025, 12, 29, 0, 0, 0, 0, scraper.Berlin),
+	}
+	for spec, want := range cases {
+		got, err := ParseWeek(spec, now)
+		if err != nil || !got.Equal(want) {
+			t.Errorf("ParseWeek(%q) = %v, %v; want %v", spec, got, err, want)
+		}
+	}
+	for _, bad := range []string{"2026-W54", "2026-W00", "KW42", "2025-W53"} {
+		if _, err := ParseWeek(bad, now); err == nil {
+			t.Errorf("ParseWeek(%q) should fail", bad)
+		}
+	}
+}
+
+func TestBuild_Overlaps(t *testing.T) {
+	g := Build([]scraper.Course{
+		slot("Mathe", "Vorlesung", 12, 8, 15, 9, 45),
+		slot("Physik", "Labor", 12, 9, 0, 10, 30),
+		slot("Chemie", "Übung", 12, 10, 30, 12, 0),
+		slot("Spät", "Vorlesung", 14, 18, 0, 19, 30),
+		slot("Next week", "Vorlesung", 19, 8, 15, 9, 45),
+	}, monday)
+
+	if len(g.Days) != 5 {
+		t.Fatalf("expected Mon–Fri, got %d days", len(g.Days))
+	}
+	if g.Courses() != 4 {
+		t.Errorf("expected 4 courses in the week, got %d", g.Courses())
+	}
+	if g.FirstHour != 8 || g.LastHour != 20 {
+		t.Errorf("expected the grid to stretch to 08–20, got %d–%d", g.FirstHour, g.LastHour)
+	}
+
+	mon := g.Days[0].Slots
+	if mon[0].Lanes != 2 || mon[1].Lanes != 2 || mon[0].Lane == mon[1].Lane {
+		t.Errorf("overlapping slots must share the column: %+v", mon[:2])
+	}
+	// Chemie starts in the row Physik ends in (10:30), so it is part of the same cluster,
+	// but it can reuse the lane Mathe freed
+	if mon[2].Lane != 0 {
+		t.Errorf("expected Chemie in lane 0, got %d", mon[2].Lane)
+	}
+	if g.Days[2].Slots[0].Lanes != 1 {
+		t.Errorf("a lone slot should take the full column")
+	}
+}
+
+func TestBuild_Weekend(t *testing.T) {
+	g := Build([]scraper.Course{slot("Blockseminar", "Seminar", 17, 9, 0, 17, 0)}, monday)
+	if len(g.Days) != 6 || g.Days[5].Date.Weekday() != time.Saturday {
+		t.Errorf("expected a Saturday column when something happens on it, got %d days", len(g.Days))
+	}
+}
+
+func TestRender(t *testing.T) {
+	g := Build([]scraper.Course{
+		slot("Mathe", "Vorlesung", 12, 8, 15, 9, 45),
+		slot("Physik", "Labor", 12, 9, 0, 10, 30),
+	}, monday)
+	out := g.Render(200)
+	for _, want := range []string{"Mon 12.10.", "Fri 16.10.", "08:15 Mathe", "09:00 Physik", "WF-EX-2/127"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in:\n%s", want, out)
+		}
+	}
+	if lines := strings.Count(out, "\n"); lines != 2+g.Rows() {
+		t.Errorf("expected %d lines, got %d", 2+g.Rows(), lines)
+	}
+}
+
+func TestWriteHTML(t *testing.T) {
+	g := Build([]scraper.Course{slot("Mathe & Logik", "Vorlesung", 12, 8, 15, 9, 45)}, monday)
+	var buf bytes.Buffer
+	if err := WriteHTML(&buf, "My week", []*Grid{g}); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, want := range []string{"@page", "Week 42", `class="slot lecture"`, "Mathe &amp; Logik", "top:2.500%"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in:\n%s", want, out)
+		}
+	}
+}
+
+func TestCategory(t *testing.T) {
+	cases := map[string]string{
+		"Vorlesung":      "lecture",
+		"Übung Gruppe B": "exercise",
+		"Labor Gruppe A": "lab",
+		"Klausur":        "exam",
+		"DT+WI S1":       "other",
+	}
+	for typ, want := range cases {
+		if got := Category(typ); got != want {
+			t.Errorf("Category(%q) = %q, want %q", typ, got, want)
+		}
+	}
+}
@@ -1,85 +1,164 @@
 package transit
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
-	"os"
+	"strings"
 	"time"
 )
 
-var baseURL = "https://v6.db.transport.rest"
+const defaultBaseURL = "https://v6.db.transport.rest"
+
+// Public APIs often block default Go user agents
+const defaultUserAgent = "faliactl-student-project/1.0 (https://github.com/jb381/faliactl)"
+
+// maxAttempts is how often a request is sent before a transient failure is returned
+const maxAttempts = 3
 
 // Client interacts with the HAFAS DB API
 type Client struct {
 	httpClient *http.Client
+	baseURL    string
+	userAgent  string
+	timeout    time.Duration
+	onRetry    func(attempt int, err error)
+}
+
+// Option customizes a Client created by NewClient
+type Option func(*Client)
+
+// WithBaseURL points the client at another API root, e.g. a local mirror or a test server
+func WithBaseURL(baseURL string) Option {
+	return func(c *Client) {
+		c.baseURL = strings.TrimRight(baseURL, "/")
+	}
+}
+
+// WithHTTPClient replaces the underlying http.Client, e.g. to install a custom transport
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
 }
 
-func NewClient() *Client {
-	return &Client{
+// WithUserAgent overrides the User-Agent header sent with every request
+func WithUserAgent(userAgent string) Option {
+	return func(c *Client) {
+		c.userAgent = userAgent
+	}
+}
+
+// WithTimeout sets the per-request timeout. It applies on top of WithHTTPClient
+// without modifying the http.Client that was passed in.
+func WithTimeout(timeout time.Duration) Option {
+	return func(c *Client) {
+		c.timeout = timeout
+	}
+}
+
+// WithRetryHook is called before every retry of a transient failure with the number of the
+// failed attempt (starting at 1) and its error, e.g. to log it
+func WithRetryHook(hook func(attempt int, err error)) Option {
+	return func(c *Client) {
+		c.onRetry = hook
+	}
+}
+
+// NewClient creates a new HAFAS client
+func NewClient(opts ...Option) *Client {
+	c := &Client{
 		httpClient: &http.Client{Timeout: 30 * time.Second},
+		baseURL:    defaultBaseURL,
+		userAgent:  defaultUserAgent,
+	}
+	for _, opt := range opts {
+		opt(c)
 	}
+	if c.timeout > 0 {
+		httpClient := *c.httpClient
+		httpClient.Timeout = c.timeout
+		c.httpClient = &httpClient
+	}
+	return c
 }
 
-// getWithRetries attempts an HTTP GET request up to 3 times for transient failures.
-func (c *Client) getWithRetries(reqURL string) (*http.Response, error) {
+// getWithRetries attempts an HTTP GET request up to maxAttempts times for transient failures,
+// waiting a little longer after every attempt. The wait ends early when ctx is done.
+func (c *Client) getWithRetries(ctx context.Context, reqURL string) (*http.Response, error) {
 	var lastErr error
-	var resp *http.Response
 
-	for attempt := 0; attempt < 3; attempt++ {
-		req, err := http.NewRequest("GET", reqURL, nil)
+	for attempt := 1; ; attempt++ {
+		req, err := http.NewRequestWithContext(ctx, "GET", reqURL, nil)
 		if err != nil {
 			return nil, err
 		}
-		// Public APIs often block default Go user agents
-		req.Header.Set("User-Agent", "faliactl-student-project/1.0 (https://github.com/jb381/faliactl)")
-
-		resp, lastErr = c.httpClient.Do(req)
+		req.Header.Set("User-Agent", c.userAgent)
 
+		resp, err := c.httpClient.Do(req)
+		switch {
+		case err != nil:
+			lastErr = &RequestError{URL: reqURL, Err: err}
 		// If request succeeded but gave a transient error code, also retry.
-		if lastErr == nil && (resp.StatusCode == 500 || resp.StatusCode == 502 || resp.StatusCode == 503 || resp.StatusCode == 504) {
+		case resp.StatusCode == 500 || resp.StatusCode == 502 || resp.StatusCode == 503 || resp.StatusCode == 504:
 			resp.Body.Close()
-			lastErr = fmt.Errorf("transient status code: %d", resp.StatusCode)
-		} else if lastErr == nil {
+			lastErr = &HTTPStatusError{Code: resp.StatusCode, URL: reqURL}
+		default:
 			return resp, nil
 		}
 
-		if attempt < 2 {
-			fmt.Fprintf(os.Stderr, "[Transit API] retrying after transient failure (attempt %d/3)\n", attempt+1)
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		if attempt == maxAttempts {
+			return nil, fmt.Errorf("failed after %d attempts: %w", maxAttempts, lastErr)
+		}
+		if c.onRetry != nil {
+			c.onRetry(attempt, lastErr)
 		}
 
-		time.Sleep(time.Duration(attempt+1) * time.Second)
+		timer := time.NewTimer(time.Duration(attempt) * time.Second)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		}
 	}
-
-	return nil, fmt.Errorf("failed after 3 attempts: %v", lastErr)
 }
 
 // FetchLocations searches for transit stops matching a text query
 func (c *Client) FetchLocations(query string) ([]Location, error) {
+	return c.FetchLocationsContext(context.Background(), query)
+}
+
+// FetchLocationsContext is FetchLocations with a context that cancels the request and its retries
+func (c *Client) FetchLocationsContext(ctx context.Context, query string) ([]Location, error) {
 	// Query parameters
 	encodedQuery := url.QueryEscape(query)
-	reqURL := fmt.Sprintf("%s/locations?query=%s&results=5", baseURL, encodedQuery)
+	reqURL := fmt.Sprintf("%s/locations?query=%s&results=5", c.baseURL, encodedQuery)
 
-	resp, err := c.getWithRetries(reqURL)
+	resp, err := c.getWithRetries(ctx, reqURL)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch locations: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+		return nil, &HTTPStatusError{Code: resp.StatusCode, URL: reqURL}
 	}
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read location response body: %w", err)
+		return nil, &RequestError{URL: reqURL, Err: err}
 	}
 
 	var locations []Location
 	if err := json.Unmarshal(body, &locations); err != nil {
-		return nil, fmt.Errorf("failed to decode locations JSON: %w", err)
+		return nil, &ParseError{URL: reqURL, Err: err}
 	}
 
 	// Filter down to just actual stations/stops
@@ -95,26 +174,31 @@ func (c *Client) FetchLocations(query string) ([]Location, error) {
 
 // FetchDepartures gets the next departures for a specific station ID
 func (c *Client) FetchDepartures(stationID string, durationMinutes int) ([]Departure, error) {
-	reqURL := fmt.Sprintf("%s/stops/%s/departures?duration=%d&results=15", baseURL, stationID, durationMinutes)
+	return c.FetchDeparturesContext(context.Background(), stationID, durationMinutes)
+}
+
+// FetchDeparturesContext is FetchDepartures with a context that cancels the request and its retries
+func (c *Client) FetchDeparturesContext(ctx context.Context, stationID string, durationMinutes int) ([]Departure, error) {
+	reqURL := fmt.Sprintf("%s/stops/%s/departures?duration=%d&results=15", c.baseURL, stationID, durationMinutes)
 
-	resp, err := c.getWithRetries(reqURL)
+	resp, err := c.getWithRetries(ctx, reqURL)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch departures: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+		return nil, &HTTPStatusError{Code: resp.StatusCode, URL: reqURL}
 	}
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read departure response body: %w", err)
+		return nil, &RequestError{URL: reqURL, Err: err}
 	}
 
 	var depResp DepartureResponse
 	if err := json.Unmarshal(body, &depResp); err != nil {
-		return nil, fmt.Errorf("failed to decode departures JSON: %w", err)
+		return nil, &ParseError{URL: reqURL, Err: err}
 	}
 
 	return depResp.Departures, nil
@@ -122,27 +206,31 @@ func (c *Client) FetchDepartures(stationID string, durationMinutes int) ([]Depar
 
 // FetchJourneys plans a trip from a starting station/address ID to a destination ID
 func (c *Client) FetchJourneys(fromID string, toID string) ([]Journey, error) {
-	reqURL := fmt.Sprintf("%s/journeys?from=%s&to=%s&results=3", baseURL, fromID, toID)
+	return c.FetchJourneysContext(context.Background(), fromID, toID)
+}
+
+// FetchJourneysContext is FetchJourneys with a context that cancels the request and its retries
+func (c *Client) FetchJourneysContext(ctx context.Context, fromID string, toID string) ([]Journey, error) {
+	reqURL := fmt.Sprintf("%s/journeys?from=%s&to=%s&results=3", c.baseURL, fromID, toID)
 
-	resp, err := c.getWithRetries(reqURL)
+	resp, err := c.getWithRetries(ctx, reqURL)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch journeys: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+		return nil, &HTTPStatusError{Code: resp.StatusCode, URL: reqURL}
 	}
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read journey response body: %w", err)
+		return nil, &RequestError{URL: reqURL, Err: err}
 	}
 
 	var journeyResp JourneyResponse
 	if err := json.Unmarshal(body, &journeyResp); err != nil {
-		// Log the body if it fails to unmarshal so we can debug the exact JSON shape of the legs
-		return nil, fmt.Errorf("failed to decode journey JSON: %w", err)
+		return nil, &ParseError{URL: reqURL, Err: err}
 	}
 
 	return journeyResp.Journeys, nil
@@ -150,27 +238,32 @@ func (c *Client) FetchJourneys(fromID string, toID string) ([]Journey, error) {
 
 // FetchJourneysByArrival plans a trip from a starting station ID to a destination ID, arriving before a specific time
 func (c *Client) FetchJourneysByArrival(fromID string, toID string, arrival time.Time) ([]Journey, error) {
+	return c.FetchJourneysByArrivalContext(context.Background(), fromID, toID, arrival)
+}
+
+// FetchJourneysByArrivalContext is FetchJourneysByArrival with a context that cancels the request and its retries
+func (c *Client) FetchJourneysByArrivalContext(ctx context.Context, fromID string, toID string, arrival time.Time) ([]Journey, error) {
 	encodedArrival := url.QueryEscape(arrival.Format(time.RFC3339))
-	reqURL := fmt.Sprintf("%s/journeys?from=%s&to=%s&arrival=%s&results=3", baseURL, fromID, toID, encodedArrival)
+	reqURL := fmt.Sprintf("%s/journeys?from=%s&to=%s&arrival=%s&results=3", c.baseURL, fromID, toID, encodedArrival)
 
-	resp, err := c.getWithRetries(reqURL)
+	resp, err := c.getWithRetries(ctx, reqURL)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch journeys by arrival: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+		return nil, &HTTPStatusError{Code: resp.StatusCode, URL: reqURL}
 	}
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read journey response body: %w", err)
+		return nil, &RequestError{URL: reqURL, Err: err}
 	}
 
 	var journeyResp JourneyResponse
 	if err := json.Unmarshal(body, &journeyResp); err != nil {
-		return nil, fmt.Errorf("failed to decode journey JSON: %w", err)
+		return nil, &ParseError{URL: reqURL, Err: err}
 	}
 
 	return journeyResp.Journeys, nil
@@ -1,6 +1,8 @@
 package transit
 
 import (
+	"context"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -50,17 +52,8 @@ func TestClient_FetchJourneysByArrival(t *testing.T) {
 	}))
 	defer server.Close()
 
-	// Initialize the custom client with the mock backend URL
-	// We have to temporarily override the global baseURL or inject it.
-	// Since baseURL is hardcoded to "https://v6.db.transport.rest", we will inject a custom HTTP Client transport
-	// or modify the baseURL string. Let's create an overlay in the struct if possible, or just modify the global var for testing.
-
-	// Temporarily override the unexported global baseURL string
-	originalBaseURL := baseURL
-	baseURL = server.URL
-	defer func() { baseURL = originalBaseURL }()
-
-	client := NewClient()
+	// Point the client at the mock backend instead of the live HAFAS API
+	client := NewClient(WithBaseURL(server.URL))
 
 	loc, _ := time.LoadLocation("Europe/Berlin")
 	arrivalTime := time.Date(2026, 2, 25, 9, 0, 0, 0, loc)
@@ -101,7 +94,7 @@ func TestClient_GetWithRetries_Success(t *testing.T) {
 	client := NewClient()
 
 	// getWithRetries is unexported
-	resp, err := client.getWithRetries(server.URL)
+	resp, err := client.getWithRetries(context.Background(), server.URL)
 	if err != nil {
 		t.Fatalf("expected robust retry to succeed on 3rd attempt, got error: %v", err)
 	}
@@ -124,8 +117,60 @@ func TestClient_GetWithRetries_Fail(t *testing.T) {
 
 	client := NewClient()
 
-	_, err := client.getWithRetries(server.URL)
+	_, err := client.getWithRetries(context.Background(), server.URL)
 	if err == nil {
 		t.Fatalf("expected robust retry to completely fail after 3 attempts, but got nil error")
 	}
+	var statusErr *HTTPStatusError
+	if !errors.Is(err, ErrUpstreamUnavailable) || !errors.As(err, &statusErr) || statusErr.Code != http.StatusBadGateway {
+		t.Errorf("expected the last 502 to be reported as unavailable, got %v", err)
+	}
+}
+
+func TestClient_GetWithRetries_HookAndCancel(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusGatewayTimeout)
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var retries []int
+	client := NewClient(WithRetryHook(func(attempt int, err error) {
+		retries = append(retries, attempt)
+		cancel() // Cancelling must end the backoff sleep right away
+	}))
+
+	start := time.Now()
+	_, err := client.getWithRetries(ctx, server.URL)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("the retry sleep was not cancelled, took %v", elapsed)
+	}
+	if len(retries) != 1 || retries[0] != 1 {
+		t.Errorf("expected the hook to see attempt 1 only, got %v", retries)
+	}
+}
+
+func TestNewClient_Options(t *testing.T) {
+	var gotAgent string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAgent = r.Header.Get("User-Agent")
+		w.Write([]byte(`{"departures": []}`))
+	}))
+	defer server.Close()
+
+	shared := &http.Client{}
+	client := NewClient(WithHTTPClient(shared), WithBaseURL(server.URL), WithUserAgent("faliactl-test"), WithTimeout(2*time.Second))
+
+	if _, err := client.FetchDepartures("891097", 60); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if gotAgent != "faliactl-test" {
+		t.Errorf("expected custom user agent, got %q", gotAgent)
+	}
+	if client.httpClient.Timeout != 2*time.Second || shared.Timeout != 0 {
+		t.Errorf("expected a copied client with a 2s timeout, got %v (shared %v)", client.httpClient.Timeout, shared.Timeout)
+	}
 }
@@ -0,0 +1,15 @@
+package transit
+
+import "faliactl/pkg/upstream"
+
+// The errors shared by every upstream client, so callers only need to import this package
+var (
+	ErrNotFound            = upstream.ErrNotFound
+	ErrUpstreamUnavailable = upstream.ErrUpstreamUnavailable
+)
+
+type (
+	HTTPStatusError = upstream.HTTPStatusError
+	RequestError    = upstream.RequestError
+	ParseError      = upstream.ParseError
+)
@@ -1,8 +1,11 @@
 package transit
 
 import (
-	"strings"
+	"errors"
+	"path/filepath"
 	"testing"
+
+	"faliactl/pkg/fixture/fixturetest"
 )
 
 func skipOnUpstreamFailure(t *testing.T, err error) {
@@ -10,9 +13,8 @@ func skipOnUpstreamFailure(t *testing.T, err error) {
 		return
 	}
 
-	msg := err.Error()
-	if strings.Contains(msg, "unexpected status code: 5") || strings.Contains(msg, "transient status code: 5") {
-		t.Skipf("Skipping live transit test due to upstream failure: %v", err)
+	if errors.Is(err, ErrUpstreamUnavailable) {
+		t.Skipf("Skipping transit test due to upstream failure: %v", err)
 	}
 }
 
@@ -21,7 +23,7 @@ func TestTransitIntegration_FetchLocations(t *testing.T) {
 		t.Skip("Skipping integration test in short mode")
 	}
 
-	client := NewClient()
+	client := newIntegrationClient(t)
 
 	locations, err := client.FetchLocations("Wolfenbüttel Fachhochschule")
 	if err != nil {
@@ -54,7 +56,7 @@ func TestTransitIntegration_FetchDepartures(t *testing.T) {
 		t.Skip("Skipping integration test in short mode")
 	}
 
-	client := NewClient()
+	client := newIntegrationClient(t)
 
 	// 991604089 is Salzgitter Ostfalia Campus (usually has buses all day)
 	deps, err := client.FetchDepartures("991604089", 120) // 120 mins
@@ -85,7 +87,7 @@ func TestTransitIntegration_FetchJourneys(t *testing.T) {
 		t.Skip("Skipping integration test in short mode")
 	}
 
-	client := NewClient()
+	client := newIntegrationClient(t)
 
 	// Wolfenbüttel (8000255) to Braunschweig Hbf (8000049)
 	journeys, err := client.FetchJourneys("8000255", "8000049")
@@ -112,3 +114,8 @@ func TestTransitIntegration_FetchJourneys(t *testing.T) {
 		}
 	}
 }
+
+// newIntegrationClient replays testdata/fixtures unless FALIACTL_LIVE is set
+func newIntegrationClient(t *testing.T) *Client {
+	return NewClient(WithHTTPClient(fixturetest.Client(t, filepath.Join("testdata", "fixtures"))))
+}
@@ -41,12 +41,25 @@ type Journey struct {
 	Legs []Leg `json:"legs"`
 }
 
-// Leg is a single continuous part of a journey (e.g., walking, or one bus ride)
+// Leg is a single continuous part of a journey (e.g., walking, or one bus ride).
+// Departure and Arrival already include realtime delays when HAFAS knows them.
 type Leg struct {
-	Origin      Location  `json:"origin"`
-	Destination Location  `json:"destination"`
-	Departure   time.Time `json:"departure"`
-	Arrival     time.Time `json:"arrival"`
-	Line        *Line     `json:"line,omitempty"`
-	Walking     bool      `json:"walking,omitempty"`
+	Origin           Location  `json:"origin"`
+	Destination      Location  `json:"destination"`
+	Departure        time.Time `json:"departure"`
+	PlannedDeparture time.Time `json:"plannedDeparture"`
+	DepartureDelay   *int      `json:"departureDelay"` // Seconds, nil without realtime data
+	Arrival          time.Time `json:"arrival"`
+	PlannedArrival   time.Time `json:"plannedArrival"`
+	ArrivalDelay     *int      `json:"arrivalDelay"` // Seconds, nil without realtime data
+	Line             *Line     `json:"line,omitempty"`
+	Walking          bool      `json:"walking,omitempty"`
+}
+
+// Delay returns the realtime departure delay of the leg (zero when unknown)
+func (l Leg) Delay() time.Duration {
+	if l.DepartureDelay == nil {
+		return 0
+	}
+	return time.Duration(*l.DepartureDelay) * time.Second
 }
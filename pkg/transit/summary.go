@@ -6,9 +6,9 @@ import (
 
 // SummarizedRoute holds the next few departures for a unique line and destination.
 type SummarizedRoute struct {
-	LineName   string
-	Direction  string
-	Departures []Departure
+	LineName   string      `json:"line"`
+	Direction  string      `json:"direction"`
+	Departures []Departure `json:"departures"`
 }
 
 // SummarizeDepartures sorts departures by actual time and groups them by Line and Direction,
@@ -1,6 +1,7 @@
 package tui
 
 import (
+	"context"
 	"faliactl/pkg/config"
 
 	"github.com/charmbracelet/huh"
@@ -11,6 +12,7 @@ var (
 	// These act as fallbacks initially, but should ideally be dynamically instantiated by GetTheme()
 	accentStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("99"))
 	errorStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("196")).Bold(true)
+	warnStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("214"))
 )
 
 // GetTheme securely loads the user's saved Accent Color and constructs the UI theme.
@@ -59,7 +61,7 @@ func GetCustomTheme(baseColor string) *huh.Theme {
 }
 
 // RunTUI launches the main menu interactive form experience
-func RunTUI() error {
+func RunTUI(ctx context.Context) error {
 	var action string
 
 	initialForm := huh.NewForm(
@@ -83,16 +85,16 @@ func RunTUI() error {
 	}
 
 	if action == "mensa" {
-		return RunMensaTUI()
+		return RunMensaTUI(ctx)
 	} else if action == "transit" {
-		return RunTransitTUI()
+		return RunTransitTUI(ctx)
 	} else if action == "commute" {
-		return RunCourseCommuteTUI()
+		return RunCourseCommuteTUI(ctx)
 	} else if action == "weekly" {
-		return RunWeeklyCommuteTUI()
+		return RunWeeklyCommuteTUI(ctx)
 	} else if action == "config" {
-		return RunConfigTUI()
+		return RunConfigTUI(ctx)
 	}
 
-	return RunScheduleTUI()
+	return RunScheduleTUI(ctx)
 }
@@ -1,9 +1,11 @@
 package tui
 
 import (
+	"context"
 	"fmt"
 	"strings"
 
+	"faliactl/pkg/clients"
 	"faliactl/pkg/config"
 	"faliactl/pkg/scraper"
 	"faliactl/pkg/transit"
@@ -14,7 +16,7 @@ import (
 )
 
 // RunConfigTUI launches the interactive experience for managing configurations
-func RunConfigTUI() error {
+func RunConfigTUI(ctx context.Context) error {
 	for {
 		cfg, err := config.Load()
 		if err != nil {
@@ -51,13 +53,13 @@ func RunConfigTUI() error {
 		if action == "theme" {
 			err = runSetThemeTUI(cfg)
 		} else if action == "home" {
-			err = runSetHomeTUI(cfg)
+			err = runSetHomeTUI(ctx, cfg)
 		} else if action == "mensa" {
 			err = runSetMensaCampusTUI(cfg)
 		} else if action == "groups" {
-			err = runSetSavedGroupsTUI(cfg)
+			err = runSetSavedGroupsTUI(ctx, cfg)
 		} else if action == "courses" {
-			err = runSetSavedCoursesTUI(cfg)
+			err = runSetSavedCoursesTUI(ctx, cfg)
 		} else if action == "view" {
 			fmt.Println(accentStyle.Render("\n--- Current Configuration (~/.faliactl.json) ---"))
 			if cfg.HomeAddress == "" {
@@ -109,15 +111,17 @@ func runSetMensaCampusTUI(cfg *config.AppConfig) error {
 	return nil
 }
 
-func runSetSavedGroupsTUI(cfg *config.AppConfig) error {
-	client := scraper.NewClient()
+func runSetSavedGroupsTUI(ctx context.Context, cfg *config.AppConfig) error {
+	client := clients.Scraper()
 	var groups []scraper.Group
 	var err error
 
-	_ = spinner.New().
+	err = spinner.New().
 		Title("Fetching available study groups from Ostfalia...").
-		Action(func() {
-			groups, err = client.FetchGroups()
+		Context(ctx).
+		ActionWithErr(func(ctx context.Context) (err error) {
+			groups, err = client.FetchGroupsContext(ctx)
+			return err
 		}).
 		Run()
 
@@ -168,27 +172,28 @@ func runSetSavedGroupsTUI(cfg *config.AppConfig) error {
 	return nil
 }
 
-func runSetSavedCoursesTUI(cfg *config.AppConfig) error {
+func runSetSavedCoursesTUI(ctx context.Context, cfg *config.AppConfig) error {
 	if len(cfg.SavedGroupURLs) == 0 {
 		fmt.Println(errorStyle.Render("You must save at least one Study Group before you can configure Saved Courses!"))
 		return nil
 	}
 
-	client := scraper.NewClient()
+	client := clients.Scraper()
 	var allCourses []scraper.Course
 	var fetchErr error
 
-	_ = spinner.New().
+	fetchErr = spinner.New().
 		Title("Fetching schedules for your saved groups...").
-		Action(func() {
+		Context(ctx).
+		ActionWithErr(func(ctx context.Context) error {
 			for _, url := range cfg.SavedGroupURLs {
-				groupCourses, err := client.FetchSchedule(url)
+				groupCourses, err := client.FetchScheduleContext(ctx, url)
 				if err != nil {
-					fetchErr = fmt.Errorf("failed to fetch schedule for a group: %w", err)
-					return
+					return fmt.Errorf("failed to fetch schedule for a group: %w", err)
 				}
 				allCourses = append(allCourses, groupCourses...)
 			}
+			return nil
 		}).
 		Run()
 
@@ -248,7 +253,7 @@ func runSetSavedCoursesTUI(cfg *config.AppConfig) error {
 	return nil
 }
 
-func runSetHomeTUI(cfg *config.AppConfig) error {
+func runSetHomeTUI(ctx context.Context, cfg *config.AppConfig) error {
 	var input string
 
 	inputForm := huh.NewForm(
@@ -270,14 +275,16 @@ func runSetHomeTUI(cfg *config.AppConfig) error {
 		return nil
 	}
 
-	client := transit.NewClient()
+	client := clients.Transit()
 	var locations []transit.Location
 	var fetchErr error
 
-	_ = spinner.New().
+	fetchErr = spinner.New().
 		Title(fmt.Sprintf("Searching transit network for '%s'...", input)).
-		Action(func() {
-			locations, fetchErr = client.FetchLocations(input)
+		Context(ctx).
+		ActionWithErr(func(ctx context.Context) (err error) {
+			locations, err = client.FetchLocationsContext(ctx, input)
+			return err
 		}).
 		Run()
 
@@ -1,10 +1,12 @@
 package tui
 
 import (
+	"context"
 	"fmt"
-	"strings"
 	"time"
 
+	"faliactl/pkg/campus"
+	"faliactl/pkg/clients"
 	"faliactl/pkg/config"
 	"faliactl/pkg/scraper"
 	"faliactl/pkg/transit"
@@ -15,7 +17,7 @@ import (
 )
 
 // RunCourseCommuteTUI launches the interactive experience for routing a specific university course
-func RunCourseCommuteTUI() error {
+func RunCourseCommuteTUI(ctx context.Context) error {
 	cfg, err := config.Load()
 	if err != nil || cfg.HomeStationID == "" {
 		fmt.Println(errorStyle.Render("Home address is not configured."))
@@ -25,14 +27,16 @@ func RunCourseCommuteTUI() error {
 
 	fmt.Println(accentStyle.Render("Plan Route to Class"))
 
-	client := scraper.NewClient()
+	client := clients.Scraper()
 	var selectedGroupURLs []string
 
 	var groups []scraper.Group
-	_ = spinner.New().
+	err = spinner.New().
 		Title("Fetching available groups...").
-		Action(func() {
-			groups, err = client.FetchGroups()
+		Context(ctx).
+		ActionWithErr(func(ctx context.Context) (err error) {
+			groups, err = client.FetchGroupsContext(ctx)
+			return err
 		}).
 		Run()
 
@@ -80,17 +84,18 @@ func RunCourseCommuteTUI() error {
 	var courses []scraper.Course
 	var fetchErr error
 
-	_ = spinner.New().
+	fetchErr = spinner.New().
 		Title("Fetching schedule...").
-		Action(func() {
+		Context(ctx).
+		ActionWithErr(func(ctx context.Context) error {
 			for _, url := range selectedGroupURLs {
-				groupCourses, cErr := client.FetchSchedule(url)
+				groupCourses, cErr := client.FetchScheduleContext(ctx, url)
 				if cErr != nil {
-					fetchErr = fmt.Errorf("failed to fetch schedule: %w", cErr)
-					return
+					return fmt.Errorf("failed to fetch schedule: %w", cErr)
 				}
 				courses = append(courses, groupCourses...)
 			}
+			return nil
 		}).
 		Run()
 
@@ -107,28 +112,21 @@ func RunCourseCommuteTUI() error {
 	var courseOptions []huh.Option[string]
 
 	// Only show upcoming courses (roughly, since the scraper pulls the current week/semester)
-	loc, _ := time.LoadLocation("Europe/Berlin")
-	now := time.Now().In(loc)
+	now := time.Now()
 
 	existingSavedMap := make(map[string]bool)
 	for _, name := range cfg.SavedCourses {
 		existingSavedMap[name] = true
 	}
 
-	// Since DateStr is "04.03.2026 (Mittwoch)" we need to parse it
 	for i, c := range courses {
 		// Filter out courses that aren't in the saved list (if the user has a saved list)
 		if len(existingSavedMap) > 0 && !existingSavedMap[c.Name] {
 			continue
 		}
 
-		// "04.03.2026", "08:15"
-		dateOnly := strings.Split(c.DateStr, " ")[0]
-		timeStr := fmt.Sprintf("%s %s", dateOnly, c.StartTime)
-
-		t, parseErr := time.ParseInLocation("02.01.2006 15:04", timeStr, loc)
-		if parseErr == nil && t.After(now) {
-			displayStr := fmt.Sprintf("%s - %s @ %s (%s)", dateOnly, c.Name, c.StartTime, c.Room)
+		if c.Start.After(now) {
+			displayStr := fmt.Sprintf("%s - %s @ %s (%s)", c.Start.Format("02.01.2006"), c.Name, c.StartTime, c.Room)
 			// Store the index as the value so we can retrieve the exact struct
 			courseOptions = append(courseOptions, huh.NewOption(displayStr, fmt.Sprintf("%d", i)))
 		}
@@ -165,50 +163,34 @@ func RunCourseCommuteTUI() error {
 		}
 	}
 
-	return calculateRouteToClass(selectedCourse, cfg)
+	return calculateRouteToClass(ctx, selectedCourse, cfg)
 }
 
-func calculateRouteToClass(course scraper.Course, cfg *config.AppConfig) error {
-	loc, _ := time.LoadLocation("Europe/Berlin")
-	dateOnly := strings.Split(course.DateStr, " ")[0]
-	timeStr := fmt.Sprintf("%s %s", dateOnly, course.StartTime)
-	arrivalTime, err := time.ParseInLocation("02.01.2006 15:04", timeStr, loc)
+func calculateRouteToClass(ctx context.Context, course scraper.Course, cfg *config.AppConfig) error {
+	arrivalTime, _, err := course.Times()
 	if err != nil {
 		return fmt.Errorf("could not parse class start time: %w", err)
 	}
 
 	// Determine destination campus based on Room prefix or context
-	// WF = Wolfenbuettel, SZ = Salzgitter, SUD = Suderburg
-	var destStationID string
-	var destName string
-	roomUpper := strings.ToUpper(course.Room)
-
-	if strings.HasPrefix(roomUpper, "SZ") {
-		destStationID = "991604089" // Salzgitter
-		destName = "Ostfalia Salzgitter"
-	} else if strings.HasPrefix(roomUpper, "SUD") {
-		destStationID = "991604106" // Suderburg
-		destName = "Ostfalia Suderburg"
-	} else if strings.Contains(roomUpper, "EX") {
-		destStationID = "891011" // Exer Süd
-		destName = "Ostfalia Am Exer"
-	} else {
-		// Default to Wolfenbüttel Hauptcampus (Salzdahlumer Straße)
-		destStationID = "891097"
-		destName = "Ostfalia Hauptcampus (Salzdahlumer Str.)"
-	}
-
-	transitClient := transit.NewClient()
+	dest := campus.Default().ForRoom(course.Room)
+
+	transitClient := clients.Transit()
 	var journeys []transit.Journey
 	var fetchErr error
 
-	_ = spinner.New().
-		Title(fmt.Sprintf("Calculating route from %s to %s for %s...", cfg.HomeAddress, destName, course.StartTime)).
-		Action(func() {
-			journeys, fetchErr = transitClient.FetchJourneysByArrival(cfg.HomeStationID, destStationID, arrivalTime)
+	fetchErr = spinner.New().
+		Title(fmt.Sprintf("Calculating route from %s to %s for %s...", cfg.HomeAddress, dest.StationName, course.StartTime)).
+		Context(ctx).
+		ActionWithErr(func(ctx context.Context) (err error) {
+			journeys, err = transitClient.FetchJourneysByArrivalContext(ctx, cfg.HomeStationID, dest.StationID, arrivalTime)
+			return err
 		}).
 		Run()
 
+	if transitUnavailable(fetchErr) {
+		return nil
+	}
 	if fetchErr != nil {
 		return fmt.Errorf("could not calculate journey: %w", fetchErr)
 	}
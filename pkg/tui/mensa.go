@@ -1,10 +1,13 @@
 package tui
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"strings"
 	"time"
 
+	"faliactl/pkg/clients"
 	"faliactl/pkg/config"
 	"faliactl/pkg/mensa"
 
@@ -16,11 +19,11 @@ import (
 )
 
 // RunMensaTUI runs the interactive flow for selecting a Mensa and displaying the menu
-func RunMensaTUI() error {
+func RunMensaTUI(ctx context.Context) error {
 	var selectedLocationID int
 	var selectedDate string
 
-	client := mensa.NewClient()
+	client := clients.Mensa()
 	var locations []mensa.Location
 	var err error
 
@@ -52,10 +55,12 @@ func RunMensaTUI() error {
 		}
 	}
 
-	_ = spinner.New().
+	err = spinner.New().
 		Title("Fetching available Mensa locations...").
-		Action(func() {
-			locations, err = client.FetchLocations()
+		Context(ctx).
+		ActionWithErr(func(ctx context.Context) (err error) {
+			locations, err = client.FetchLocationsContext(ctx)
+			return err
 		}).
 		Run()
 
@@ -134,13 +139,19 @@ func RunMensaTUI() error {
 
 	var menu *mensa.MenuResponse
 
-	_ = spinner.New().
+	err = spinner.New().
 		Title(fmt.Sprintf("Fetching menu for %s...", selectedDate)).
-		Action(func() {
-			menu, err = client.FetchMenu(selectedLocationID, selectedDate)
+		Context(ctx).
+		ActionWithErr(func(ctx context.Context) (err error) {
+			menu, err = client.FetchMenuContext(ctx, selectedLocationID, selectedDate)
+			return err
 		}).
 		Run()
 
+	if errors.Is(err, mensa.ErrNoMenu) {
+		fmt.Println(errorStyle.Render(fmt.Sprintf("🍽️ No menu published for %s, the Mensa is probably closed.", selectedDate)))
+		return nil
+	}
 	if err != nil {
 		return fmt.Errorf("failed to fetch mensa menu: %w", err)
 	}
@@ -170,10 +181,8 @@ func RunMensaTUI() error {
 
 	for _, meal := range menu.Meals {
 		vegan := ""
-		for _, cat := range meal.Tags.Categories {
-			if cat.ID == "VEGA" {
-				vegan = lipgloss.NewStyle().Foreground(lipgloss.Color("46")).Render(" [Vegan]")
-			}
+		if meal.Vegan() {
+			vegan = lipgloss.NewStyle().Foreground(lipgloss.Color("46")).Render(" [Vegan]")
 		}
 
 		extras := []string{}
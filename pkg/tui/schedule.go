@@ -1,12 +1,17 @@
 package tui
 
 import (
+	"context"
 	"fmt"
 	"os"
+	"path/filepath"
 	"strings"
 
+	"faliactl/pkg/clients"
 	"faliactl/pkg/config"
+	"faliactl/pkg/conflict"
 	"faliactl/pkg/exporter"
+	"faliactl/pkg/filter"
 	"faliactl/pkg/scraper"
 
 	"github.com/charmbracelet/huh"
@@ -14,21 +19,23 @@ import (
 )
 
 // RunScheduleTUI runs the interactive flow for selecting study groups and exporting a timetable
-func RunScheduleTUI() error {
+func RunScheduleTUI(ctx context.Context) error {
 	fmt.Println(accentStyle.Render("Welcome to the Faliactl Exporter!"))
 
 	cfg, _ := config.Load()
 	var selectedGroupURLs []string
 
-	client := scraper.NewClient()
+	client := clients.Scraper()
 
 	var groups []scraper.Group
 	var err error
 
-	_ = spinner.New().
+	err = spinner.New().
 		Title("Fetching available study groups from Ostfalia...").
-		Action(func() {
-			groups, err = client.FetchGroups()
+		Context(ctx).
+		ActionWithErr(func(ctx context.Context) (err error) {
+			groups, err = client.FetchGroupsContext(ctx)
+			return err
 		}).
 		Run()
 
@@ -79,15 +86,14 @@ func RunScheduleTUI() error {
 	seenEvent := make(map[string]bool)
 	var fetchErr error
 
-	_ = spinner.New().
+	fetchErr = spinner.New().
 		Title("Fetching schedules...").
-		Action(func() {
+		Context(ctx).
+		ActionWithErr(func(ctx context.Context) error {
 			for _, url := range selectedGroupURLs {
-				var groupCourses []scraper.Course
-				groupCourses, fetchErr = client.FetchSchedule(url)
-				if fetchErr != nil {
-					fetchErr = fmt.Errorf("failed to fetch schedule for %s: %w", url, fetchErr)
-					return
+				groupCourses, err := client.FetchScheduleContext(ctx, url)
+				if err != nil {
+					return fmt.Errorf("failed to fetch schedule for %s: %w", url, err)
 				}
 				for _, c := range groupCourses {
 					key := fmt.Sprintf("%s|%s|%s|%s", c.Name, c.DateStr, c.StartTime, c.EndTime)
@@ -97,6 +103,7 @@ func RunScheduleTUI() error {
 					}
 				}
 			}
+			return nil
 		}).
 		Run()
 
@@ -109,6 +116,27 @@ func RunScheduleTUI() error {
 		return nil
 	}
 
+	// The saved filter hides courses from the picker; renames only change the labels
+	var savedFilter *filter.Filter
+	if cfg != nil {
+		savedFilter = cfg.Filter
+	}
+	courseFilter, err := savedFilter.Compile()
+	if err != nil {
+		return fmt.Errorf("invalid filter in config: %w", err)
+	}
+	var kept []scraper.Course
+	for _, c := range courses {
+		if courseFilter.Keep(c) {
+			kept = append(kept, c)
+		}
+	}
+	if len(kept) == 0 {
+		fmt.Println(errorStyle.Render("Your saved filter removed every course of the selected groups!"))
+		return nil
+	}
+	courses = kept
+
 	courseNamesMap := make(map[string]bool)
 	var courseOptions []huh.Option[string]
 
@@ -124,7 +152,7 @@ func RunScheduleTUI() error {
 
 		if !courseNamesMap[name] {
 			courseNamesMap[name] = true
-			opt := huh.NewOption(name, name)
+			opt := huh.NewOption(courseFilter.DisplayName(name), name)
 
 			// If user has saved courses, strictly select only those by default.
 			// Otherwise, pre-select all available courses.
@@ -186,17 +214,54 @@ func RunScheduleTUI() error {
 	for _, c := range courses {
 		// Match against the base name
 		if selectedMap[c.Name] {
+			c.Name = courseFilter.DisplayName(c.Name)
 			filteredCourses = append(filteredCourses, c)
 		}
 	}
 
+	// Combined groups can silently produce overlapping lectures; let the user decide
+	if conflicts := conflict.Analyze(filteredCourses, conflict.Options{}); len(conflicts) > 0 {
+		fmt.Println(warnStyle.Render(fmt.Sprintf("\n%d conflict(s) in your selection:", len(conflicts))))
+		for _, c := range conflicts {
+			fmt.Println(warnStyle.Render("  • " + c.String()))
+		}
+		fmt.Println()
+
+		exportAnyway := true
+		err = huh.NewForm(
+			huh.NewGroup(
+				huh.NewConfirm().
+					Title("Export anyway?").
+					Value(&exportAnyway).
+					Affirmative("Export").
+					Negative("Cancel"),
+			),
+		).WithTheme(GetTheme()).Run()
+		if err != nil {
+			return err
+		}
+		if !exportAnyway {
+			return nil
+		}
+	}
+
 	file, err := os.Create(outputFile)
 	if err != nil {
 		return fmt.Errorf("failed to create output file: %w", err)
 	}
 	defer file.Close()
 
-	err = exporter.GenerateICS(filteredCourses, file)
+	stateName := outputFile
+	if abs, absErr := filepath.Abs(outputFile); absErr == nil {
+		stateName = abs
+	}
+
+	var opts exporter.Options
+	if cfg != nil {
+		opts.CancelGrace = cfg.CancelGrace()
+	}
+
+	err = exporter.GenerateTrackedICS(stateName, filteredCourses, file, opts)
 	if err != nil {
 		return fmt.Errorf("failed to generate ICS: %w", err)
 	}
@@ -1,8 +1,12 @@
 package tui
 
 import (
+	"context"
+	"errors"
 	"fmt"
 
+	"faliactl/pkg/campus"
+	"faliactl/pkg/clients"
 	"faliactl/pkg/config"
 	"faliactl/pkg/transit"
 
@@ -11,17 +15,29 @@ import (
 	"github.com/charmbracelet/lipgloss"
 )
 
-var (
-	transitCampuses = []huh.Option[string]{
-		huh.NewOption("Wolfenbüttel (Hauptcampus)", "891097"),
-		huh.NewOption("Wolfenbüttel (Am Exer)", "891011"),
-		huh.NewOption("Salzgitter (Ostfalia Campus)", "991604089"),
-		huh.NewOption("Suderburg (Ostfalia Campus)", "991604106"),
+// transitCampuses offers every campus with a known stop
+func transitCampuses() []huh.Option[string] {
+	var options []huh.Option[string]
+	for _, c := range campus.Default().Campuses() {
+		if c.StationID != "" {
+			options = append(options, huh.NewOption(c.Name, c.StationID))
+		}
 	}
-)
+	return options
+}
+
+// transitUnavailable prints a hint instead of failing when HAFAS is down, which happens
+// often enough to not be worth an error
+func transitUnavailable(err error) bool {
+	if !errors.Is(err, transit.ErrUpstreamUnavailable) {
+		return false
+	}
+	fmt.Println(errorStyle.Render("🚧 The transit API is unavailable right now, please try again in a few minutes."))
+	return true
+}
 
 // RunTransitTUI launches the interactive experience for public transit
-func RunTransitTUI() error {
+func RunTransitTUI(ctx context.Context) error {
 	var stationID string
 	var action string
 
@@ -29,7 +45,7 @@ func RunTransitTUI() error {
 		huh.NewGroup(
 			huh.NewSelect[string]().
 				Title("Which campus are you at?").
-				Options(transitCampuses...).
+				Options(transitCampuses()...).
 				Value(&stationID),
 
 			huh.NewSelect[string]().
@@ -46,26 +62,31 @@ func RunTransitTUI() error {
 		return err
 	}
 
-	client := transit.NewClient()
+	client := clients.Transit()
 
 	if action == "departures" {
-		return runDeparturesView(client, stationID)
+		return runDeparturesView(ctx, client, stationID)
 	}
 
-	return runRouteHomeView(client, stationID)
+	return runRouteHomeView(ctx, client, stationID)
 }
 
-func runDeparturesView(client *transit.Client, stationID string) error {
+func runDeparturesView(ctx context.Context, client *transit.Client, stationID string) error {
 	var deps []transit.Departure
 	var err error
 
-	_ = spinner.New().
+	err = spinner.New().
 		Title("Fetching live departures...").
-		Action(func() {
-			deps, err = client.FetchDepartures(stationID, 60)
+		Context(ctx).
+		ActionWithErr(func(ctx context.Context) (err error) {
+			deps, err = client.FetchDeparturesContext(ctx, stationID, 60)
+			return err
 		}).
 		Run()
 
+	if transitUnavailable(err) {
+		return nil
+	}
 	if err != nil {
 		return fmt.Errorf("could not fetch departures: %w", err)
 	}
@@ -99,7 +120,7 @@ func runDeparturesView(client *transit.Client, stationID string) error {
 	return nil
 }
 
-func runRouteHomeView(client *transit.Client, stationID string) error {
+func runRouteHomeView(ctx context.Context, client *transit.Client, stationID string) error {
 	cfg, err := config.Load()
 	if err != nil || cfg.HomeStationID == "" {
 		fmt.Println(errorStyle.Render("Home address is not configured."))
@@ -110,13 +131,18 @@ func runRouteHomeView(client *transit.Client, stationID string) error {
 	var journeys []transit.Journey
 	var fetchErr error
 
-	_ = spinner.New().
+	fetchErr = spinner.New().
 		Title(fmt.Sprintf("Routing trip from campus to %s...", cfg.HomeAddress)).
-		Action(func() {
-			journeys, fetchErr = client.FetchJourneys(stationID, cfg.HomeStationID)
+		Context(ctx).
+		ActionWithErr(func(ctx context.Context) (err error) {
+			journeys, err = client.FetchJourneysContext(ctx, stationID, cfg.HomeStationID)
+			return err
 		}).
 		Run()
 
+	if transitUnavailable(fetchErr) {
+		return nil
+	}
 	if fetchErr != nil {
 		return fmt.Errorf("could not route journey: %w", fetchErr)
 	}
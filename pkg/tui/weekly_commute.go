@@ -1,14 +1,16 @@
 package tui
 
 import (
+	"context"
 	"fmt"
 	"net/url"
 	"os"
-	"sort"
 	"strconv"
-	"strings"
 	"time"
 
+	"faliactl/pkg/campus"
+	"faliactl/pkg/clients"
+	"faliactl/pkg/commute"
 	"faliactl/pkg/config"
 	"faliactl/pkg/scraper"
 	"faliactl/pkg/transit"
@@ -28,7 +30,7 @@ type ResolvedCommute struct {
 }
 
 // RunWeeklyCommuteTUI generates a transit itinerary based on Saved Courses
-func RunWeeklyCommuteTUI() error {
+func RunWeeklyCommuteTUI(ctx context.Context) error {
 	cfg, err := config.Load()
 	if err != nil || cfg.HomeStationID == "" {
 		fmt.Println(errorStyle.Render("Home address is not configured."))
@@ -74,22 +76,23 @@ func RunWeeklyCommuteTUI() error {
 
 	fmt.Println(accentStyle.Render(fmt.Sprintf("\nGenerating %d-Day Commute Planner...", days)))
 
-	client := scraper.NewClient()
+	client := clients.Scraper()
 	var allCourses []scraper.Course
 	var fetchErr error
 
 	// 1. Fetch all schedules from the saved groups (using local cache implicitly)
-	_ = spinner.New().
+	fetchErr = spinner.New().
 		Title("Checking schedules...").
-		Action(func() {
+		Context(ctx).
+		ActionWithErr(func(ctx context.Context) error {
 			for _, url := range cfg.SavedGroupURLs {
-				groupCourses, cErr := client.FetchSchedule(url)
+				groupCourses, cErr := client.FetchScheduleContext(ctx, url)
 				if cErr != nil {
-					fetchErr = fmt.Errorf("failed to fetch schedule: %w", cErr)
-					return
+					return fmt.Errorf("failed to fetch schedule: %w", cErr)
 				}
 				allCourses = append(allCourses, groupCourses...)
 			}
+			return nil
 		}).
 		Run()
 
@@ -98,99 +101,43 @@ func RunWeeklyCommuteTUI() error {
 	}
 
 	// 2. Filter down to only Saved Courses occurring in the given timeframe
-	loc, _ := time.LoadLocation("Europe/Berlin")
-	now := time.Now().In(loc)
+	now := time.Now().In(scraper.Berlin)
 	// Start of today (midnight) so we include courses later today
-	todayStart := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+	todayStart := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, scraper.Berlin)
 	timeHorizon := todayStart.AddDate(0, 0, days)
 
-	existingSavedMap := make(map[string]bool)
-	for _, name := range cfg.SavedCourses {
-		existingSavedMap[name] = true
-	}
-
-	var upcomingCourses []scraper.Course
-
-	for _, c := range allCourses {
-		if !existingSavedMap[c.Name] {
-			continue
-		}
-
-		dateOnly := strings.Split(c.DateStr, " ")[0]
-		timeStr := fmt.Sprintf("%s %s", dateOnly, c.StartTime)
-
-		t, parseErr := time.ParseInLocation("02.01.2006 15:04", timeStr, loc)
-		// Must be in the future (or later today) AND before the horizon
-		if parseErr == nil && t.After(now) && t.Before(timeHorizon) {
-			// We only want to commute ONCE per day, to the FIRST class of that day.
-			// Let's store them all for now and sort them.
-			upcomingCourses = append(upcomingCourses, c)
-		}
-	}
+	// We only want to commute ONCE per day, to the FIRST class of that day.
+	firstClasses := commute.FirstClasses(allCourses, cfg.SavedCourses, now, timeHorizon)
 
-	if len(upcomingCourses) == 0 {
+	if len(firstClasses) == 0 {
 		fmt.Println(lipgloss.NewStyle().Foreground(lipgloss.Color("240")).Render(fmt.Sprintf("\nNo saved classes scheduled for the next %d days! Enjoy your free time. 🏖️", days)))
 		return nil
 	}
 
-	// Sort chronologically
-	sort.SliceStable(upcomingCourses, func(i, j int) bool {
-		di := strings.Split(upcomingCourses[i].DateStr, " ")[0]
-		ti := fmt.Sprintf("%s %s", di, upcomingCourses[i].StartTime)
-		t1, _ := time.ParseInLocation("02.01.2006 15:04", ti, loc)
-
-		dj := strings.Split(upcomingCourses[j].DateStr, " ")[0]
-		tj := fmt.Sprintf("%s %s", dj, upcomingCourses[j].StartTime)
-		t2, _ := time.ParseInLocation("02.01.2006 15:04", tj, loc)
-
-		return t1.Before(t2)
-	})
-
-	// Group by Date to only route to the FIRST class each day
-	type DailyFirstClass struct {
-		Date        string
-		Course      scraper.Course
-		ArrivalTime time.Time
-	}
-
-	var commuteList []DailyFirstClass
-	seenDates := make(map[string]bool)
-
-	for _, c := range upcomingCourses {
-		dateOnly := strings.Split(c.DateStr, " ")[0]
-		if !seenDates[dateOnly] {
-			seenDates[dateOnly] = true
-
-			timeStr := fmt.Sprintf("%s %s", dateOnly, c.StartTime)
-			arrTime, _ := time.ParseInLocation("02.01.2006 15:04", timeStr, loc)
-
-			commuteList = append(commuteList, DailyFirstClass{
-				Date:        dateOnly,
-				Course:      c,
-				ArrivalTime: arrTime,
-			})
-		}
-	}
-
 	// 3. Calculate all the routes
 	var results []ResolvedCommute
-	transitClient := transit.NewClient()
+	transitClient := clients.Transit()
 
-	_ = spinner.New().
+	err = spinner.New().
 		Title("Calculating HAFAS transit routes for the week...").
-		Action(func() {
-			for _, daily := range commuteList {
-				j, jErr := getBestJourney(transitClient, cfg, daily.Course, daily.ArrivalTime)
+		Context(ctx).
+		ActionWithErr(func(ctx context.Context) error {
+			for _, c := range firstClasses {
+				j, jErr := commute.BestJourney(ctx, transitClient, cfg.HomeStationID, c)
 
 				results = append(results, ResolvedCommute{
-					Date:    daily.Date,
-					Course:  daily.Course,
+					Date:    c.Start.Format("02.01.2006"),
+					Course:  c,
 					Journey: j,
 					Error:   jErr,
 				})
 			}
+			return nil
 		}).
 		Run()
+	if err != nil {
+		return err
+	}
 
 	fmt.Println()
 
@@ -274,7 +221,7 @@ func exportCommutesToICS(results []ResolvedCommute, homeAddress string) error {
 
 		event.SetSummary(fmt.Sprintf("🚌 Commute to %s", res.Course.Name))
 
-		fullAddress := scraper.GetCampusAddress(res.Course.Room)
+		fullAddress := campus.Default().ForRoom(res.Course.Room).Address
 		event.SetLocation(fmt.Sprintf("%s, %s", res.Course.Room, fullAddress))
 
 		// Build a description with all transfers + Map Link
@@ -309,34 +256,3 @@ func exportCommutesToICS(results []ResolvedCommute, homeAddress string) error {
 	fmt.Printf("\n✨ Successfully exported commute calendar to: %s\n", filename)
 	return nil
 }
-
-// Helper to resolve routes
-func getBestJourney(client *transit.Client, cfg *config.AppConfig, course scraper.Course, arrivalTime time.Time) (*transit.Journey, error) {
-	roomUpper := strings.ToUpper(course.Room)
-	var destStationID string
-
-	if strings.HasPrefix(roomUpper, "SZ") {
-		destStationID = "991604089" // Salzgitter
-	} else if strings.HasPrefix(roomUpper, "SUD") {
-		destStationID = "991604106" // Suderburg
-	} else if strings.Contains(roomUpper, "EX") {
-		destStationID = "891011" // Exer Süd
-	} else {
-		destStationID = "891097" // Hauptcampus (Salzdahlumer Str.)
-	}
-
-	journeys, err := client.FetchJourneysByArrival(cfg.HomeStationID, destStationID, arrivalTime)
-	if err != nil {
-		return nil, err
-	}
-
-	if len(journeys) == 0 {
-		return nil, fmt.Errorf("no routes found")
-	}
-
-	best := journeys[len(journeys)-1]
-	if best.Legs[len(best.Legs)-1].Arrival.After(arrivalTime) {
-		best = journeys[0]
-	}
-	return &best, nil
-}
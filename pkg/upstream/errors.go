@@ -0,0 +1,71 @@
+// Package upstream defines the errors shared by the clients of the intranet, the Mensa API
+// and HAFAS, so callers can tell "nothing there" from "service down" from "format changed"
+// with errors.Is and errors.As instead of matching messages
+package upstream
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+var (
+	// ErrNotFound means the upstream has nothing for the request, e.g. an unknown group or
+	// a Mensa that is closed on that day
+	ErrNotFound = errors.New("not found")
+	// ErrUpstreamUnavailable means the upstream could not be reached, timed out or answered
+	// with a server error; trying again later may help
+	ErrUpstreamUnavailable = errors.New("upstream unavailable")
+)
+
+// HTTPStatusError is an unexpected HTTP status code. It matches ErrNotFound for 404 and 410,
+// and ErrUpstreamUnavailable for 429 and every 5xx.
+type HTTPStatusError struct {
+	Code int
+	URL  string
+}
+
+func (e *HTTPStatusError) Error() string {
+	return fmt.Sprintf("unexpected status code %d when fetching %s", e.Code, e.URL)
+}
+
+func (e *HTTPStatusError) Is(target error) bool {
+	switch target {
+	case ErrNotFound:
+		return e.Code == http.StatusNotFound || e.Code == http.StatusGone
+	case ErrUpstreamUnavailable:
+		return e.Code == http.StatusTooManyRequests || e.Code >= 500
+	}
+	return false
+}
+
+// RequestError is a request that got no response at all: DNS, refused connections, timeouts.
+// It matches ErrUpstreamUnavailable unless the caller cancelled the request.
+type RequestError struct {
+	URL string
+	Err error
+}
+
+func (e *RequestError) Error() string {
+	return fmt.Sprintf("failed to fetch %s: %v", e.URL, e.Err)
+}
+
+func (e *RequestError) Unwrap() error { return e.Err }
+
+func (e *RequestError) Is(target error) bool {
+	return target == ErrUpstreamUnavailable && !errors.Is(e.Err, context.Canceled)
+}
+
+// ParseError is a response that doesn't have the expected format, which usually means the
+// upstream changed its layout or schema
+type ParseError struct {
+	URL string
+	Err error
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("unexpected response format from %s: %v", e.URL, e.Err)
+}
+
+func (e *ParseError) Unwrap() error { return e.Err }
@@ -0,0 +1,61 @@
+package upstream
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestHTTPStatusError_Is(t *testing.T) {
+	tests := []struct {
+		code        int
+		notFound    bool
+		unavailable bool
+	}{
+		{404, true, false},
+		{410, true, false},
+		{429, false, true},
+		{500, false, true},
+		{503, false, true},
+		{400, false, false},
+		{403, false, false},
+	}
+	for _, tt := range tests {
+		// Wrapped like the clients do, to make sure the chain is followed
+		err := fmt.Errorf("failed to fetch menu: %w", &HTTPStatusError{Code: tt.code, URL: "http://x"})
+		if got := errors.Is(err, ErrNotFound); got != tt.notFound {
+			t.Errorf("%d: errors.Is(ErrNotFound) = %v, want %v", tt.code, got, tt.notFound)
+		}
+		if got := errors.Is(err, ErrUpstreamUnavailable); got != tt.unavailable {
+			t.Errorf("%d: errors.Is(ErrUpstreamUnavailable) = %v, want %v", tt.code, got, tt.unavailable)
+		}
+		var status *HTTPStatusError
+		if !errors.As(err, &status) || status.Code != tt.code {
+			t.Errorf("%d: errors.As did not find the status", tt.code)
+		}
+	}
+}
+
+func TestRequestError_Is(t *testing.T) {
+	timeout := &RequestError{URL: "http://x", Err: context.DeadlineExceeded}
+	if !errors.Is(timeout, ErrUpstreamUnavailable) || !errors.Is(timeout, context.DeadlineExceeded) {
+		t.Errorf("a timeout should be unavailable and keep its cause: %v", timeout)
+	}
+	cancelled := &RequestError{URL: "http://x", Err: context.Canceled}
+	if errors.Is(cancelled, ErrUpstreamUnavailable) || !errors.Is(cancelled, context.Canceled) {
+		t.Errorf("a cancelled request is not an upstream failure: %v", cancelled)
+	}
+}
+
+func TestParseError(t *testing.T) {
+	cause := errors.New("invalid character")
+	var err error = &ParseError{URL: "http://x", Err: cause}
+	var parseErr *ParseError
+	if !errors.As(err, &parseErr) || !errors.Is(err, cause) {
+		t.Errorf("ParseError should be found with As and unwrap to its cause")
+	}
+	if errors.Is(err, ErrNotFound) || errors.Is(err, ErrUpstreamUnavailable) {
+		t.Errorf("a parse error is neither not found nor unavailable")
+	}
+}
@@ -0,0 +1,184 @@
+package watch
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"faliactl/pkg/notify"
+	"faliactl/pkg/scraper"
+)
+
+// DefaultInterval is how often the watched groups are re-scraped
+const DefaultInterval = 30 * time.Minute
+
+// State is the last schedule every sink was told about, per group. It is kept apart from the
+// schedule cache because other commands (export, diff, ...) refresh the cache too, which would
+// otherwise swallow changes the watcher never reported.
+type State struct {
+	Groups map[string]GroupState `json:"groups"`
+}
+
+// GroupState is the notified baseline of a single group
+type GroupState struct {
+	Checked  time.Time        `json:"checked"`
+	Notified time.Time        `json:"notified,omitempty"`
+	Courses  []scraper.Course `json:"courses"`
+}
+
+// Watcher periodically re-scrapes groups and notifies the sinks about changes
+type Watcher struct {
+	Client    *scraper.Client
+	Groups    []string // Group paths, e.g. "161902.html"
+	Sinks     []notify.Sink
+	Interval  time.Duration
+	StatePath string
+	// Logf receives progress and error messages (defaults to discarding them)
+	Logf func(format string, args ...any)
+	// Now is used for event timestamps (defaults to time.Now)
+	Now func() time.Time
+}
+
+// DefaultStatePath returns the watcher state file inside the faliactl cache directory
+func DefaultStatePath() (string, error) {
+	cacheDir, err := scraper.CacheDir()
+	if err != nil {
+		return "", err
+	}
+	dir := filepath.Join(cacheDir, "watch")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("could not create watch state directory: %w", err)
+	}
+	return filepath.Join(dir, "state.json"), nil
+}
+
+// Run checks all groups immediately and then every Interval until ctx is cancelled.
+// The state is saved after every pass, so a restart continues where it stopped.
+func (w *Watcher) Run(ctx context.Context) error {
+	interval := w.Interval
+	if interval <= 0 {
+		interval = DefaultInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := w.Check(ctx); err != nil {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// Check runs a single pass over all groups and notifies the sinks about every change.
+// Failures of individual groups or sinks are logged, not returned; only a state file
+// that cannot be read or written is fatal.
+func (w *Watcher) Check(ctx context.Context) error {
+	state, err := w.loadState()
+	if err != nil {
+		return err
+	}
+
+	for _, group := range w.Groups {
+		if ctx.Err() != nil {
+			break
+		}
+
+		courses, _, err := w.Client.RevalidateScheduleContext(ctx, group)
+		if err != nil {
+			w.logf("Failed to fetch %s: %v", group, err)
+			continue
+		}
+		if len(courses) == 0 {
+			// An empty page is far more likely a scraping problem than a cancelled semester
+			w.logf("Fetched no courses for %s, keeping the previous baseline", group)
+			continue
+		}
+
+		now := w.now()
+		previous, known := state.Groups[group]
+		current := GroupState{Checked: now, Notified: previous.Notified, Courses: courses}
+
+		if known {
+			changes := scraper.DiffSchedules(previous.Courses, courses)
+			if len(changes) > 0 {
+				event := notify.Event{Kind: notify.KindScheduleChange, Group: group, Time: now, Changes: changes}
+				w.logf("%d change(s) in %s", len(changes), group)
+				errs := notify.NotifyAll(ctx, w.Sinks, event)
+				for _, err := range errs {
+					w.logf("Notification failed: %v", err)
+				}
+				if ctx.Err() != nil || (len(w.Sinks) > 0 && len(errs) == len(w.Sinks)) {
+					// Nobody was told: keep the old baseline so the next check reports the changes again
+					w.logf("No sink delivered the changes in %s, retrying on the next check", group)
+					current.Courses = previous.Courses
+				} else {
+					current.Notified = now
+				}
+			}
+		} else {
+			w.logf("Watching %s (%d courses)", group, len(courses))
+		}
+
+		state.Groups[group] = current
+		// Saved per group so a SIGTERM between groups never causes a repeated notification
+		if err := w.saveState(state); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (w *Watcher) loadState() (*State, error) {
+	state := &State{Groups: make(map[string]GroupState)}
+
+	data, err := os.ReadFile(w.StatePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return state, nil
+		}
+		return nil, fmt.Errorf("failed to read watch state: %w", err)
+	}
+	if err := json.Unmarshal(data, state); err != nil {
+		return nil, fmt.Errorf("failed to parse watch state %s: %w", w.StatePath, err)
+	}
+	if state.Groups == nil {
+		state.Groups = make(map[string]GroupState)
+	}
+	return state, nil
+}
+
+func (w *Watcher) saveState(state *State) error {
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to serialize watch state: %w", err)
+	}
+
+	tmp := w.StatePath + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("failed to write watch state: %w", err)
+	}
+	return os.Rename(tmp, w.StatePath)
+}
+
+func (w *Watcher) logf(format string, args ...any) {
+	if w.Logf != nil {
+		w.Logf(format, args...)
+	}
+}
+
+func (w *Watcher) now() time.Time {
+	if w.Now != nil {
+		return w.Now()
+	}
+	return time.Now()
+}
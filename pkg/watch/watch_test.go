@@ -0,0 +1,170 @@
+package watch
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"faliactl/pkg/notify"
+	"faliactl/pkg/scraper"
+)
+
+type recordingSink struct {
+	mu     sync.Mutex
+	events []notify.Event
+}
+
+func (s *recordingSink) Name() string { return "recording" }
+
+func (s *recordingSink) Notify(ctx context.Context, event notify.Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.events = append(s.events, event)
+	return nil
+}
+
+func TestWatcher_NotifiesOnceAcrossRestarts(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	page, err := os.ReadFile(filepath.Join("..", "scraper", "testdata", "161902.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var mu sync.Mutex
+	current := string(page)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		w.Write([]byte(current))
+	}))
+	defer server.Close()
+
+	statePath := filepath.Join(t.TempDir(), "state.json")
+	sink := &recordingSink{}
+	newWatcher := func() *Watcher {
+		return &Watcher{
+			Client:    scraper.NewClient(scraper.WithBaseURL(server.URL)),
+			Groups:    []string{"161902.html"},
+			Sinks:     []notify.Sink{sink},
+			StatePath: statePath,
+		}
+	}
+
+	// The first pass only records the baseline
+	if err := newWatcher().Check(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if len(sink.events) != 0 {
+		t.Fatalf("expected no notification for the baseline, got %d", len(sink.events))
+	}
+
+	mu.Lock()
+	current = strings.Replace(current, "WF-C-015", "WF-C-016", 1)
+	mu.Unlock()
+
+	if err := newWatcher().Check(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if len(sink.events) != 1 {
+		t.Fatalf("expected one notification after the room change, got %d", len(sink.events))
+	}
+	if c := sink.events[0].Changes; len(c) != 1 || c[0].Kind != scraper.ChangeMoved {
+		t.Errorf("unexpected changes: %v", c)
+	}
+
+	// A restarted watcher must not report the same change again
+	if err := newWatcher().Check(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if len(sink.events) != 1 {
+		t.Errorf("restart re-notified: got %d events", len(sink.events))
+	}
+}
+
+func TestWatcher_RunStopsOnCancel(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "down", http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	w := &Watcher{
+		Client:    scraper.NewClient(scraper.WithBaseURL(server.URL)),
+		Groups:    []string{"161902.html"},
+		StatePath: filepath.Join(t.TempDir(), "state.json"),
+	}
+	if err := w.Run(ctx); err != nil {
+		t.Errorf("expected a clean shutdown, got %v", err)
+	}
+}
+
+type failingSink struct{ calls int }
+
+func (s *failingSink) Name() string { return "failing" }
+
+func (s *failingSink) Notify(ctx context.Context, event notify.Event) error {
+	s.calls++
+	return errors.New("smtp: connection refused")
+}
+
+func TestWatcher_KeepsBaselineWhenEverySinkFails(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	page, err := os.ReadFile(filepath.Join("..", "scraper", "testdata", "161902.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var mu sync.Mutex
+	current := string(page)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		w.Write([]byte(current))
+	}))
+	defer server.Close()
+
+	statePath := filepath.Join(t.TempDir(), "state.json")
+	newWatcher := func(sink notify.Sink) *Watcher {
+		return &Watcher{
+			Client:    scraper.NewClient(scraper.WithBaseURL(server.URL)),
+			Groups:    []string{"161902.html"},
+			Sinks:     []notify.Sink{sink},
+			StatePath: statePath,
+		}
+	}
+
+	if err := newWatcher(&recordingSink{}).Check(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	mu.Lock()
+	current = strings.Replace(current, "WF-C-015", "WF-C-016", 1)
+	mu.Unlock()
+
+	failing := &failingSink{}
+	if err := newWatcher(failing).Check(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if failing.calls != 1 {
+		t.Fatalf("expected one failed delivery, got %d", failing.calls)
+	}
+
+	// The change was never delivered, so the next check must report it again
+	sink := &recordingSink{}
+	if err := newWatcher(sink).Check(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if len(sink.events) != 1 || len(sink.events[0].Changes) != 1 {
+		t.Errorf("expected the undelivered change to be retried, got %v", sink.events)
+	}
+}